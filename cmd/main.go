@@ -2,37 +2,127 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
 	_ "pr-assignment/docs"
 	"pr-assignment/internal/app"
 	"pr-assignment/internal/app/config/db"
 	"pr-assignment/internal/app/config/env"
 	"pr-assignment/internal/app/config/initstructs"
+	"pr-assignment/internal/app/scheduler"
+	"pr-assignment/internal/metrics"
+	"syscall"
+	"time"
 )
 
+// time left to export buffered spans after the server stopped
+const tracingFlushTimeout = 5 * time.Second
+
 func main() {
-	ctx := context.Background()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to an optional YAML config file")
+	printConfig := flag.Bool("print-config", false, "print the resolved config with secrets redacted and exit")
+	flag.Parse()
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
 
-	configDb, err := env.LoadConfigEnv()
+	config, err := env.Load(*configPath)
 	if err != nil {
-		log.Fatalf("unable to load config: %e", err)
+		log.Fatalf("unable to load config: %v", err)
+	}
+
+	if *printConfig {
+		out, err := config.YAML()
+		if err != nil {
+			log.Fatalf("unable to print config: %v", err)
+		}
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+
+	logger := initstructs.InitLogger(config.Log)
+
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			fatal(logger, "unknown command", errors.New(args[0]))
+		}
+		if err := runMigrate(config.Db, logger, args[1:]); err != nil {
+			fatal(logger, "migrate failed", err)
+		}
+		return
+	}
+
+	if config.Db.AutoMigrate {
+		if err := runMigrate(config.Db, logger, []string{"up"}); err != nil {
+			fatal(logger, "unable to apply migrations", err)
+		}
 	}
 
-	database, err := db.InitDatabase(ctx, *configDb)
+	shutdownTracing, err := initstructs.InitTracing(ctx, config.Tracing)
 	if err != nil {
-		log.Fatalf("unable to init database: %e", err)
+		fatal(logger, "unable to init tracing", err)
+	}
+
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("unable to flush traces", "error", err)
+		}
+	}()
+
+	database, err := db.InitDatabase(ctx, config.Db, logger)
+	if err != nil {
+		fatal(logger, "unable to init database", err)
 	}
 
 	defer database.Pool.Close()
 
+	appMetrics := metrics.NewMetrics(logger)
+	appMetrics.RegisterPool(database.Pool)
+
 	repos := initstructs.InitRepositories(database.Pool)
-	services := initstructs.InitServices(repos)
-	handlers := initstructs.InitHandlers(services)
+	notifier := initstructs.InitNotifier(config.Notifier)
+	directorySource := initstructs.InitDirectorySource(config.Directory)
+	services := initstructs.InitServices(repos, config.Assignment, notifier, directorySource, config.Directory,
+		appMetrics, logger)
+	handlers := initstructs.InitHandlers(services, database)
+
+	jobs := scheduler.NewScheduler(logger)
+	if config.Features.SlaChecks {
+		jobs.AddJob("sla", config.Scheduler.SlaCheckInterval, services.SlaService.CheckSla)
+	}
+	if notifier != nil {
+		jobs.AddJob("digest", config.Scheduler.DigestCheckInterval, services.DigestService.SendDigests)
+	}
+	if services.DirectorySyncService != nil {
+		jobs.AddJob("directory_sync", config.Directory.SyncInterval, services.DirectorySyncService.Sync)
+	}
 
-	server := app.NewServer(handlers.PullRequestHandler, handlers.UserHandler, handlers.StatHandler)
+	// jobs get their own context so they keep the database until requests are drained
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs.Start(jobsCtx)
+
+	server := app.NewServer(handlers.PullRequestHandler, handlers.UserHandler, handlers.StatHandler,
+		handlers.ExclusionHandler, handlers.SlaHandler, handlers.HealthHandler, handlers.RosterHandler,
+		handlers.SnapshotHandler, handlers.TeamHandler, appMetrics, config.Server, config.Features, logger)
+
+	err = server.RunServer(ctx)
+
+	stopJobs()
+	jobs.Wait()
 
-	err = server.RunServer()
 	if err != nil {
-		log.Fatalf("unable to run server: %e", err)
+		fatal(logger, "unable to run server", err)
 	}
+	logger.Info("server stopped")
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
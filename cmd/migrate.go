@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"pr-assignment/internal/app/config/db"
+	"pr-assignment/internal/app/config/env"
+	"strconv"
+)
+
+const migrateUsage = "usage: pr-assignment migrate up | down [N] | version | force VERSION"
+
+// runMigrate handles the migrate subcommand, args are the words after "migrate".
+// Arguments are checked before connecting so typos fail fast
+func runMigrate(config env.ConfigDb, logger *slog.Logger, args []string) error {
+	run, err := parseMigrate(args)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := db.NewMigrator(config, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := migrator.Close(); err != nil {
+			logger.Warn("unable to close migrator", "error", err)
+		}
+	}()
+
+	return run(migrator)
+}
+
+func parseMigrate(args []string) (func(*db.Migrator) error, error) {
+	if len(args) == 0 {
+		return nil, errors.New(migrateUsage)
+	}
+
+	switch command, rest := args[0], args[1:]; {
+	case command == "up" && len(rest) == 0:
+		return (*db.Migrator).Up, nil
+	case command == "down" && len(rest) <= 1:
+		// a single step by default, rolling back everything has to be asked for explicitly
+		steps := 1
+		if len(rest) == 1 {
+			var err error
+			steps, err = strconv.Atoi(rest[0])
+			if err != nil || steps <= 0 {
+				return nil, fmt.Errorf("down takes a positive number of steps, got %s", rest[0])
+			}
+		}
+		return func(m *db.Migrator) error { return m.Down(steps) }, nil
+	case command == "version" && len(rest) == 0:
+		return printVersion, nil
+	case command == "force" && len(rest) == 1:
+		version, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return nil, fmt.Errorf("force takes a version, got %s", rest[0])
+		}
+		return func(m *db.Migrator) error { return m.Force(version) }, nil
+	}
+
+	return nil, errors.New(migrateUsage)
+}
+
+func printVersion(m *db.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	latest, err := db.LatestMigration()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", version, dirty, latest)
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestParseMigrate(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"up"}},
+		{args: []string{"down"}},
+		{args: []string{"down", "3"}},
+		{args: []string{"version"}},
+		{args: []string{"force", "12"}},
+		{args: nil, wantErr: migrateUsage},
+		{args: []string{"sideways"}, wantErr: migrateUsage},
+		{args: []string{"up", "2"}, wantErr: migrateUsage},
+		{args: []string{"down", "0"}, wantErr: "down takes a positive number of steps, got 0"},
+		{args: []string{"down", "all"}, wantErr: "down takes a positive number of steps, got all"},
+		{args: []string{"down", "1", "2"}, wantErr: migrateUsage},
+		{args: []string{"version", "now"}, wantErr: migrateUsage},
+		{args: []string{"force"}, wantErr: migrateUsage},
+		{args: []string{"force", "latest"}, wantErr: "force takes a version, got latest"},
+	}
+
+	for _, tt := range tests {
+		run, err := parseMigrate(tt.args)
+		if tt.wantErr == "" {
+			if err != nil || run == nil {
+				t.Errorf("parseMigrate(%q) = %v, want a command", tt.args, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("parseMigrate(%q) err = %v, want %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
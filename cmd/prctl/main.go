@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"pr-assignment/internal/prctl"
+	"syscall"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", envOr("PRCTL_ADDR", "http://localhost:8080"), "service base url")
+	output := flag.String("o", "table", "output format: table or json")
+	dryRun := flag.Bool("dry-run", false, "print the request instead of sending it")
+	timeout := flag.Duration("timeout", 30*time.Second, "request timeout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n%s\n\nflags:\n", prctl.Usage())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	client := prctl.NewClient(*addr, *timeout, *dryRun, os.Stdout)
+	app, err := prctl.NewApp(client, *output, os.Stdout)
+	if err == nil {
+		err = app.Run(ctx, flag.Args())
+	}
+
+	var usageErr *prctl.UsageError
+	switch {
+	case err == nil:
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "prctl: %s\n", err)
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "prctl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
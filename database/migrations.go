@@ -0,0 +1,11 @@
+package database
+
+import "embed"
+
+// Migrations are compiled into the binary so it does not depend on the working directory
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// MigrationsDir is the directory of the migration files inside Migrations
+const MigrationsDir = "migrations"
@@ -0,0 +1,6 @@
+CREATE TABLE team_fallbacks(
+    team_id uuid NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    fallback_team_id uuid NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (team_id, fallback_team_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE pr_reviewers
+    ADD COLUMN from_fallback BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS team_role_rules;
\ No newline at end of file
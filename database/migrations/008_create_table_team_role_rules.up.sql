@@ -0,0 +1,6 @@
+CREATE TABLE team_role_rules(
+    team_id uuid NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    role VARCHAR(32) NOT NULL,
+    min_count INT NOT NULL CHECK (min_count > 0),
+    PRIMARY KEY (team_id, role)
+);
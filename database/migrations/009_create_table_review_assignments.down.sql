@@ -0,0 +1 @@
+DROP TABLE IF EXISTS review_assignments;
\ No newline at end of file
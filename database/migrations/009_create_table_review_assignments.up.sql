@@ -0,0 +1,17 @@
+CREATE TABLE review_assignments(
+    assignment_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    author_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX review_assignments_author_idx ON review_assignments(author_id, assigned_at);
+
+-- created_at is only a time of day here, the real assignment dates are unknown. Existing
+-- assignments are dated to the epoch so that they count in all-time totals but never look
+-- recent to the pairing window or dated stats
+INSERT INTO review_assignments (pull_request_id, author_id, reviewer_id, assigned_at)
+SELECT p.pull_request_id, p.author_id, r.reviewer_id, DATE '1970-01-01' + p.created_at
+FROM pr_reviewers r
+JOIN pull_requests p ON p.pull_request_id = r.pull_request_id;
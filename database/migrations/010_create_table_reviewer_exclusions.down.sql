@@ -0,0 +1 @@
+DROP TABLE IF EXISTS reviewer_exclusions;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE reviewer_exclusions(
+    author_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_symmetric BOOLEAN NOT NULL DEFAULT false,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (author_id, reviewer_id),
+    CHECK (author_id <> reviewer_id)
+);
+
+CREATE INDEX reviewer_exclusions_reviewer_idx ON reviewer_exclusions(reviewer_id);
@@ -0,0 +1,4 @@
+ALTER TABLE pr_reviewers
+    ADD COLUMN state VARCHAR(32) NOT NULL DEFAULT 'assigned',
+    ADD COLUMN assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN responded_at TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+CREATE TABLE review_declines(
+    decline_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    reason TEXT NOT NULL DEFAULT '',
+    declined_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX review_declines_pr_idx ON review_declines(pull_request_id);
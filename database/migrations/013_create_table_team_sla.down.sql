@@ -0,0 +1 @@
+DROP TABLE IF EXISTS team_sla;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE team_sla(
+    team_id uuid PRIMARY KEY NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    first_review_hours INT NOT NULL CHECK (first_review_hours > 0),
+    policy VARCHAR(32) NOT NULL
+);
@@ -0,0 +1,10 @@
+CREATE TABLE sla_escalations(
+    escalation_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    team_id uuid NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    action VARCHAR(32) NOT NULL,
+    new_reviewer_id VARCHAR(255) REFERENCES users(user_id),
+    escalated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (pull_request_id, reviewer_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests
+    ALTER COLUMN created_at TYPE TIME USING created_at::time,
+    ALTER COLUMN merged_at TYPE TIME USING merged_at::time;
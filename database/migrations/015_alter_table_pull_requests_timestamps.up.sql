@@ -0,0 +1,10 @@
+-- created_at and merged_at were times of day without a date, so the real dates are lost.
+-- Every PR gets the migration day, or the day before when that time has not come yet today,
+-- and a PR merged after midnight gets merged_at = created_at instead of a negative time to merge
+ALTER TABLE pull_requests
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING CURRENT_DATE + created_at
+        - CASE WHEN CURRENT_DATE + created_at > LOCALTIMESTAMP THEN INTERVAL '1 day' ELSE INTERVAL '0' END,
+    ALTER COLUMN merged_at TYPE TIMESTAMPTZ USING CASE WHEN status = 'merged' THEN CURRENT_DATE + merged_at
+        - CASE WHEN CURRENT_DATE + merged_at > LOCALTIMESTAMP THEN INTERVAL '1 day' ELSE INTERVAL '0' END END;
+
+UPDATE pull_requests SET merged_at = created_at WHERE merged_at < created_at;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS digest_frequency,
+    DROP COLUMN IF EXISTS digest_sent_at;
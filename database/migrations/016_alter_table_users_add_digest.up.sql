@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN digest_frequency VARCHAR(16) NOT NULL DEFAULT 'none',
+    ADD COLUMN digest_sent_at TIMESTAMPTZ;
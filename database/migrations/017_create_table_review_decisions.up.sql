@@ -0,0 +1,9 @@
+CREATE TABLE review_decisions(
+    decision_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    decision VARCHAR(32) NOT NULL,
+    decided_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX review_decisions_pr_idx ON review_decisions(pull_request_id, decided_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS teams_team_name_idx;
@@ -0,0 +1,15 @@
+-- team names were not unique before, duplicates have to be renamed or merged by hand first
+DO $$
+DECLARE
+    duplicates text;
+BEGIN
+    SELECT string_agg(team_name, ', ' ORDER BY team_name) INTO duplicates
+    FROM (SELECT team_name FROM teams GROUP BY team_name HAVING COUNT(*) > 1) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'duplicate team names: %, rename them before adding the unique index', duplicates;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS teams_team_name_idx;
+CREATE UNIQUE INDEX teams_team_name_idx ON teams(team_name);
@@ -0,0 +1,15 @@
+CREATE TABLE team_moves(
+    move_id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    -- names at the time of the move, teams can be renamed or deleted later
+    from_team VARCHAR(255) NOT NULL,
+    to_team VARCHAR(255) NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    review_policy VARCHAR(32) NOT NULL,
+    reevaluate_authored BOOLEAN NOT NULL DEFAULT false,
+    reassigned_reviews INT NOT NULL DEFAULT 0,
+    reassigned_authored INT NOT NULL DEFAULT 0,
+    moved_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX team_moves_user_idx ON team_moves(user_id, moved_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS pull_requests_team_idx;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS team_id;
+DROP VIEW IF EXISTS team_members;
+DROP TABLE IF EXISTS team_memberships;
@@ -0,0 +1,24 @@
+-- users.team_name stays the primary team, the table holds the other teams of a user
+CREATE TABLE team_memberships(
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_id uuid NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, team_id)
+);
+
+CREATE INDEX team_memberships_team_idx ON team_memberships(team_id);
+
+-- every team of every user, a leftover membership in the primary team is not listed twice
+CREATE VIEW team_members AS
+    SELECT user_id, team_name AS team_id, true AS is_primary FROM users
+    UNION ALL
+    SELECT m.user_id, m.team_id, false FROM team_memberships m
+    JOIN users u ON u.user_id = m.user_id
+    WHERE m.team_id <> u.team_name;
+
+-- the team that reviews the PR, the author's primary team unless chosen at creation
+ALTER TABLE pull_requests ADD COLUMN team_id uuid REFERENCES teams(team_id);
+UPDATE pull_requests p SET team_id = u.team_name FROM users u WHERE u.user_id = p.author_id;
+ALTER TABLE pull_requests ALTER COLUMN team_id SET NOT NULL;
+
+CREATE INDEX pull_requests_team_idx ON pull_requests(team_id);
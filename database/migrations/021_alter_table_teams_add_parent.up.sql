@@ -0,0 +1,4 @@
+ALTER TABLE teams ADD COLUMN parent_team_id uuid REFERENCES teams(team_id) ON DELETE SET NULL,
+    ADD CONSTRAINT teams_parent_not_self CHECK (parent_team_id <> team_id);
+
+CREATE INDEX teams_parent_team_idx ON teams(parent_team_id);
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/pullRequest/create": {
+        "/exclusions/add": {
             "post": {
-                "description": "create new pr and assign reviewers automatically",
+                "description": "never assign reviewer_id to PRs of author_id, symmetric exclusion also works the other way",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,17 +25,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "pull requests"
+                    "exclusions"
                 ],
-                "summary": "Create new Pull Request",
+                "summary": "exclude reviewer from author's PRs",
                 "parameters": [
                     {
-                        "description": "PR DATA",
+                        "description": "author id, reviewer id, symmetric, reason",
                         "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PullRequestQuery"
+                            "$ref": "#/definitions/dto.ExclusionQuery"
                         }
                     }
                 ],
@@ -43,7 +43,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.PrResponse"
+                            "$ref": "#/definitions/model.ReviewerExclusion"
                         }
                     },
                     "400": {
@@ -67,8 +67,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/pullRequest/merge": {
-            "post": {
+        "/exclusions/get": {
+            "get": {
+                "description": "get exclusions where the user is author or reviewer",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,28 +77,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "pull requests"
+                    "exclusions"
                 ],
-                "summary": "Merge Pull Request",
+                "summary": "get exclusions of user",
                 "parameters": [
                     {
-                        "description": "PR ID",
-                        "name": "query",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.PullRequestIdQuery"
-                        }
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PrMergedResponse"
-                            }
+                            "$ref": "#/definitions/dto.ExclusionsResponse"
                         }
                     },
                     "400": {
@@ -121,7 +117,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/pullRequest/reassign": {
+        "/exclusions/remove": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -130,29 +126,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "pull requests"
+                    "exclusions"
                 ],
-                "summary": "Reassign reviewer Pull Request",
+                "summary": "remove reviewer exclusion",
                 "parameters": [
                     {
-                        "description": "Pr id, old reviewer id",
+                        "description": "author id, reviewer id",
                         "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PrReassignQuery"
+                            "$ref": "#/definitions/dto.ExclusionRemoveQuery"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PrReassignResponse"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -175,9 +165,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/stat/pull_request/reviewers": {
+        "/healthz": {
             "get": {
-                "description": "get pull requests with reviewers and their number",
+                "description": "ok while the process serves http, does not touch the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pullRequest/accept": {
+            "post": {
+                "description": "only an assignment that was neither accepted nor reviewed can be accepted, otherwise 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,14 +198,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "pull requests"
+                ],
+                "summary": "Accept review assignment",
+                "parameters": [
+                    {
+                        "description": "Pr id, reviewer id",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AcceptReviewQuery"
+                        }
+                    }
                 ],
-                "summary": "get prs with assigned reviewers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.PrReviewersCount"
+                            "$ref": "#/definitions/model.ReviewAssignment"
                         }
                     },
                     "400": {
@@ -207,6 +231,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -216,9 +246,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/stat/users/reviews": {
-            "get": {
-                "description": "get users and in how many pull requests they are reviewers",
+        "/pullRequest/create": {
+            "post": {
+                "description": "create new pr and assign reviewers automatically from team_name, one of the author's teams,\nthe primary team when empty",
                 "consumes": [
                     "application/json"
                 ],
@@ -226,14 +256,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "pull requests"
+                ],
+                "summary": "Create new Pull Request",
+                "parameters": [
+                    {
+                        "description": "PR DATA",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PullRequestQuery"
+                        }
+                    }
                 ],
-                "summary": "get users and number of reviews",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.UserReviewsCount"
+                            "$ref": "#/definitions/dto.PrResponse"
                         }
                     },
                     "400": {
@@ -257,8 +298,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/team/add": {
+        "/pullRequest/decline": {
             "post": {
+                "description": "decline the assignment, a substitute reviewer is assigned automatically. The decline is\ncounted even when no one can replace the reviewer, who then stays on the PR and gets 409.\nOnly an assigned or accepted review can be declined, a reviewed one is 409 WRONG_REVIEW_STATE",
                 "consumes": [
                     "application/json"
                 ],
@@ -266,25 +308,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "pull requests"
                 ],
-                "summary": "add new team",
+                "summary": "Decline review assignment",
                 "parameters": [
                     {
-                        "description": "team object: team_name {members}",
-                        "name": "team",
+                        "description": "Pr id, reviewer id, optional reason",
+                        "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Team"
+                            "$ref": "#/definitions/dto.DeclineReviewQuery"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Team"
+                            "$ref": "#/definitions/dto.PrReassignResponse"
                         }
                     },
                     "400": {
@@ -299,6 +341,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -308,32 +356,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/team/get": {
+        "/pullRequest/list": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list pull requests with current reviewers, oldest first. Rows are streamed, so large exports do not need to fit in memory",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "teams"
+                    "pull requests"
                 ],
-                "summary": "get existing team",
+                "summary": "List pull requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "team_name",
+                        "description": "only PRs of this team",
                         "name": "team_name",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "author id",
+                        "name": "author_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created or merged",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Team"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PullRequest"
+                            }
                         }
                     },
                     "400": {
@@ -348,6 +416,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -357,9 +431,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/team/kill": {
+        "/pullRequest/merge": {
             "post": {
-                "description": "set users status to not active by a given team name",
                 "consumes": [
                     "application/json"
                 ],
@@ -367,17 +440,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "pull requests"
                 ],
-                "summary": "deactivate all users in team",
+                "summary": "Merge Pull Request",
                 "parameters": [
                     {
-                        "description": "team_name",
-                        "name": "team_name",
+                        "description": "PR ID",
+                        "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamName"
+                            "$ref": "#/definitions/dto.PullRequestIDQuery"
                         }
                     }
                 ],
@@ -385,7 +458,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Team"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PrMergedResponse"
+                            }
                         }
                     },
                     "400": {
@@ -409,8 +485,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/getReview": {
-            "get": {
+        "/pullRequest/reassign": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -418,23 +494,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "pull requests"
                 ],
-                "summary": "get prs where user is reviewer",
+                "summary": "Reassign reviewer Pull Request",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "user id",
-                        "name": "user_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Pr id, old reviewer id",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PrReassignQuery"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserPrsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PrReassignResponse"
+                            }
                         }
                     },
                     "400": {
@@ -458,7 +539,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/setIsActive": {
+        "/pullRequest/review": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -467,25 +548,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "pull requests"
                 ],
-                "summary": "set user is active status",
+                "summary": "Submit review decision",
                 "parameters": [
                     {
-                        "description": "user id, status",
+                        "description": "Pr id, reviewer id, decision: approved, changes_requested or commented",
                         "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.StatusQuery"
+                            "$ref": "#/definitions/dto.ReviewDecisionQuery"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/model.ReviewDecision"
                         }
                     },
                     "400": {
@@ -500,6 +581,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -508,250 +595,3160 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.PrMerged": {
-            "type": "object",
-            "properties": {
-                "assigned_reviewers": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "author_id": {
-                    "type": "string"
-                },
-                "merged_at": {
-                    "type": "string"
-                },
-                "pull_request_id": {
-                    "type": "string"
-                },
-                "pull_request_name": {
-                    "type": "string"
-                },
-                "status": {
-                    "$ref": "#/definitions/model.PRstatus"
-                }
-            }
         },
-        "dto.PrMergedResponse": {
-            "type": "object",
-            "properties": {
-                "pr": {
-                    "$ref": "#/definitions/dto.PrMerged"
+        "/readyz": {
+            "get": {
+                "description": "pings the database and checks that migrations are applied up to the version of the binary",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.PrReassignQuery": {
-            "type": "object",
-            "properties": {
-                "old_reviewer_id": {
-                    "type": "string"
-                },
+        "/snapshot/export": {
+            "get": {
+                "description": "teams with fallbacks, role rules and SLA, users, exclusions, PRs with reviewers and the\nassignment, decline, decision and escalation history as a versioned JSON archive. Tables are\nread in one transaction, so the archive is consistent. Served only with FEATURE_SNAPSHOTS",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "summary": "export the whole dataset",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Snapshot"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshot/import": {
+            "post": {
+                "description": "replace wipes all data and loads the archive. merge adds and updates rows and never deletes,\nteams are matched by name. The archive is validated first and written in one transaction,\nimporting the same archive again writes nothing. Served only with FEATURE_SNAPSHOTS",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "summary": "import an exported archive",
+                "parameters": [
+                    {
+                        "description": "archive from /snapshot/export",
+                        "name": "snapshot",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Snapshot"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "replace or merge",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only count the rows that would be written",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SnapshotImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/flow": {
+            "get": {
+                "description": "get median and p90 time to first review, approval and merge per team and per author for PRs created in the range. Defaults to the last 30 days",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get review flow metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from date, 2006-01-02",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to date exclusive, 2006-01-02",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "team name",
+                        "name": "team_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.FlowReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/pairings": {
+            "get": {
+                "description": "get matrix of how many times each reviewer was assigned to each author, rows are authors.\ncsv and ndjson stream one row per pair assigned at least once instead",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get author and reviewer pairings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PairingMatrix"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/pull_request/reviewers": {
+            "get": {
+                "description": "get pull requests with reviewers and their number. Rows are streamed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get prs with assigned reviewers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only PRs of this team",
+                        "name": "team_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PrReviewersCount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/sla": {
+            "get": {
+                "description": "get number of SLA breaches per team and per reviewer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get SLA breaches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SlaReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/teams": {
+            "get": {
+                "description": "get open and merged PRs, average reviewers per PR, active and inactive members and share of PRs with fewer reviewers than the team requires, PRs belong to the team they were created for.\nWith rollup a team also counts PRs and members of all its sub-teams. Members are primary and secondary ones, a user in several teams of the subtree is counted once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get team aggregates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "team name, all teams when empty",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include sub-teams",
+                        "name": "rollup",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TeamStats"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/users/declines": {
+            "get": {
+                "description": "get users who declined review assignments and how many times. Rows are streamed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get users and number of declined reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.UserDeclinesCount"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/users/reviews": {
+            "get": {
+                "description": "get users and in how many pull requests they are reviewers.\nWith from, to or bucket returns model.UserReviewLoad series instead: assignments per user in every day, week or month of the range, reassigned reviews included. Range defaults to the last 30 days, bucket to day.\nRows are streamed, a series is written as soon as the user is complete",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "get users and number of reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json, csv or ndjson, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from date, 2006-01-02",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to date exclusive, 2006-01-02",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day, week or month",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only members of this team",
+                        "name": "team_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UserReviewsCount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/add": {
+            "post": {
+                "description": "members must be new users, users that already have a primary team are moved with /team/moveMember, a user_id listed twice is rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "add new team",
+                "parameters": [
+                    {
+                        "description": "team object: team_name {members}",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/addMember": {
+            "post": {
+                "description": "creates the user in the team, repeating the call is fine. A user of another team keeps\nthe primary team and gets this one as a secondary team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "add member to team",
+                "parameters": [
+                    {
+                        "description": "team_name, user_id, username, is_active, role",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamMemberQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/delete": {
+            "post": {
+                "description": "members, active or not, move to move_members_to, required unless the team is empty.\nopen_prs decides about open PRs filed in the team: block (default) refuses with 409, keep moves\nthem to the new team, reassign also picks pending reviewers again from the new team.\nSub-teams move up to the parent of the team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "delete team",
+                "parameters": [
+                    {
+                        "description": "team_name, move_members_to, open_prs",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamDeleteQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamDeletion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/get": {
+            "get": {
+                "description": "with subtree all sub-teams are nested in sub_teams",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "get existing team",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "team_name",
+                        "name": "team_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include sub-teams",
+                        "name": "subtree",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/import": {
+            "post": {
+                "description": "yaml roster (teams: [{team_name, members: [{user_id, username, is_active, role}]}]) or csv with\nteam_name, user_id, username and optional is_active, role columns. The whole roster is validated\nbefore anything is written. Missing teams are created, users are created or updated.\nWith reconcile active users of the roster teams missing from the roster are deactivated",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "bulk import teams and members",
+                "parameters": [
+                    {
+                        "description": "roster file",
+                        "name": "roster",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "yaml or csv, taken from Content-Type when empty",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only report the diff",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "deactivate users of the roster teams missing from the roster",
+                        "name": "reconcile",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RosterImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/kill": {
+            "post": {
+                "description": "set users status to not active by a given team name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "deactivate all users in team",
+                "parameters": [
+                    {
+                        "description": "team_name",
+                        "name": "team_name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamName"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/moveMember": {
+            "post": {
+                "description": "reviews decides about unfinished reviews of the user: keep (default) or reassign, which hands\nreviews of PRs from outside the new team back to the authors' teams. reevaluate_authored picks\npending reviewers of the user's open PRs again from the new team. The move is audited",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "move user to another primary team",
+                "parameters": [
+                    {
+                        "description": "user_id, team_name, reviews, reevaluate_authored",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamMoveQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamMove"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/moves": {
+            "get": {
+                "description": "latest first, moves by /team/moveMember, /team/add, /team/delete and roster imports",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "audit trail of team changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only moves of the user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only moves from or to the team",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "100 by default, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TeamMove"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/removeMember": {
+            "post": {
+                "description": "for a secondary team only the membership goes, reviews of the team's PRs are reassigned.\nFrom the primary team a user without PRs and reviews is deleted, others are kept for the\nhistory, deactivated, and their reviews are reassigned. A user with secondary teams has to\nbe moved instead",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "remove member from team",
+                "parameters": [
+                    {
+                        "description": "team_name, user_id",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamMemberRemoveQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MemberRemovalResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/rename": {
+            "post": {
+                "description": "members, fallbacks, role rules and SLA stay with the team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "rename team",
+                "parameters": [
+                    {
+                        "description": "team_name, new_team_name",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamRenameQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/setFallbacks": {
+            "post": {
+                "description": "set ordered list of teams to take reviewers from when the team can not fill the reviewers count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "set fallback teams",
+                "parameters": [
+                    {
+                        "description": "team_name, fallback_teams",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamFallbacksQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/setParent": {
+            "post": {
+                "description": "puts the team under the parent, an empty parent_team makes it a top level team. Stats roll up\nto parents and reviewer selection falls back to sibling and parent teams. A sub-team of the team\ncan not become its parent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "set parent team",
+                "parameters": [
+                    {
+                        "description": "team_name, parent_team",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamParentQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/setRoleRule": {
+            "post": {
+                "description": "require at least min_count reviewers with the role or higher on every PR of the team, 0 removes the rule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "set team role rule",
+                "parameters": [
+                    {
+                        "description": "team_name, role, min_count",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamRoleRuleQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/setSla": {
+            "post": {
+                "description": "set business hours reviewers have to act and what to do on breach: add_lead or reassign",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "set team review SLA",
+                "parameters": [
+                    {
+                        "description": "team_name, first_review_hours, policy",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamSlaQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamSla"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/sync": {
+            "post": {
+                "description": "reads groups from the configured directory source and imports them with reconcile, the same\nas the periodic job. Users of teams the directory manages who are missing from it are deactivated\nand their reviews reassigned, other teams are left alone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "sync teams with the directory now",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "only report the diff",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RosterImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams": {
+            "get": {
+                "description": "teams ordered by name with member counts (primary and secondary members), total is the number of matching teams",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "list teams",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "part of the team name, case-insensitive",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 50 by default, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "teams to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/getReview": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "get prs where user is reviewer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPrsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/getTeams": {
+            "get": {
+                "description": "the primary team and secondary teams of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "teams of user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UserTeams"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/setDigest": {
+            "post": {
+                "description": "set how often the user gets a digest of PRs waiting for review: daily, weekly or none",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "set review digest settings",
+                "parameters": [
+                    {
+                        "description": "user id, email, frequency",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DigestSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DigestSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/setIsActive": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "set user is active status",
+                "parameters": [
+                    {
+                        "description": "user id, status",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatusQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/setRole": {
+            "post": {
+                "description": "set role of the user: member, senior or lead",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "set user role",
+                "parameters": [
+                    {
+                        "description": "user id, role",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRoleQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AcceptReviewQuery": {
+            "type": "object",
+            "properties": {
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeclineReviewQuery": {
+            "type": "object",
+            "properties": {
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ExclusionQuery": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "symmetric": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.ExclusionRemoveQuery": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ExclusionsResponse": {
+            "type": "object",
+            "properties": {
+                "exclusions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewerExclusion"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PrMerged": {
+            "type": "object",
+            "properties": {
+                "assigned_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "author_id": {
+                    "type": "string"
+                },
+                "excluded_candidates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fallback_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "merged_at": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PRstatus"
+                },
+                "team_name": {
+                    "description": "team that reviews the PR",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PrMergedResponse": {
+            "type": "object",
+            "properties": {
+                "pr": {
+                    "$ref": "#/definitions/dto.PrMerged"
+                }
+            }
+        },
+        "dto.PrReassignQuery": {
+            "type": "object",
+            "properties": {
+                "old_reviewer_id": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PrReassignResponse": {
+            "type": "object",
+            "properties": {
+                "pr": {
+                    "$ref": "#/definitions/dto.PrResponse"
+                },
+                "replaced_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PrResponse": {
+            "type": "object",
+            "properties": {
+                "assigned_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "author_id": {
+                    "type": "string"
+                },
+                "excluded_candidates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fallback_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PRstatus"
+                },
+                "team_name": {
+                    "description": "team that reviews the PR",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PullRequestIDQuery": {
+            "type": "object",
+            "properties": {
+                "pull_request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PullRequestQuery": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "team_name": {
+                    "description": "team that reviews the PR, one of the author's teams, the primary team by default",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReviewDecisionQuery": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "$ref": "#/definitions/model.Decision"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StatusQuery": {
+            "type": "object",
+            "properties": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamDeleteQuery": {
+            "type": "object",
+            "properties": {
+                "move_members_to": {
+                    "description": "required when the team has members",
+                    "type": "string"
+                },
+                "open_prs": {
+                    "description": "block, keep or reassign, block by default",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.OpenPRPolicy"
+                        }
+                    ]
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamFallbacksQuery": {
+            "type": "object",
+            "properties": {
+                "fallback_teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamMemberQuery": {
+            "type": "object",
+            "properties": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamMemberRemoveQuery": {
+            "type": "object",
+            "properties": {
+                "team_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamMoveQuery": {
+            "type": "object",
+            "properties": {
+                "reevaluate_authored": {
+                    "type": "boolean"
+                },
+                "reviews": {
+                    "description": "keep or reassign, keep by default",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.MoveReviewPolicy"
+                        }
+                    ]
+                },
+                "team_name": {
+                    "description": "team to move the user to",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamName": {
+            "type": "object",
+            "properties": {
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamParentQuery": {
+            "type": "object",
+            "properties": {
+                "parent_team": {
+                    "description": "empty makes the team a top level team",
+                    "type": "string"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamRenameQuery": {
+            "type": "object",
+            "properties": {
+                "new_team_name": {
+                    "type": "string"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamRoleRuleQuery": {
+            "type": "object",
+            "properties": {
+                "min_count": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamSlaQuery": {
+            "type": "object",
+            "properties": {
+                "first_review_hours": {
+                    "type": "integer"
+                },
+                "policy": {
+                    "$ref": "#/definitions/model.SlaPolicy"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserPrsResponse": {
+            "type": "object",
+            "properties": {
+                "pull_requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PullRequestShort"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserResponse": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                }
+            }
+        },
+        "dto.UserRoleQuery": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CustomError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/model.ErrCode"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Decision": {
+            "type": "string",
+            "enum": [
+                "approved",
+                "changes_requested",
+                "commented"
+            ],
+            "x-enum-varnames": [
+                "APPROVED",
+                "ChangesRequested",
+                "COMMENTED"
+            ]
+        },
+        "model.DigestFrequency": {
+            "type": "string",
+            "enum": [
+                "daily",
+                "weekly",
+                "none"
+            ],
+            "x-enum-varnames": [
+                "DAILY",
+                "WEEKLY",
+                "NONE"
+            ]
+        },
+        "model.DigestSettings": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "$ref": "#/definitions/model.DigestFrequency"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ErrCode": {
+            "type": "string",
+            "enum": [
+                "SOME_ERROR",
+                "TEAM_EXISTS",
+                "PR_EXISTS",
+                "PR_MERGED",
+                "NOT_ASSIGNED",
+                "NO_CANDIDATE",
+                "NOT_FOUND",
+                "BAD_REQUEST",
+                "REVIEWER_EXCLUDED",
+                "INTERNAL_ERROR",
+                "NOT_READY",
+                "HAS_OPEN_PRS",
+                "WRONG_REVIEW_STATE"
+            ],
+            "x-enum-varnames": [
+                "DefaultError",
+                "TeamExists",
+                "PrExists",
+                "PrMerged",
+                "NotAssigned",
+                "NoCandidate",
+                "NotFound",
+                "BadRequest",
+                "Excluded",
+                "InternalError",
+                "NotReady",
+                "HasOpenPRs",
+                "WrongState"
+            ]
+        },
+        "model.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/model.CustomError"
+                }
+            }
+        },
+        "model.FlowReport": {
+            "type": "object",
+            "properties": {
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamFlowMetrics"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserFlowMetrics"
+                    }
+                }
+            }
+        },
+        "model.MemberRemoval": {
+            "type": "string",
+            "enum": [
+                "deleted",
+                "deactivated",
+                "membership_removed"
+            ],
+            "x-enum-varnames": [
+                "MemberDeleted",
+                "MemberDeactivated",
+                "MembershipRemoved"
+            ]
+        },
+        "model.MemberRemovalResult": {
+            "type": "object",
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/model.MemberRemoval"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MoveReviewPolicy": {
+            "type": "string",
+            "enum": [
+                "keep",
+                "reassign"
+            ],
+            "x-enum-varnames": [
+                "KeepReviews",
+                "ReassignReviews"
+            ]
+        },
+        "model.MoveSource": {
+            "type": "string",
+            "enum": [
+                "move",
+                "team_add",
+                "team_delete",
+                "roster"
+            ],
+            "x-enum-varnames": [
+                "MoveExplicit",
+                "MoveTeamAdd",
+                "MoveTeamDelete",
+                "MoveRoster"
+            ]
+        },
+        "model.OpenPRPolicy": {
+            "type": "string",
+            "enum": [
+                "block",
+                "keep",
+                "reassign"
+            ],
+            "x-enum-varnames": [
+                "BlockOpenPRs",
+                "KeepOpenPRs",
+                "ReassignOpenPRs"
+            ]
+        },
+        "model.PRstatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "merged"
+            ],
+            "x-enum-varnames": [
+                "CREATED",
+                "MERGED"
+            ]
+        },
+        "model.PairingMatrix": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "counts": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.Percentiles": {
+            "type": "object",
+            "properties": {
+                "median_seconds": {
+                    "type": "number"
+                },
+                "p90_seconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.PrReviewersCount": {
+            "type": "object",
+            "properties": {
+                "pull_request": {
+                    "$ref": "#/definitions/model.PullRequestShort"
+                },
+                "reviewers_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PullRequest": {
+            "type": "object",
+            "properties": {
+                "assigned_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "author_id": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "excluded_candidates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fallback_reviewers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mergedAt": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PRstatus"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PullRequestShort": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PRstatus"
+                }
+            }
+        },
+        "model.ReviewAssignment": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "responded_at": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/model.ReviewState"
+                }
+            }
+        },
+        "model.ReviewDecision": {
+            "type": "object",
+            "properties": {
+                "decided_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "$ref": "#/definitions/model.Decision"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReviewState": {
+            "type": "string",
+            "enum": [
+                "assigned",
+                "accepted",
+                "reviewed"
+            ],
+            "x-enum-varnames": [
+                "ASSIGNED",
+                "ACCEPTED",
+                "REVIEWED"
+            ]
+        },
+        "model.ReviewerExclusion": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "symmetric": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.ReviewerSlaBreaches": {
+            "type": "object",
+            "properties": {
+                "breaches": {
+                    "type": "integer"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RoleRule": {
+            "type": "object",
+            "properties": {
+                "min_count": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                }
+            }
+        },
+        "model.RosterChange": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "unchanged",
+                "deactivated"
+            ],
+            "x-enum-varnames": [
+                "RosterCreated",
+                "RosterUpdated",
+                "RosterUnchanged",
+                "RosterDeactivated"
+            ]
+        },
+        "model.RosterImportResult": {
+            "type": "object",
+            "properties": {
+                "created_teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "reconcile": {
+                    "type": "boolean"
+                },
+                "reconciled_teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RosterRow"
+                    }
+                },
+                "summary": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "model.RosterRow": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "$ref": "#/definitions/model.RosterChange"
+                },
+                "fields": {
+                    "description": "changed fields of updated users",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SlaPolicy": {
+            "type": "string",
+            "enum": [
+                "add_lead",
+                "reassign"
+            ],
+            "x-enum-varnames": [
+                "AddLead",
+                "Reassign"
+            ]
+        },
+        "model.SlaReport": {
+            "type": "object",
+            "properties": {
+                "reviewers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewerSlaBreaches"
+                    }
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamSlaBreaches"
+                    }
+                }
+            }
+        },
+        "model.Snapshot": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotAssignment"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotDecision"
+                    }
+                },
+                "declines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotDecline"
+                    }
+                },
+                "escalations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotEscalation"
+                    }
+                },
+                "pull_requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotPullRequest"
+                    }
+                },
+                "reviewer_exclusions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotExclusion"
+                    }
+                },
+                "reviewers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotReviewer"
+                    }
+                },
+                "team_fallbacks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotFallback"
+                    }
+                },
+                "team_memberships": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotMembership"
+                    }
+                },
+                "team_moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotTeamMove"
+                    }
+                },
+                "team_role_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotRoleRule"
+                    }
+                },
+                "team_sla": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotTeamSla"
+                    }
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotTeam"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotUser"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SnapshotAssignment": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string"
+                },
+                "author_id": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotDecision": {
+            "type": "object",
+            "properties": {
+                "decided_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "$ref": "#/definitions/model.Decision"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotDecline": {
+            "type": "object",
+            "properties": {
+                "declined_at": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotEscalation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/model.SlaPolicy"
+                },
+                "escalated_at": {
+                    "type": "string"
+                },
+                "new_reviewer_id": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotExclusion": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "is_symmetric": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotFallback": {
+            "type": "object",
+            "properties": {
+                "fallback_team_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotImportResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "matched_teams": {
+                    "description": "archive teams merged into existing teams of the same name",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mode": {
+                    "$ref": "#/definitions/model.SnapshotMode"
+                },
+                "written": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "model.SnapshotMembership": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotMode": {
+            "type": "string",
+            "enum": [
+                "replace",
+                "merge"
+            ],
+            "x-enum-varnames": [
+                "SnapshotReplace",
+                "SnapshotMerge"
+            ]
+        },
+        "model.SnapshotPullRequest": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "merged_at": {
+                    "type": "string"
+                },
+                "pull_request_id": {
+                    "type": "string"
+                },
+                "pull_request_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PRstatus"
+                },
+                "team_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotReviewer": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string"
+                },
+                "from_fallback": {
+                    "type": "boolean"
+                },
                 "pull_request_id": {
                     "type": "string"
+                },
+                "responded_at": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/model.ReviewState"
+                }
+            }
+        },
+        "model.SnapshotRoleRule": {
+            "type": "object",
+            "properties": {
+                "min_count": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "team_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotTeam": {
+            "type": "object",
+            "properties": {
+                "parent_team_id": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "string"
+                },
+                "team_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotTeamMove": {
+            "type": "object",
+            "properties": {
+                "from_team": {
+                    "type": "string"
+                },
+                "moved_at": {
+                    "type": "string"
+                },
+                "reassigned_authored": {
+                    "type": "integer"
+                },
+                "reassigned_reviews": {
+                    "type": "integer"
+                },
+                "reevaluate_authored": {
+                    "type": "boolean"
+                },
+                "review_policy": {
+                    "$ref": "#/definitions/model.MoveReviewPolicy"
+                },
+                "source": {
+                    "$ref": "#/definitions/model.MoveSource"
+                },
+                "to_team": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "dto.PrReassignResponse": {
+        "model.SnapshotTeamSla": {
             "type": "object",
             "properties": {
-                "pr": {
-                    "$ref": "#/definitions/dto.PrResponse"
+                "first_review_hours": {
+                    "type": "integer"
                 },
-                "replaced_by": {
+                "policy": {
+                    "$ref": "#/definitions/model.SlaPolicy"
+                },
+                "team_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.PrResponse": {
+        "model.SnapshotUser": {
             "type": "object",
             "properties": {
-                "assigned_reviewers": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "digest_frequency": {
+                    "$ref": "#/definitions/model.DigestFrequency"
                 },
-                "author_id": {
+                "digest_sent_at": {
                     "type": "string"
                 },
-                "pull_request_id": {
+                "email": {
                     "type": "string"
                 },
-                "pull_request_name": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "team_id": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.PRstatus"
-                }
-            }
-        },
-        "dto.PullRequestIdQuery": {
-            "type": "object",
-            "properties": {
-                "pull_request_id": {
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "dto.PullRequestQuery": {
+        "model.Team": {
             "type": "object",
             "properties": {
-                "author_id": {
-                    "type": "string"
+                "fallback_teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "pull_request_id": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamMember"
+                    }
+                },
+                "parent_team": {
                     "type": "string"
                 },
-                "pull_request_name": {
+                "role_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RoleRule"
+                    }
+                },
+                "secondary_members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamMember"
+                    }
+                },
+                "sla": {
+                    "$ref": "#/definitions/model.TeamSla"
+                },
+                "sub_teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Team"
+                    }
+                },
+                "team_name": {
                     "type": "string"
                 }
             }
         },
-        "dto.StatusQuery": {
+        "model.TeamDeletion": {
             "type": "object",
             "properties": {
-                "is_active": {
-                    "type": "boolean"
+                "moved_members": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "user_id": {
+                "moved_to": {
+                    "type": "string"
+                },
+                "open_prs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "open_prs_policy": {
+                    "$ref": "#/definitions/model.OpenPRPolicy"
+                },
+                "reassigned": {
+                    "description": "reviewers replaced under the reassign policy",
+                    "type": "integer"
+                },
+                "team_name": {
                     "type": "string"
                 }
             }
         },
-        "dto.TeamName": {
+        "model.TeamFlowMetrics": {
             "type": "object",
             "properties": {
+                "pull_requests": {
+                    "type": "integer"
+                },
                 "team_name": {
                     "type": "string"
+                },
+                "time_to_approval": {
+                    "$ref": "#/definitions/model.Percentiles"
+                },
+                "time_to_first_review": {
+                    "$ref": "#/definitions/model.Percentiles"
+                },
+                "time_to_merge": {
+                    "$ref": "#/definitions/model.Percentiles"
                 }
             }
         },
-        "dto.UserPrsResponse": {
+        "model.TeamList": {
             "type": "object",
             "properties": {
-                "pull_requests": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "teams": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.PullRequestShort"
+                        "$ref": "#/definitions/model.TeamSummary"
                     }
                 },
-                "user_id": {
-                    "type": "string"
+                "total": {
+                    "description": "matching teams without limit and offset",
+                    "type": "integer"
                 }
             }
         },
-        "dto.UserResponse": {
+        "model.TeamMember": {
             "type": "object",
             "properties": {
-                "user": {
-                    "$ref": "#/definitions/model.User"
+                "is_active": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "model.CustomError": {
+        "model.TeamMove": {
             "type": "object",
             "properties": {
-                "code": {
+                "from_team": {
                     "type": "string"
                 },
-                "message": {
-                    "$ref": "#/definitions/model.ErrCode"
+                "move_id": {
+                    "type": "integer"
+                },
+                "moved_at": {
+                    "type": "string"
+                },
+                "reassigned_authored": {
+                    "description": "pending reviewers replaced on the user's own open PRs",
+                    "type": "integer"
+                },
+                "reassigned_reviews": {
+                    "description": "reviews of the user handed to someone else",
+                    "type": "integer"
+                },
+                "reevaluate_authored": {
+                    "type": "boolean"
+                },
+                "review_policy": {
+                    "$ref": "#/definitions/model.MoveReviewPolicy"
+                },
+                "source": {
+                    "$ref": "#/definitions/model.MoveSource"
+                },
+                "to_team": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "model.ErrCode": {
-            "type": "string",
-            "enum": [
-                "TEAM_EXISTS",
-                "PR_EXISTS",
-                "PR_MERGED",
-                "NOT_ASSIGNED",
-                "NO_CANDIDATE",
-                "NOT_FOUND",
-                "INTERNAL_ERROR"
-            ],
-            "x-enum-varnames": [
-                "TEAM_EXISTS",
-                "PR_EXISTS",
-                "PR_MERGED",
-                "NOT_ASSIGNED",
-                "NO_CANDIDATE",
-                "NOT_FOUND",
-                "INTERNAL_ERROR"
-            ]
-        },
-        "model.ErrorResponse": {
+        "model.TeamSla": {
             "type": "object",
             "properties": {
-                "error": {
-                    "$ref": "#/definitions/model.CustomError"
+                "first_review_hours": {
+                    "type": "integer"
+                },
+                "policy": {
+                    "$ref": "#/definitions/model.SlaPolicy"
                 }
             }
         },
-        "model.PRstatus": {
-            "type": "string",
-            "enum": [
-                "created",
-                "merged"
-            ],
-            "x-enum-varnames": [
-                "CREATED",
-                "MERGED"
-            ]
-        },
-        "model.PrReviewersCount": {
+        "model.TeamSlaBreaches": {
             "type": "object",
             "properties": {
-                "pull_request": {
-                    "$ref": "#/definitions/model.PullRequestShort"
-                },
-                "reviewers_count": {
+                "breaches": {
                     "type": "integer"
+                },
+                "team_name": {
+                    "type": "string"
                 }
             }
         },
-        "model.PullRequestShort": {
+        "model.TeamStats": {
             "type": "object",
             "properties": {
-                "author_id": {
-                    "type": "string"
+                "active_members": {
+                    "type": "integer"
                 },
-                "pull_request_id": {
+                "avg_reviewers": {
+                    "type": "number"
+                },
+                "inactive_members": {
+                    "type": "integer"
+                },
+                "merged_prs": {
+                    "type": "integer"
+                },
+                "open_prs": {
+                    "type": "integer"
+                },
+                "parent_team": {
                     "type": "string"
                 },
-                "pull_request_name": {
+                "required_reviewers": {
+                    "type": "integer"
+                },
+                "team_name": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.PRstatus"
+                "under_reviewed_prs": {
+                    "type": "integer"
+                },
+                "under_reviewed_share": {
+                    "type": "number"
                 }
             }
         },
-        "model.Team": {
+        "model.TeamSummary": {
             "type": "object",
             "properties": {
+                "active_members": {
+                    "type": "integer"
+                },
                 "members": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.TeamMember"
-                    }
+                    "type": "integer"
+                },
+                "parent_team": {
+                    "type": "string"
                 },
                 "team_name": {
                     "type": "string"
                 }
             }
         },
-        "model.TeamMember": {
+        "model.User": {
             "type": "object",
             "properties": {
                 "is_active": {
                     "type": "boolean"
                 },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "team_name": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "string"
                 },
@@ -760,19 +3757,33 @@ const docTemplate = `{
                 }
             }
         },
-        "model.User": {
+        "model.UserDeclinesCount": {
             "type": "object",
             "properties": {
-                "is_active": {
-                    "type": "boolean"
+                "declines_count": {
+                    "type": "integer"
                 },
-                "team_name": {
-                    "type": "string"
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                }
+            }
+        },
+        "model.UserFlowMetrics": {
+            "type": "object",
+            "properties": {
+                "pull_requests": {
+                    "type": "integer"
                 },
-                "user_id": {
-                    "type": "string"
+                "time_to_approval": {
+                    "$ref": "#/definitions/model.Percentiles"
                 },
-                "username": {
+                "time_to_first_review": {
+                    "$ref": "#/definitions/model.Percentiles"
+                },
+                "time_to_merge": {
+                    "$ref": "#/definitions/model.Percentiles"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
@@ -787,6 +3798,36 @@ const docTemplate = `{
                     "$ref": "#/definitions/model.User"
                 }
             }
+        },
+        "model.UserRole": {
+            "type": "string",
+            "enum": [
+                "member",
+                "senior",
+                "lead"
+            ],
+            "x-enum-varnames": [
+                "MEMBER",
+                "SENIOR",
+                "LEAD"
+            ]
+        },
+        "model.UserTeams": {
+            "type": "object",
+            "properties": {
+                "primary_team": {
+                    "type": "string"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
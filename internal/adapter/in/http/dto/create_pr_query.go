@@ -4,4 +4,6 @@ type PullRequestQuery struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	// team that reviews the PR, one of the author's teams, the primary team by default
+	TeamName string `json:"team_name,omitempty"`
 }
@@ -0,0 +1,5 @@
+package dto
+
+type DirectorySyncQuery struct {
+	DryRun bool `form:"dry_run"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type ExclusionQuery struct {
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+	Symmetric  bool   `json:"symmetric"`
+	Reason     string `json:"reason"`
+}
+
+type ExclusionRemoveQuery struct {
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+}
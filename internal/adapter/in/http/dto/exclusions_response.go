@@ -0,0 +1,8 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type ExclusionsResponse struct {
+	UserID     string                    `json:"user_id"`
+	Exclusions []model.ReviewerExclusion `json:"exclusions"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type FlowQuery struct {
+	From     time.Time `form:"from" time_format:"2006-01-02"`
+	To       time.Time `form:"to" time_format:"2006-01-02"`
+	TeamName string    `form:"team_name"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type PullRequestListQuery struct {
+	TeamName string         `form:"team_name"`
+	AuthorID string         `form:"author_id"`
+	Status   model.PRstatus `form:"status"`
+}
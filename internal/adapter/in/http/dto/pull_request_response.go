@@ -7,7 +7,11 @@ import (
 
 type PrResponse struct {
 	model.PullRequestShort
-	AssignedReviewers []string `json:"assigned_reviewers"`
+	// team that reviews the PR
+	TeamName           string   `json:"team_name,omitempty"`
+	AssignedReviewers  []string `json:"assigned_reviewers"`
+	FallbackReviewers  []string `json:"fallback_reviewers,omitempty"`
+	ExcludedCandidates []string `json:"excluded_candidates,omitempty"`
 }
 
 type PrMerged struct {
@@ -0,0 +1,9 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type ReviewDecisionQuery struct {
+	PullRequestID string         `json:"pull_request_id"`
+	ReviewerID    string         `json:"reviewer_id"`
+	Decision      model.Decision `json:"decision"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import (
+	"pr-assignment/internal/model"
+	"time"
+)
+
+type ReviewLoadQuery struct {
+	From     time.Time        `form:"from" time_format:"2006-01-02"`
+	To       time.Time        `form:"to" time_format:"2006-01-02"`
+	Bucket   model.StatBucket `form:"bucket"`
+	TeamName string           `form:"team_name"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type RosterImportQuery struct {
+	// yaml or csv, taken from Content-Type when empty
+	Format    string `form:"format"`
+	DryRun    bool   `form:"dry_run"`
+	Reconcile bool   `form:"reconcile"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type SnapshotImportQuery struct {
+	// replace or merge
+	Mode   model.SnapshotMode `form:"mode" binding:"required"`
+	DryRun bool               `form:"dry_run"`
+}
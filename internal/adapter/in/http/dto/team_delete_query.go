@@ -0,0 +1,11 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type TeamDeleteQuery struct {
+	TeamName string `json:"team_name"`
+	// required when the team has members
+	MoveMembersTo string `json:"move_members_to,omitempty"`
+	// block, keep or reassign, block by default
+	OpenPRs model.OpenPRPolicy `json:"open_prs,omitempty"`
+}
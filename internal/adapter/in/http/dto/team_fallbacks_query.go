@@ -0,0 +1,6 @@
+package dto
+
+type TeamFallbacksQuery struct {
+	TeamName      string   `json:"team_name"`
+	FallbackTeams []string `json:"fallback_teams"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type TeamGetQuery struct {
+	TeamName string `form:"team_name"`
+	Subtree  bool   `form:"subtree"`
+}
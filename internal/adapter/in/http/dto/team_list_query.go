@@ -0,0 +1,8 @@
+package dto
+
+type TeamListQuery struct {
+	// part of the team name, case-insensitive
+	Search string `form:"search"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type TeamMemberQuery struct {
+	TeamName string `json:"team_name"`
+	model.TeamMember
+}
+
+type TeamMemberRemoveQuery struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type TeamMoveQuery struct {
+	UserID string `json:"user_id"`
+	// team to move the user to
+	TeamName string `json:"team_name"`
+	// keep or reassign, keep by default
+	Reviews            model.MoveReviewPolicy `json:"reviews,omitempty"`
+	ReevaluateAuthored bool                   `json:"reevaluate_authored,omitempty"`
+}
+
+type TeamMovesQuery struct {
+	UserID string `form:"user_id"`
+	// matches both the team left and the team joined
+	TeamName string `form:"team_name"`
+	Limit    int    `form:"limit"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type TeamParentQuery struct {
+	TeamName string `json:"team_name"`
+	// empty makes the team a top level team
+	ParentTeam string `json:"parent_team"`
+}
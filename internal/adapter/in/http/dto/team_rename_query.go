@@ -0,0 +1,6 @@
+package dto
+
+type TeamRenameQuery struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
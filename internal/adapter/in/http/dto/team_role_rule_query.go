@@ -0,0 +1,8 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type TeamRoleRuleQuery struct {
+	TeamName string `json:"team_name"`
+	model.RoleRule
+}
@@ -0,0 +1,6 @@
+package dto
+
+type TeamStatQuery struct {
+	TeamName string `form:"team_name"`
+	Rollup   bool   `form:"rollup"`
+}
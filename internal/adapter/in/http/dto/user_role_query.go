@@ -0,0 +1,8 @@
+package dto
+
+import "pr-assignment/internal/model"
+
+type UserRoleQuery struct {
+	UserID string         `json:"user_id"`
+	Role   model.UserRole `json:"role"`
+}
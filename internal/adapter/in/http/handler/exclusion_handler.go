@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExclusionHandler struct {
+	exclusionService *service.ExclusionService
+}
+
+func NewExclusionHandler(exclusionService *service.ExclusionService) *ExclusionHandler {
+	return &ExclusionHandler{exclusionService: exclusionService}
+}
+
+// AddExclusion godoc
+// @Summary      exclude reviewer from author's PRs
+// @Description  never assign reviewer_id to PRs of author_id, symmetric exclusion also works the other way
+// @Tags         exclusions
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.ExclusionQuery true "author id, reviewer id, symmetric, reason"
+// @Success      201  {object}  model.ReviewerExclusion
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /exclusions/add [post]
+func (h *ExclusionHandler) AddExclusion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.ExclusionQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	exclusion, err := h.exclusionService.AddExclusion(ctx, model.ReviewerExclusion{AuthorID: query.AuthorID,
+		ReviewerID: query.ReviewerID, Symmetric: query.Symmetric, Reason: query.Reason})
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, exclusion)
+}
+
+// RemoveExclusion godoc
+// @Summary      remove reviewer exclusion
+// @Tags         exclusions
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.ExclusionRemoveQuery true "author id, reviewer id"
+// @Success      204
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /exclusions/remove [post]
+func (h *ExclusionHandler) RemoveExclusion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.ExclusionRemoveQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	err := h.exclusionService.RemoveExclusion(ctx, query.AuthorID, query.ReviewerID)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetExclusions godoc
+// @Summary      get exclusions of user
+// @Description  get exclusions where the user is author or reviewer
+// @Tags         exclusions
+// @Accept       json
+// @Produce      json
+// @Param        user_id query string true "user id"
+// @Success      200  {object}  dto.ExclusionsResponse
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /exclusions/get [get]
+func (h *ExclusionHandler) GetExclusions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var userID dto.UserIDQuery
+	if err := c.BindQuery(&userID); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exclusions, err := h.exclusionService.GetExclusions(ctx, userID.UserID)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, dto.ExclusionsResponse{UserID: userID.UserID, Exclusions: exclusions})
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"pr-assignment/internal/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type exportFormat string
+
+const (
+	jsonFormat   exportFormat = "json"
+	csvFormat    exportFormat = "csv"
+	ndjsonFormat exportFormat = "ndjson"
+)
+
+const (
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+)
+
+// rows are flushed to the client in batches of this size
+const exportFlushRows = 100
+
+// negotiateFormat takes the format query parameter first and the Accept header otherwise.
+// Writes the error response and returns false when neither can be served
+func negotiateFormat(c *gin.Context) (exportFormat, bool) {
+	if format := c.Query("format"); format != "" {
+		switch exportFormat(format) {
+		case jsonFormat, csvFormat, ndjsonFormat:
+			return exportFormat(format), true
+		}
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(
+			model.NewError(model.BadRequest, "format must be json, csv or ndjson")))
+		return "", false
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeCSV, mimeNDJSON) {
+	case gin.MIMEJSON:
+		return jsonFormat, true
+	case mimeCSV:
+		return csvFormat, true
+	case mimeNDJSON:
+		return ndjsonFormat, true
+	}
+
+	c.IndentedJSON(http.StatusNotAcceptable, model.ParseErrorResponse(
+		model.NewError(model.BadRequest, "only %s, %s and %s are supported", gin.MIMEJSON, mimeCSV, mimeNDJSON)))
+	return "", false
+}
+
+// rowWriter writes rows to the response as they come. value is used by json formats,
+// record by csv
+type rowWriter interface {
+	WriteRow(value any, record []string) error
+	Close() error
+}
+
+func newRowWriter(c *gin.Context, format exportFormat, header []string) (rowWriter, error) {
+	switch format {
+	case csvFormat:
+		c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+		c.Status(http.StatusOK)
+		w := &csvRowWriter{c: c, w: csv.NewWriter(c.Writer)}
+		if err := w.w.Write(header); err != nil {
+			return nil, err
+		}
+		return w, nil
+	case ndjsonFormat:
+		c.Header("Content-Type", mimeNDJSON)
+		c.Status(http.StatusOK)
+		return &ndjsonRowWriter{c: c, enc: json.NewEncoder(c.Writer)}, nil
+	default:
+		c.Header("Content-Type", gin.MIMEJSON+"; charset=utf-8")
+		c.Status(http.StatusOK)
+		if _, err := c.Writer.WriteString("["); err != nil {
+			return nil, err
+		}
+		return &jsonRowWriter{c: c}, nil
+	}
+}
+
+type csvRowWriter struct {
+	c    *gin.Context
+	w    *csv.Writer
+	rows int
+}
+
+func (w *csvRowWriter) WriteRow(_ any, record []string) error {
+	if err := w.w.Write(csvSafeRecord(record)); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		w.w.Flush()
+		w.c.Writer.Flush()
+	}
+	return w.w.Error()
+}
+
+// csvSafeRecord prefixes cells that spreadsheets would run as formulas with a quote, a leading
+// tab or carriage return counts too. Numbers are left as they are, so negative values stay numeric
+func csvSafeRecord(record []string) []string {
+	safe := make([]string, len(record))
+	for i, cell := range record {
+		safe[i] = cell
+		if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			continue
+		}
+		safe[i] = "'" + cell
+	}
+	return safe
+}
+
+func (w *csvRowWriter) Close() error {
+	w.w.Flush()
+	w.c.Writer.Flush()
+	return w.w.Error()
+}
+
+type ndjsonRowWriter struct {
+	c    *gin.Context
+	enc  *json.Encoder
+	rows int
+}
+
+func (w *ndjsonRowWriter) WriteRow(value any, _ []string) error {
+	if err := w.enc.Encode(value); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		w.c.Writer.Flush()
+	}
+	return nil
+}
+
+func (w *ndjsonRowWriter) Close() error {
+	w.c.Writer.Flush()
+	return nil
+}
+
+// jsonRowWriter streams a plain json array
+type jsonRowWriter struct {
+	c    *gin.Context
+	rows int
+}
+
+func (w *jsonRowWriter) WriteRow(value any, _ []string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if w.rows > 0 {
+		if _, err = w.c.Writer.WriteString(","); err != nil {
+			return err
+		}
+	}
+	if _, err = w.c.Writer.Write(data); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		w.c.Writer.Flush()
+	}
+	return nil
+}
+
+func (w *jsonRowWriter) Close() error {
+	if _, err := w.c.Writer.WriteString("]"); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// streamRows writes rows in the requested format as stream passes them to its callback, write
+// turns one row into response rows. The writer is created on the first row so that errors
+// before it, validation included, still get a status code
+func streamRows[T any](c *gin.Context, format exportFormat, header []string,
+	stream func(fn func(T) error) error, write func(w rowWriter, row T) error) {
+	var w rowWriter
+	err := stream(func(row T) error {
+		if w == nil {
+			var err error
+			if w, err = newRowWriter(c, format, header); err != nil {
+				return err
+			}
+		}
+		return write(w, row)
+	})
+
+	if err != nil && w == nil {
+		errResp := model.ParseErrorResponse(err)
+		c.IndentedJSON(statErrorStatus(errResp.Error.Code), errResp)
+		return
+	}
+	if err != nil {
+		// the response has already started, the client gets a truncated body
+		_ = c.Error(err)
+		return
+	}
+
+	if w == nil {
+		if w, err = newRowWriter(c, format, header); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	if err = w.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// writeRecord writes a row as it is, json formats get the row and csv gets record(row)
+func writeRecord[T any](record func(T) []string) func(w rowWriter, row T) error {
+	return func(w rowWriter, row T) error {
+		return w.WriteRow(row, record(row))
+	}
+}
+
+// exportRows writes already loaded rows as csv or ndjson
+func exportRows[T any](c *gin.Context, format exportFormat, header []string, rows []T, record func(T) []string) {
+	w, err := newRowWriter(c, format, header)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	for _, row := range rows {
+		if err = w.WriteRow(row, record(row)); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func formatInt(n int) string {
+	return strconv.Itoa(n)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatOptFloat leaves the cell empty for nil
+func formatOptFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return formatFloat(*f)
+}
+
+// formatTime leaves the cell empty for zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatList joins list values into one cell
+func formatList(values []string) string {
+	return strings.Join(values, ";")
+}
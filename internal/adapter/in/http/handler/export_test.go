@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSVSafeRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record []string
+		want   []string
+	}{
+		{name: "plain cells", record: []string{"pr-1", "Add search", "", "u1"},
+			want: []string{"pr-1", "Add search", "", "u1"}},
+		{name: "formulas", record: []string{"=HYPERLINK(\"x\")", "+1+cmd|' /C calc'!A0", "-2+3", "@SUM(A1)"},
+			want: []string{"'=HYPERLINK(\"x\")", "'+1+cmd|' /C calc'!A0", "'-2+3", "'@SUM(A1)"}},
+		{name: "tab and carriage return", record: []string{"\t=1+2", "\r@SUM(A1)"},
+			want: []string{"'\t=1+2", "'\r@SUM(A1)"}},
+		{name: "numbers stay numeric", record: []string{"-1.5", "+3", "42"}, want: []string{"-1.5", "+3", "42"}},
+		{name: "marker in the middle", record: []string{"a=b", "x-y"}, want: []string{"a=b", "x-y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvSafeRecord(tt.record); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("record = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamRows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	header := []string{"user_id", "count"}
+	record := func(count model.UserDeclinesCount) []string {
+		return []string{count.User.UserID, formatInt(count.DeclinesCount)}
+	}
+	rows := func(ids ...string) func(fn func(model.UserDeclinesCount) error) error {
+		return func(fn func(model.UserDeclinesCount) error) error {
+			for _, id := range ids {
+				if err := fn(model.UserDeclinesCount{User: model.User{UserID: id}, DeclinesCount: 1}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		format     exportFormat
+		stream     func(fn func(model.UserDeclinesCount) error) error
+		wantStatus int
+		wantBody   string
+		wantError  bool
+	}{
+		{name: "csv rows", format: csvFormat, stream: rows("u1", "u2"), wantStatus: http.StatusOK,
+			wantBody: "user_id,count\nu1,1\nu2,1\n"},
+		{name: "no rows still get the header", format: csvFormat, stream: rows(), wantStatus: http.StatusOK,
+			wantBody: "user_id,count\n"},
+		{name: "json array", format: jsonFormat, stream: rows("u1"), wantStatus: http.StatusOK,
+			wantBody: `[{"user":{"user_id":"u1","username":"","team_name":"","is_active":false,"role":""},"declines_count":1}]`},
+		{name: "error before the first row gets a status", format: csvFormat,
+			stream: func(fn func(model.UserDeclinesCount) error) error {
+				return model.NewError(model.NotFound, "team not found")
+			}, wantStatus: http.StatusNotFound},
+		{name: "error after the first row is only logged", format: csvFormat,
+			stream: func(fn func(model.UserDeclinesCount) error) error {
+				if err := rows("u1")(fn); err != nil {
+					return err
+				}
+				return errors.New("connection lost")
+			}, wantStatus: http.StatusOK, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			streamRows(c, tt.format, header, tt.stream, writeRecord(record))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+			if (len(c.Errors) > 0) != tt.wantError {
+				t.Errorf("errors = %v, want error %v", c.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestWriteReviewLoad(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	load := model.UserReviewLoad{User: model.User{UserID: "u1", Username: "Alice", TeamName: "backend",
+		IsActive: true, Role: model.MEMBER}, Total: 2,
+		Points: []model.ReviewLoadPoint{{Start: start, Count: 2}, {Start: start.AddDate(0, 0, 1)}}}
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	streamRows(c, csvFormat, reviewLoadHeader, func(fn func(model.UserReviewLoad) error) error {
+		return fn(load)
+	}, writeReviewLoad(csvFormat))
+
+	want := "user_id,username,team_name,is_active,role,start,count\n" +
+		"u1,Alice,backend,true,member,2025-03-10T00:00:00Z,2\n" +
+		"u1,Alice,backend,true,member,2025-03-11T00:00:00Z,0\n"
+	if got := recorder.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
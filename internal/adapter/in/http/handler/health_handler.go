@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"pr-assignment/internal/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyTimeout bounds the readiness check so a hanging database fails the probe instead of the kubelet timeout
+const readyTimeout = 2 * time.Second
+
+// Readiness reports whether the service can serve traffic
+type Readiness interface {
+	Ready(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	readiness Readiness
+}
+
+func NewHealthHandler(readiness Readiness) *HealthHandler {
+	return &HealthHandler{readiness: readiness}
+}
+
+// Healthz godoc
+// @Summary      liveness probe
+// @Description  ok while the process serves http, does not touch the database
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Router       /healthz [get]
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz godoc
+// @Summary      readiness probe
+// @Description  pings the database and checks that migrations are applied up to the version of the binary
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      503  {object}  model.ErrorResponse
+// @Router       /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	if err := h.readiness.Ready(ctx); err != nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, model.ParseErrorResponse(model.NewError(model.NotReady, "%s", err)))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"pr-assignment/internal/adapter/in/http/dto"
 	"pr-assignment/internal/model"
@@ -24,7 +23,8 @@ func NewPullRequestHandler(prService *service.PullRequestService) *PullRequestHa
 
 // CreatePullRequest godoc
 // @Summary      Create new Pull Request
-// @Description  create new pr and assign reviewers automatically
+// @Description  create new pr and assign reviewers automatically from team_name, one of the author's teams,
+// @Description  the primary team when empty
 // @Tags         pull requests
 // @Accept       json
 // @Produce      json
@@ -50,17 +50,23 @@ func (h *PullRequestHandler) CreatePullRequest(c *gin.Context) {
 		if errResp.Error.Code == model.NotFound {
 			statusCode = http.StatusNotFound
 		}
+		if errResp.Error.Code == model.BadRequest {
+			statusCode = http.StatusBadRequest
+		}
 		if errResp.Error.Code == model.PrExists {
 			statusCode = http.StatusConflict
 		}
+		_ = c.Error(err)
 		c.IndentedJSON(statusCode, errResp)
-		fmt.Println(err)
 		return
 	}
 
 	newPr := dto.PrResponse{
-		PullRequestShort:  pr.PullRequestShort,
-		AssignedReviewers: pr.AssignedReviewers,
+		PullRequestShort:   pr.PullRequestShort,
+		TeamName:           pr.TeamName,
+		AssignedReviewers:  pr.AssignedReviewers,
+		FallbackReviewers:  pr.FallbackReviewers,
+		ExcludedCandidates: pr.ExcludedCandidates,
 	}
 
 	c.IndentedJSON(http.StatusCreated, newPr)
@@ -96,7 +102,8 @@ func (h *PullRequestHandler) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	prResponse := dto.PrResponse{PullRequestShort: pr.PullRequestShort, AssignedReviewers: pr.AssignedReviewers}
+	prResponse := dto.PrResponse{PullRequestShort: pr.PullRequestShort, TeamName: pr.TeamName,
+		AssignedReviewers: pr.AssignedReviewers, FallbackReviewers: pr.FallbackReviewers}
 	updatedPr := dto.PrMergedResponse{PrMerged: dto.PrMerged{PrResponse: prResponse, MergedAt: pr.MergedAt}}
 
 	c.IndentedJSON(http.StatusOK, updatedPr)
@@ -121,7 +128,8 @@ func (h *PullRequestHandler) ReassignPullRequest(c *gin.Context) {
 		return
 	}
 
-	result, err := h.prService.ChangeReviewer(ctx, query.PullRequestID, query.OldReviewerID)
+	result, err := h.prService.ChangeReviewer(ctx, query.PullRequestID, query.OldReviewerID,
+		model.ManualReassign)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errResp := model.ParseErrorResponse(err)
@@ -138,9 +146,217 @@ func (h *PullRequestHandler) ReassignPullRequest(c *gin.Context) {
 	}
 
 	prResponse := dto.PrResponse{PullRequestShort: result.PullRequest.PullRequestShort,
-		AssignedReviewers: result.PullRequest.AssignedReviewers}
+		TeamName:           result.PullRequest.TeamName,
+		AssignedReviewers:  result.PullRequest.AssignedReviewers,
+		FallbackReviewers:  result.PullRequest.FallbackReviewers,
+		ExcludedCandidates: result.PullRequest.ExcludedCandidates}
 
 	prReassignResponse := dto.PrReassignResponse{PrResponse: prResponse, ReplacedBy: result.NewReviewerID}
 
 	c.IndentedJSON(http.StatusOK, prReassignResponse)
 }
+
+// AcceptReview godoc
+// @Summary      Accept review assignment
+// @Description  only an assignment that was neither accepted nor reviewed can be accepted, otherwise 409
+// @Tags         pull requests
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.AcceptReviewQuery true "Pr id, reviewer id"
+// @Success      200  {object}  model.ReviewAssignment
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /pullRequest/accept [post]
+func (h *PullRequestHandler) AcceptReview(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query dto.AcceptReviewQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	assignment, err := h.prService.AcceptReview(ctx, query.PullRequestID, query.ReviewerID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errResp := model.ParseErrorResponse(err)
+
+		if errResp.Error.Code == model.NotFound {
+			statusCode = http.StatusNotFound
+		}
+		if errResp.Error.Code == model.PrMerged || errResp.Error.Code == model.NotAssigned ||
+			errResp.Error.Code == model.WrongState {
+			statusCode = http.StatusConflict
+		}
+
+		c.IndentedJSON(statusCode, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, assignment)
+}
+
+// DeclineReview godoc
+// @Summary      Decline review assignment
+// @Description  decline the assignment, a substitute reviewer is assigned automatically. The decline is
+// @Description  counted even when no one can replace the reviewer, who then stays on the PR and gets 409.
+// @Description  Only an assigned or accepted review can be declined, a reviewed one is 409 WRONG_REVIEW_STATE
+// @Tags         pull requests
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.DeclineReviewQuery true "Pr id, reviewer id, optional reason"
+// @Success      200  {object}  dto.PrReassignResponse
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /pullRequest/decline [post]
+func (h *PullRequestHandler) DeclineReview(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query dto.DeclineReviewQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	result, err := h.prService.DeclineReview(ctx, query.PullRequestID, query.ReviewerID, query.Reason)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errResp := model.ParseErrorResponse(err)
+
+		if errResp.Error.Code == model.NotFound {
+			statusCode = http.StatusNotFound
+		}
+		if errResp.Error.Code == model.PrMerged || errResp.Error.Code == model.NotAssigned ||
+			errResp.Error.Code == model.NoCandidate {
+			statusCode = http.StatusConflict
+		}
+
+		c.IndentedJSON(statusCode, errResp)
+		return
+	}
+
+	prResponse := dto.PrResponse{PullRequestShort: result.PullRequest.PullRequestShort,
+		TeamName:           result.PullRequest.TeamName,
+		AssignedReviewers:  result.PullRequest.AssignedReviewers,
+		FallbackReviewers:  result.PullRequest.FallbackReviewers,
+		ExcludedCandidates: result.PullRequest.ExcludedCandidates}
+
+	c.IndentedJSON(http.StatusOK, dto.PrReassignResponse{PrResponse: prResponse, ReplacedBy: result.NewReviewerID})
+}
+
+// SubmitReview godoc
+// @Summary      Submit review decision
+// @Tags         pull requests
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.ReviewDecisionQuery true "Pr id, reviewer id, decision: approved, changes_requested or commented"
+// @Success      201  {object}  model.ReviewDecision
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /pullRequest/review [post]
+func (h *PullRequestHandler) SubmitReview(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query dto.ReviewDecisionQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	decision, err := h.prService.SubmitReview(ctx, query.PullRequestID, query.ReviewerID, query.Decision)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errResp := model.ParseErrorResponse(err)
+
+		if errResp.Error.Code == model.BadRequest {
+			statusCode = http.StatusBadRequest
+		}
+		if errResp.Error.Code == model.NotFound {
+			statusCode = http.StatusNotFound
+		}
+		if errResp.Error.Code == model.PrMerged || errResp.Error.Code == model.NotAssigned ||
+			errResp.Error.Code == model.WrongState {
+			statusCode = http.StatusConflict
+		}
+
+		c.IndentedJSON(statusCode, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, decision)
+}
+
+// ListPullRequests godoc
+// @Summary      List pull requests
+// @Description  list pull requests with current reviewers, oldest first. Rows are streamed, so large exports do not need to fit in memory
+// @Tags         pull requests
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        team_name query string false "only PRs of this team"
+// @Param        author_id query string false "author id"
+// @Param        status query string false "created or merged"
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Success      200  {array}   model.PullRequest
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      406  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /pullRequest/list [get]
+func (h *PullRequestHandler) ListPullRequests(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PullRequestListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	// the writer is created on the first row so that errors before it still get a status code
+	var w rowWriter
+	err := h.prService.StreamPRs(ctx, query, func(pr *model.PullRequest) error {
+		if w == nil {
+			var err error
+			if w, err = newRowWriter(c, format, pullRequestHeader); err != nil {
+				return err
+			}
+		}
+		return w.WriteRow(pr, pullRequestRecord(*pr))
+	})
+
+	if err != nil && w == nil {
+		statusCode := http.StatusInternalServerError
+		errResp := model.ParseErrorResponse(err)
+
+		if errResp.Error.Code == model.BadRequest {
+			statusCode = http.StatusBadRequest
+		}
+		if errResp.Error.Code == model.NotFound {
+			statusCode = http.StatusNotFound
+		}
+
+		c.IndentedJSON(statusCode, errResp)
+		return
+	}
+	if err != nil {
+		// the response has already started, the client gets a truncated body
+		_ = c.Error(err)
+		return
+	}
+
+	if w == nil {
+		if w, err = newRowWriter(c, format, pullRequestHeader); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	if err = w.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRosterSize limits the request body of an import
+const maxRosterSize = 10 << 20
+
+type RosterHandler struct {
+	rosterService *service.RosterService
+	// nil when no directory source is configured
+	directorySyncService *service.DirectorySyncService
+}
+
+func NewRosterHandler(rosterService *service.RosterService,
+	directorySyncService *service.DirectorySyncService) *RosterHandler {
+	return &RosterHandler{rosterService: rosterService, directorySyncService: directorySyncService}
+}
+
+// ImportRoster godoc
+// @Summary      bulk import teams and members
+// @Description  yaml roster (teams: [{team_name, members: [{user_id, username, is_active, role}]}]) or csv with
+// @Description  team_name, user_id, username and optional is_active, role columns. The whole roster is validated
+// @Description  before anything is written. Missing teams are created, users are created or updated.
+// @Description  With reconcile active users of the roster teams missing from the roster are deactivated
+// @Tags         teams
+// @Accept       plain
+// @Produce      json
+// @Param        roster body string true "roster file"
+// @Param        format query string false "yaml or csv, taken from Content-Type when empty"
+// @Param        dry_run query bool false "only report the diff"
+// @Param        reconcile query bool false "deactivate users of the roster teams missing from the roster"
+// @Success      200  {object}  model.RosterImportResult
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/import [post]
+func (h *RosterHandler) ImportRoster(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.RosterImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	format := query.Format
+	if format == "" {
+		format = "yaml"
+		if strings.Contains(c.ContentType(), "csv") {
+			format = "csv"
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxRosterSize)
+
+	var entries []model.RosterEntry
+	var err error
+	switch format {
+	case "yaml":
+		entries, err = service.ParseRosterYAML(body)
+	case "csv":
+		entries, err = service.ParseRosterCSV(body)
+	default:
+		err = model.NewError(model.BadRequest, "format must be yaml or csv")
+	}
+
+	if err == nil {
+		var result *model.RosterImportResult
+		result, err = h.rosterService.Import(ctx, entries, query.Reconcile, nil, query.DryRun)
+		if err == nil {
+			c.IndentedJSON(http.StatusOK, result)
+			return
+		}
+	}
+
+	h.writeImportError(c, err)
+}
+
+// SyncDirectory godoc
+// @Summary      sync teams with the directory now
+// @Description  reads groups from the configured directory source and imports them with reconcile, the same
+// @Description  as the periodic job. Users of teams the directory manages who are missing from it are deactivated
+// @Description  and their reviews reassigned, other teams are left alone
+// @Tags         teams
+// @Produce      json
+// @Param        dry_run query bool false "only report the diff"
+// @Success      200  {object}  model.RosterImportResult
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/sync [post]
+func (h *RosterHandler) SyncDirectory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.directorySyncService == nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest,
+			"directory source is not configured")))
+		return
+	}
+
+	var query dto.DirectorySyncQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	result, err := h.directorySyncService.Run(ctx, query.DryRun)
+	if err != nil {
+		h.writeImportError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+func (h *RosterHandler) writeImportError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	errResp := model.ParseErrorResponse(err)
+	if errResp.Error.Code == model.BadRequest || errResp.Error.Code == model.NotFound {
+		c.IndentedJSON(http.StatusBadRequest, errResp)
+		return
+	}
+	c.IndentedJSON(http.StatusInternalServerError, errResp)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SlaHandler struct {
+	slaService *service.SlaService
+}
+
+func NewSlaHandler(slaService *service.SlaService) *SlaHandler {
+	return &SlaHandler{slaService: slaService}
+}
+
+// SetTeamSla godoc
+// @Summary      set team review SLA
+// @Description  set business hours reviewers have to act and what to do on breach: add_lead or reassign
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamSlaQuery true "team_name, first_review_hours, policy"
+// @Success      200  {object}  model.TeamSla
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/setSla [post]
+func (h *SlaHandler) SetTeamSla(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamSlaQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sla, err := h.slaService.SetTeamSla(ctx, query.TeamName, query.TeamSla)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, sla)
+}
+
+// GetSlaReport godoc
+// @Summary      get SLA breaches
+// @Description  get number of SLA breaches per team and per reviewer
+// @Tags         statistics
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Success      200  {object}  model.SlaReport
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /stat/sla [get]
+func (h *SlaHandler) GetSlaReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.slaService.GetSlaReport(ctx)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, model.ParseErrorResponse(err))
+		return
+	}
+
+	if format == jsonFormat {
+		c.IndentedJSON(http.StatusOK, report)
+		return
+	}
+
+	exportRows(c, format, slaHeader, slaRows(report), slaRecord)
+}
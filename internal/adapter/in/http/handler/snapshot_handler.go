@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSnapshotSize limits the request body of a snapshot import
+const maxSnapshotSize = 512 << 20
+
+type SnapshotHandler struct {
+	snapshotService *service.SnapshotService
+}
+
+func NewSnapshotHandler(snapshotService *service.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{snapshotService: snapshotService}
+}
+
+// ExportSnapshot godoc
+// @Summary      export the whole dataset
+// @Description  teams with fallbacks, role rules and SLA, users, exclusions, PRs with reviewers and the
+// @Description  assignment, decline, decision and escalation history as a versioned JSON archive. Tables are
+// @Description  read in one transaction, so the archive is consistent. Served only with FEATURE_SNAPSHOTS
+// @Tags         snapshot
+// @Produce      json
+// @Success      200  {object}  model.Snapshot
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /snapshot/export [get]
+func (h *SnapshotHandler) ExportSnapshot(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	snapshot, err := h.snapshotService.Export(ctx)
+	if err != nil {
+		_ = c.Error(err)
+		c.IndentedJSON(http.StatusInternalServerError, model.ParseErrorResponse(err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=snapshot-%s.json",
+		snapshot.CreatedAt.Format("20060102-150405")))
+	// archives get large, indentation is left to the reader
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// ImportSnapshot godoc
+// @Summary      import an exported archive
+// @Description  replace wipes all data and loads the archive. merge adds and updates rows and never deletes,
+// @Description  teams are matched by name. The archive is validated first and written in one transaction,
+// @Description  importing the same archive again writes nothing. Served only with FEATURE_SNAPSHOTS
+// @Tags         snapshot
+// @Accept       json
+// @Produce      json
+// @Param        snapshot body model.Snapshot true "archive from /snapshot/export"
+// @Param        mode query string true "replace or merge"
+// @Param        dry_run query bool false "only count the rows that would be written"
+// @Success      200  {object}  model.SnapshotImportResult
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /snapshot/import [post]
+func (h *SnapshotHandler) ImportSnapshot(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.SnapshotImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotSize)
+
+	var snapshot model.Snapshot
+	if err := c.ShouldBindJSON(&snapshot); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest,
+			"invalid snapshot: %s", err)))
+		return
+	}
+
+	result, err := h.snapshotService.Import(ctx, &snapshot, query.Mode, query.DryRun)
+	if err != nil {
+		_ = c.Error(err)
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, result)
+}
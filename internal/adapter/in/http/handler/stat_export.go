@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"pr-assignment/internal/model"
+	"strconv"
+	"time"
+)
+
+// csv layouts of the stat endpoints. Reports with several sections are flattened
+// into rows with a scope column
+
+var userColumns = []string{"user_id", "username", "team_name", "is_active", "role"}
+
+func userRecord(user model.User) []string {
+	return []string{user.UserID, user.Username, user.TeamName, strconv.FormatBool(user.IsActive), string(user.Role)}
+}
+
+var prReviewersHeader = []string{"pull_request_id", "pull_request_name", "author_id", "status", "reviewers_count"}
+
+func prReviewersRecord(count model.PrReviewersCount) []string {
+	pr := count.PullRequest
+	return []string{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, string(pr.Status), formatInt(count.Count)}
+}
+
+var userReviewsHeader = append(append([]string{}, userColumns...), "reviews_count")
+
+func userReviewsRecord(count model.UserReviewsCount) []string {
+	return append(userRecord(count.User), formatInt(count.ReviewsCount))
+}
+
+var userDeclinesHeader = append(append([]string{}, userColumns...), "declines_count")
+
+func userDeclinesRecord(count model.UserDeclinesCount) []string {
+	return append(userRecord(count.User), formatInt(count.DeclinesCount))
+}
+
+type reviewLoadRow struct {
+	User  model.User `json:"user"`
+	Start time.Time  `json:"start"`
+	Count int        `json:"count"`
+}
+
+var reviewLoadHeader = append(append([]string{}, userColumns...), "start", "count")
+
+// writeReviewLoad writes the series as one json value, csv and ndjson get a row per point
+func writeReviewLoad(format exportFormat) func(w rowWriter, load model.UserReviewLoad) error {
+	return func(w rowWriter, load model.UserReviewLoad) error {
+		if format == jsonFormat {
+			return w.WriteRow(load, nil)
+		}
+		for _, point := range load.Points {
+			row := reviewLoadRow{User: load.User, Start: point.Start, Count: point.Count}
+			if err := w.WriteRow(row, reviewLoadRecord(row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func reviewLoadRecord(row reviewLoadRow) []string {
+	return append(userRecord(row.User), formatTime(row.Start), formatInt(row.Count))
+}
+
+var pairingHeader = []string{"author_id", "reviewer_id", "count"}
+
+func pairingRecord(pairing model.PairingCount) []string {
+	return []string{pairing.AuthorID, pairing.ReviewerID, formatInt(pairing.Count)}
+}
+
+type flowRow struct {
+	Scope string `json:"scope"`
+	Name  string `json:"name"`
+	model.FlowMetrics
+}
+
+var flowHeader = []string{"scope", "name", "pull_requests",
+	"time_to_first_review_median_seconds", "time_to_first_review_p90_seconds",
+	"time_to_approval_median_seconds", "time_to_approval_p90_seconds",
+	"time_to_merge_median_seconds", "time_to_merge_p90_seconds"}
+
+func flowRows(report *model.FlowReport) []flowRow {
+	rows := make([]flowRow, 0, len(report.Teams)+len(report.Users))
+	for _, team := range report.Teams {
+		rows = append(rows, flowRow{Scope: "team", Name: team.TeamName, FlowMetrics: team.FlowMetrics})
+	}
+	for _, user := range report.Users {
+		rows = append(rows, flowRow{Scope: "user", Name: user.UserID, FlowMetrics: user.FlowMetrics})
+	}
+	return rows
+}
+
+func flowRecord(row flowRow) []string {
+	return []string{row.Scope, row.Name, formatInt(row.PullRequests),
+		formatOptFloat(row.TimeToFirstReview.Median), formatOptFloat(row.TimeToFirstReview.P90),
+		formatOptFloat(row.TimeToApproval.Median), formatOptFloat(row.TimeToApproval.P90),
+		formatOptFloat(row.TimeToMerge.Median), formatOptFloat(row.TimeToMerge.P90)}
+}
+
+type slaRow struct {
+	Scope    string `json:"scope"`
+	Name     string `json:"name"`
+	Breaches int    `json:"breaches"`
+}
+
+var slaHeader = []string{"scope", "name", "breaches"}
+
+func slaRows(report *model.SlaReport) []slaRow {
+	rows := make([]slaRow, 0, len(report.Teams)+len(report.Reviewers))
+	for _, team := range report.Teams {
+		rows = append(rows, slaRow{Scope: "team", Name: team.TeamName, Breaches: team.Breaches})
+	}
+	for _, reviewer := range report.Reviewers {
+		rows = append(rows, slaRow{Scope: "reviewer", Name: reviewer.ReviewerID, Breaches: reviewer.Breaches})
+	}
+	return rows
+}
+
+func slaRecord(row slaRow) []string {
+	return []string{row.Scope, row.Name, formatInt(row.Breaches)}
+}
+
+var teamStatsHeader = []string{"team_name", "open_prs", "merged_prs", "avg_reviewers", "active_members",
+	"inactive_members", "required_reviewers", "under_reviewed_prs", "under_reviewed_share", "parent_team"}
+
+func teamStatsRecord(stats model.TeamStats) []string {
+	return []string{stats.TeamName, formatInt(stats.OpenPRs), formatInt(stats.MergedPRs),
+		formatFloat(stats.AvgReviewers), formatInt(stats.ActiveMembers), formatInt(stats.InactiveMembers),
+		formatInt(stats.RequiredReviewers), formatInt(stats.UnderReviewedPRs), formatFloat(stats.UnderReviewedShare),
+		stats.ParentTeam}
+}
+
+var pullRequestHeader = []string{"pull_request_id", "pull_request_name", "author_id", "status",
+	"created_at", "merged_at", "assigned_reviewers", "team_name"}
+
+func pullRequestRecord(pr model.PullRequest) []string {
+	return []string{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, string(pr.Status),
+		formatTime(pr.CreatedAt), formatTime(pr.MergedAt), formatList(pr.AssignedReviewers), pr.TeamName}
+}
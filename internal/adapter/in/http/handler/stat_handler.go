@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
 	"pr-assignment/internal/model"
 	"pr-assignment/internal/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,12 +18,25 @@ func NewStatHandler(statService *service.StatService) *StatHandler {
 	return &StatHandler{statService: statService}
 }
 
+func statErrorStatus(code model.ErrCode) int {
+	switch code {
+	case model.BadRequest:
+		return http.StatusBadRequest
+	case model.NotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // GetReviewersCountedByPR godoc
 // @Summary      get prs with assigned reviewers
-// @Description  get pull requests with reviewers and their number
+// @Description  get pull requests with reviewers and their number. Rows are streamed
 // @Tags         statistics
 // @Accept       json
-// @Produce      json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Param        team_name query string false "only PRs of this team"
 // @Success      200  {object}  model.PrReviewersCount
 // @Failure      400  {object}  model.ErrorResponse
 // @Failure      404  {object}  model.ErrorResponse
@@ -30,20 +45,35 @@ func NewStatHandler(statService *service.StatService) *StatHandler {
 func (h *StatHandler) GetReviewersCountedByPR(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	prReviewers, err := h.statService.GetReviewsCountedByPR(ctx)
-	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, model.ParseErrorResponse(err))
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
 	}
 
-	c.IndentedJSON(http.StatusOK, prReviewers)
+	var query dto.TeamStatQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	streamRows(c, format, prReviewersHeader, func(fn func(model.PrReviewersCount) error) error {
+		return h.statService.StreamReviewsCountedByPR(ctx, query.TeamName, fn)
+	}, writeRecord(prReviewersRecord))
 }
 
 // GetReviewsCountedByUser godoc
 // @Summary      get users and number of reviews
-// @Description  get users and in how many pull requests they are reviewers
+// @Description  get users and in how many pull requests they are reviewers.
+// @Description  With from, to or bucket returns model.UserReviewLoad series instead: assignments per user in every day, week or month of the range, reassigned reviews included. Range defaults to the last 30 days, bucket to day.
+// @Description  Rows are streamed, a series is written as soon as the user is complete
 // @Tags         statistics
 // @Accept       json
-// @Produce      json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Param        from query string false "from date, 2006-01-02"
+// @Param        to query string false "to date exclusive, 2006-01-02"
+// @Param        bucket query string false "day, week or month"
+// @Param        team_name query string false "only members of this team"
 // @Success      200  {object}  model.UserReviewsCount
 // @Failure      400  {object}  model.ErrorResponse
 // @Failure      404  {object}  model.ErrorResponse
@@ -52,10 +82,188 @@ func (h *StatHandler) GetReviewersCountedByPR(c *gin.Context) {
 func (h *StatHandler) GetReviewsCountedByUser(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	userPrs, err := h.statService.GetReviewsCountedByUser(ctx)
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ReviewLoadQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	if query.From.IsZero() && query.To.IsZero() && query.Bucket == "" {
+		streamRows(c, format, userReviewsHeader, func(fn func(model.UserReviewsCount) error) error {
+			return h.statService.StreamReviewsCountedByUser(ctx, query.TeamName, fn)
+		}, writeRecord(userReviewsRecord))
+		return
+	}
+
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -30)
+	}
+	if query.Bucket == "" {
+		query.Bucket = model.DAY
+	}
+
+	streamRows(c, format, reviewLoadHeader, func(fn func(model.UserReviewLoad) error) error {
+		return h.statService.StreamReviewLoad(ctx, query.From, query.To, query.Bucket, query.TeamName, fn)
+	}, writeReviewLoad(format))
+}
+
+// GetPairingMatrix godoc
+// @Summary      get author and reviewer pairings
+// @Description  get matrix of how many times each reviewer was assigned to each author, rows are authors.
+// @Description  csv and ndjson stream one row per pair assigned at least once instead
+// @Tags         statistics
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Success      200  {object}  model.PairingMatrix
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /stat/pairings [get]
+func (h *StatHandler) GetPairingMatrix(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	// the matrix needs every pair before the first row, csv and ndjson rows are streamed
+	if format != jsonFormat {
+		streamRows(c, format, pairingHeader, func(fn func(model.PairingCount) error) error {
+			return h.statService.StreamPairingCounts(ctx, fn)
+		}, writeRecord(pairingRecord))
+		return
+	}
+
+	matrix, err := h.statService.GetPairingMatrix(ctx)
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, model.ParseErrorResponse(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, matrix)
+}
+
+// GetDeclinesCountedByUser godoc
+// @Summary      get users and number of declined reviews
+// @Description  get users who declined review assignments and how many times. Rows are streamed
+// @Tags         statistics
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Success      200  {array}  model.UserDeclinesCount
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /stat/users/declines [get]
+func (h *StatHandler) GetDeclinesCountedByUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	streamRows(c, format, userDeclinesHeader, func(fn func(model.UserDeclinesCount) error) error {
+		return h.statService.StreamDeclinesCountedByUser(ctx, fn)
+	}, writeRecord(userDeclinesRecord))
+}
+
+// GetFlowReport godoc
+// @Summary      get review flow metrics
+// @Description  get median and p90 time to first review, approval and merge per team and per author for PRs created in the range. Defaults to the last 30 days
+// @Tags         statistics
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Param        from query string false "from date, 2006-01-02"
+// @Param        to query string false "to date exclusive, 2006-01-02"
+// @Param        team_name query string false "team name"
+// @Success      200  {object}  model.FlowReport
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /stat/flow [get]
+func (h *StatHandler) GetFlowReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	var query dto.FlowQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -30)
+	}
+
+	report, err := h.statService.GetFlowReport(ctx, query.From, query.To, query.TeamName)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		c.IndentedJSON(statErrorStatus(errResp.Error.Code), errResp)
+		return
+	}
+
+	if format == jsonFormat {
+		c.IndentedJSON(http.StatusOK, report)
+		return
+	}
+
+	exportRows(c, format, flowHeader, flowRows(report), flowRecord)
+}
+
+// GetTeamStats godoc
+// @Summary      get team aggregates
+// @Description  get open and merged PRs, average reviewers per PR, active and inactive members and share of PRs with fewer reviewers than the team requires, PRs belong to the team they were created for.
+// @Description  With rollup a team also counts PRs and members of all its sub-teams. Members are primary and secondary ones, a user in several teams of the subtree is counted once
+// @Tags         statistics
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Param        format query string false "json, csv or ndjson, overrides the Accept header"
+// @Param        team_name query string false "team name, all teams when empty"
+// @Param        rollup query bool false "include sub-teams"
+// @Success      200  {array}   model.TeamStats
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /stat/teams [get]
+func (h *StatHandler) GetTeamStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	var query dto.TeamStatQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(err))
+		return
+	}
+
+	stats, err := h.statService.GetTeamStats(ctx, query.TeamName, query.Rollup)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		c.IndentedJSON(statErrorStatus(errResp.Error.Code), errResp)
+		return
+	}
+
+	if format == jsonFormat {
+		c.IndentedJSON(http.StatusOK, stats)
+		return
 	}
 
-	c.IndentedJSON(http.StatusOK, userPrs)
+	exportRows(c, format, teamStatsHeader, stats, teamStatsRecord)
 }
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"net/http"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamHandler struct {
+	teamService *service.TeamService
+}
+
+func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
+	return &TeamHandler{teamService: teamService}
+}
+
+// ListTeams godoc
+// @Summary      list teams
+// @Description  teams ordered by name with member counts (primary and secondary members), total is the number of matching teams
+// @Tags         teams
+// @Produce      json
+// @Param        search query string false "part of the team name, case-insensitive"
+// @Param        limit query int false "page size, 50 by default, at most 500"
+// @Param        offset query int false "teams to skip"
+// @Success      200  {object}  model.TeamList
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /teams [get]
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	teams, err := h.teamService.ListTeams(ctx, query.Search, query.Limit, query.Offset)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, teams)
+}
+
+// RenameTeam godoc
+// @Summary      rename team
+// @Description  members, fallbacks, role rules and SLA stay with the team
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamRenameQuery true "team_name, new_team_name"
+// @Success      200  {object}  model.Team
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/rename [post]
+func (h *TeamHandler) RenameTeam(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamRenameQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	team, err := h.teamService.RenameTeam(ctx, query.TeamName, query.NewTeamName)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// SetParentTeam godoc
+// @Summary      set parent team
+// @Description  puts the team under the parent, an empty parent_team makes it a top level team. Stats roll up
+// @Description  to parents and reviewer selection falls back to sibling and parent teams. A sub-team of the team
+// @Description  can not become its parent
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamParentQuery true "team_name, parent_team"
+// @Success      200  {object}  model.Team
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/setParent [post]
+func (h *TeamHandler) SetParentTeam(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamParentQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	team, err := h.teamService.SetParentTeam(ctx, query.TeamName, query.ParentTeam)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// DeleteTeam godoc
+// @Summary      delete team
+// @Description  members, active or not, move to move_members_to, required unless the team is empty.
+// @Description  open_prs decides about open PRs filed in the team: block (default) refuses with 409, keep moves
+// @Description  them to the new team, reassign also picks pending reviewers again from the new team.
+// @Description  Sub-teams move up to the parent of the team
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamDeleteQuery true "team_name, move_members_to, open_prs"
+// @Success      200  {object}  model.TeamDeletion
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/delete [post]
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamDeleteQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	result, err := h.teamService.DeleteTeam(ctx, query.TeamName, query.MoveMembersTo, query.OpenPRs)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// AddMember godoc
+// @Summary      add member to team
+// @Description  creates the user in the team, repeating the call is fine. A user of another team keeps
+// @Description  the primary team and gets this one as a secondary team
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamMemberQuery true "team_name, user_id, username, is_active, role"
+// @Success      200  {object}  model.Team
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/addMember [post]
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamMemberQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	team, err := h.teamService.AddMember(ctx, query.TeamName, query.TeamMember)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// RemoveMember godoc
+// @Summary      remove member from team
+// @Description  for a secondary team only the membership goes, reviews of the team's PRs are reassigned.
+// @Description  From the primary team a user without PRs and reviews is deleted, others are kept for the
+// @Description  history, deactivated, and their reviews are reassigned. A user with secondary teams has to
+// @Description  be moved instead
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamMemberRemoveQuery true "team_name, user_id"
+// @Success      200  {object}  model.MemberRemovalResult
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/removeMember [post]
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamMemberRemoveQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	result, err := h.teamService.RemoveMember(ctx, query.TeamName, query.UserID)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// MoveMember godoc
+// @Summary      move user to another primary team
+// @Description  reviews decides about unfinished reviews of the user: keep (default) or reassign, which hands
+// @Description  reviews of PRs from outside the new team back to the authors' teams. reevaluate_authored picks
+// @Description  pending reviewers of the user's open PRs again from the new team. The move is audited
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamMoveQuery true "user_id, team_name, reviews, reevaluate_authored"
+// @Success      200  {object}  model.TeamMove
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/moveMember [post]
+func (h *TeamHandler) MoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamMoveQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	move, err := h.teamService.MoveUser(ctx, query.UserID, query.TeamName, query.Reviews, query.ReevaluateAuthored)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, move)
+}
+
+// GetMoves godoc
+// @Summary      audit trail of team changes
+// @Description  latest first, moves by /team/moveMember, /team/add, /team/delete and roster imports
+// @Tags         teams
+// @Produce      json
+// @Param        user_id query string false "only moves of the user"
+// @Param        team_name query string false "only moves from or to the team"
+// @Param        limit query int false "100 by default, at most 1000"
+// @Success      200  {array}   model.TeamMove
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/moves [get]
+func (h *TeamHandler) GetMoves(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamMovesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	moves, err := h.teamService.GetMoves(ctx, query.UserID, query.TeamName, query.Limit)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, moves)
+}
+
+// GetUserTeams godoc
+// @Summary      teams of user
+// @Description  the primary team and secondary teams of the user
+// @Tags         users
+// @Produce      json
+// @Param        user_id query string true "user id"
+// @Success      200  {object}  model.UserTeams
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /users/getTeams [get]
+func (h *TeamHandler) GetUserTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.UserIDQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, model.ParseErrorResponse(model.NewError(model.BadRequest, "%s", err)))
+		return
+	}
+
+	teams, err := h.teamService.GetUserTeams(ctx, query.UserID)
+	if err != nil {
+		writeTeamError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, teams)
+}
+
+func writeTeamError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	errResp := model.ParseErrorResponse(err)
+	switch errResp.Error.Code {
+	case model.BadRequest, model.TeamExists:
+		c.IndentedJSON(http.StatusBadRequest, errResp)
+	case model.NotFound:
+		c.IndentedJSON(http.StatusNotFound, errResp)
+	case model.HasOpenPRs:
+		c.IndentedJSON(http.StatusConflict, errResp)
+	default:
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+	}
+}
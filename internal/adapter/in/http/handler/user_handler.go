@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"pr-assignment/internal/adapter/in/http/dto"
 	"pr-assignment/internal/model"
@@ -11,12 +10,14 @@ import (
 )
 
 type UserHandler struct {
-	userService *service.UserService
-	prService   *service.PullRequestService
+	userService   *service.UserService
+	prService     *service.PullRequestService
+	digestService *service.DigestService
 }
 
-func NewUserHandler(userService *service.UserService, prService *service.PullRequestService) *UserHandler {
-	return &UserHandler{userService: userService, prService: prService}
+func NewUserHandler(userService *service.UserService, prService *service.PullRequestService,
+	digestService *service.DigestService) *UserHandler {
+	return &UserHandler{userService: userService, prService: prService, digestService: digestService}
 }
 
 // SetIsUserActive godoc
@@ -53,7 +54,8 @@ func (h *UserHandler) SetIsUserActive(c *gin.Context) {
 	if !query.IsActive {
 		err = h.prService.ReassignReviewsAfterDeath(ctx, query.UserID)
 		if err != nil {
-			fmt.Println(err)
+			// the user is deactivated anyway, the failure only goes to the log
+			_ = c.Error(err)
 		}
 	}
 
@@ -105,6 +107,7 @@ func (h *UserHandler) GetReviews(c *gin.Context) {
 
 // AddTeam godoc
 // @Summary      add new team
+// @Description  members must be new users, users that already have a primary team are moved with /team/moveMember, a user_id listed twice is rejected
 // @Tags         teams
 // @Accept       json
 // @Produce      json
@@ -125,12 +128,16 @@ func (h *UserHandler) AddTeam(c *gin.Context) {
 
 	err := h.userService.AddTeam(ctx, team)
 	if err != nil {
-		fmt.Println(err)
+		_ = c.Error(err)
 		errorResp := model.ParseErrorResponse(err)
-		if errorResp.Error.Code == model.TeamExists {
+		if errorResp.Error.Code == model.TeamExists || errorResp.Error.Code == model.BadRequest {
 			c.IndentedJSON(400, model.ParseErrorResponse(err))
 			return
 		}
+		if errorResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, model.ParseErrorResponse(err))
+			return
+		}
 		c.IndentedJSON(http.StatusInternalServerError, model.ParseErrorResponse(err))
 		return
 	}
@@ -139,10 +146,12 @@ func (h *UserHandler) AddTeam(c *gin.Context) {
 
 // GetTeam godoc
 // @Summary      get existing team
+// @Description  with subtree all sub-teams are nested in sub_teams
 // @Tags         teams
 // @Accept       json
 // @Produce      json
 // @Param        team_name query string true "team_name"
+// @Param        subtree query bool false "include sub-teams"
 // @Success      200  {object}   model.Team
 // @Failure      400  {object}  model.ErrorResponse
 // @Failure      404  {object}  model.ErrorResponse
@@ -151,14 +160,19 @@ func (h *UserHandler) AddTeam(c *gin.Context) {
 func (h *UserHandler) GetTeam(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	var query dto.TeamName
+	var query dto.TeamGetQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	fmt.Println("q", query.TeamName)
-	team, err := h.userService.GetTeam(ctx, query.TeamName)
+	var team *model.Team
+	var err error
+	if query.Subtree {
+		team, err = h.userService.GetTeamTree(ctx, query.TeamName)
+	} else {
+		team, err = h.userService.GetTeam(ctx, query.TeamName)
+	}
 	if err != nil {
 		errorResp := model.ParseErrorResponse(err)
 		if errorResp.Error.Code == model.NotFound {
@@ -205,3 +219,160 @@ func (h *UserHandler) KillTeam(c *gin.Context) {
 
 	c.IndentedJSON(http.StatusOK, team)
 }
+
+// SetFallbackTeams godoc
+// @Summary      set fallback teams
+// @Description  set ordered list of teams to take reviewers from when the team can not fill the reviewers count
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamFallbacksQuery true "team_name, fallback_teams"
+// @Success      200  {object}   model.Team
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/setFallbacks [post]
+func (h *UserHandler) SetFallbackTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamFallbacksQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.userService.SetFallbackTeams(ctx, query.TeamName, query.FallbackTeams)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// SetUserRole godoc
+// @Summary      set user role
+// @Description  set role of the user: member, senior or lead
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.UserRoleQuery true "user id, role"
+// @Success      200  {object}   dto.UserResponse
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /users/setRole [post]
+func (h *UserHandler) SetUserRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.UserRoleQuery
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.SetUserRole(ctx, query.UserID, query.Role)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	response := dto.UserResponse{User: *user}
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// SetRoleRule godoc
+// @Summary      set team role rule
+// @Description  require at least min_count reviewers with the role or higher on every PR of the team, 0 removes the rule
+// @Tags         teams
+// @Accept       json
+// @Produce      json
+// @Param        query body dto.TeamRoleRuleQuery true "team_name, role, min_count"
+// @Success      200  {object}   model.Team
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /team/setRoleRule [post]
+func (h *UserHandler) SetRoleRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query dto.TeamRoleRuleQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.userService.SetRoleRule(ctx, query.TeamName, query.RoleRule)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// SetDigest godoc
+// @Summary      set review digest settings
+// @Description  set how often the user gets a digest of PRs waiting for review: daily, weekly or none
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        query body model.DigestSettings true "user id, email, frequency"
+// @Success      200  {object}  model.DigestSettings
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /users/setDigest [post]
+func (h *UserHandler) SetDigest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query model.DigestSettings
+	if err := c.BindJSON(&query); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.digestService.SetDigestSettings(ctx, query)
+	if err != nil {
+		errResp := model.ParseErrorResponse(err)
+		if errResp.Error.Code == model.NotFound {
+			c.IndentedJSON(http.StatusNotFound, errResp)
+			return
+		}
+		if errResp.Error.Code == model.BadRequest {
+			c.IndentedJSON(http.StatusBadRequest, errResp)
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, settings)
+}
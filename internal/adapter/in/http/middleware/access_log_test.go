@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"pr-assignment/internal/logging"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestAccessLogHasRequestAndTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	provider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+
+	// same order as the server router
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(otelgin.Middleware("test", otelgin.WithTracerProvider(provider)))
+	router.Use(AccessLog(logging.New(&logs, slog.LevelInfo, "json")))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatalf("unable to decode log line %q: %v", logs.String(), err)
+	}
+	if line["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", line["request_id"])
+	}
+	if traceID, _ := line["trace_id"].(string); len(traceID) != 32 {
+		t.Errorf("trace_id = %v, want a trace id", line["trace_id"])
+	}
+	if line["route"] != "/ping" || line["status"] != float64(http.StatusNoContent) {
+		t.Errorf("route/status = %v/%v", line["route"], line["status"])
+	}
+}
@@ -0,0 +1,57 @@
+package directory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"pr-assignment/internal/model"
+	"pr-assignment/internal/service"
+	"strings"
+)
+
+// FileSource reads groups from a roster file in the /team/import format, team_name is the
+// group name. The file is read again on every sync so it can be replaced in place
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Name() string {
+	return "file"
+}
+
+func (s *FileSource) Groups(_ context.Context) ([]model.DirectoryGroup, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []model.RosterEntry
+	if strings.EqualFold(filepath.Ext(s.path), ".csv") {
+		entries, err = service.ParseRosterCSV(file)
+	} else {
+		entries, err = service.ParseRosterYAML(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]model.DirectoryGroup, 0)
+	index := make(map[string]int)
+	for _, entry := range entries {
+		i, ok := index[entry.TeamName]
+		if !ok {
+			i = len(groups)
+			index[entry.TeamName] = i
+			groups = append(groups, model.DirectoryGroup{Name: entry.TeamName})
+		}
+		groups[i].Members = append(groups[i].Members, model.DirectoryUser{UserID: entry.UserID,
+			Username: entry.Username, IsActive: entry.IsActive})
+	}
+
+	return groups, nil
+}
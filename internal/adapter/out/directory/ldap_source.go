@@ -0,0 +1,153 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"pr-assignment/internal/model"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig describes where groups and users are and which attributes hold what
+type LDAPConfig struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	GroupFilter  string
+	// attribute with the group name
+	GroupNameAttr string
+	// attribute with DNs of group members
+	MemberAttr   string
+	UserFilter   string
+	UserIDAttr   string
+	UsernameAttr string
+	// optional attribute marking disabled accounts, any of true, yes, 1 disables the user
+	DisabledAttr string
+	Timeout      time.Duration
+	// 0 turns paging off
+	PageSize uint32
+}
+
+// LDAPSource reads groups with groupOfNames style member lists. Members that do not match
+// UserFilter are skipped, users that are nowhere to be found are departed
+type LDAPSource struct {
+	config LDAPConfig
+}
+
+func NewLDAPSource(config LDAPConfig) *LDAPSource {
+	return &LDAPSource{config: config}
+}
+
+func (s *LDAPSource) Name() string {
+	return "ldap"
+}
+
+func (s *LDAPSource) Groups(ctx context.Context) ([]model.DirectoryGroup, error) {
+	conn, err := ldap.DialURL(s.config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: s.config.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to ldap: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(s.config.Timeout)
+
+	// go-ldap has no context support, closing the connection aborts a hanging search
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if s.config.BindDN != "" {
+		if err = conn.Bind(s.config.BindDN, s.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("unable to bind to ldap: %w", err)
+		}
+	}
+
+	userAttrs := []string{s.config.UserIDAttr, s.config.UsernameAttr}
+	if s.config.DisabledAttr != "" {
+		userAttrs = append(userAttrs, s.config.DisabledAttr)
+	}
+	userEntries, err := s.search(conn, s.config.UserFilter, userAttrs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search ldap users: %w", err)
+	}
+
+	users := make(map[string]model.DirectoryUser, len(userEntries))
+	for _, entry := range userEntries {
+		userID := entry.GetAttributeValue(s.config.UserIDAttr)
+		if userID == "" {
+			continue
+		}
+		users[normalizeDN(entry.DN)] = model.DirectoryUser{UserID: userID,
+			Username: entry.GetAttributeValue(s.config.UsernameAttr), IsActive: !s.disabled(entry)}
+	}
+
+	groupEntries, err := s.search(conn, s.config.GroupFilter, []string{s.config.GroupNameAttr, s.config.MemberAttr})
+	if err != nil {
+		return nil, fmt.Errorf("unable to search ldap groups: %w", err)
+	}
+
+	groups := make([]model.DirectoryGroup, 0, len(groupEntries))
+	for _, entry := range groupEntries {
+		group := model.DirectoryGroup{Name: entry.GetAttributeValue(s.config.GroupNameAttr),
+			Members: make([]model.DirectoryUser, 0)}
+		if group.Name == "" {
+			continue
+		}
+		for _, memberDN := range entry.GetAttributeValues(s.config.MemberAttr) {
+			if user, ok := users[normalizeDN(memberDN)]; ok {
+				group.Members = append(group.Members, user)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (s *LDAPSource) search(conn *ldap.Conn, filter string, attrs []string) ([]*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(s.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0,
+		false, filter, attrs, nil)
+
+	var result *ldap.SearchResult
+	var err error
+	if s.config.PageSize > 0 {
+		result, err = conn.SearchWithPaging(request, s.config.PageSize)
+	} else {
+		result, err = conn.Search(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+func (s *LDAPSource) disabled(entry *ldap.Entry) bool {
+	if s.config.DisabledAttr == "" {
+		return false
+	}
+	switch strings.ToLower(entry.GetAttributeValue(s.config.DisabledAttr)) {
+	case "true", "yes", "1":
+		return true
+	}
+	return false
+}
+
+// normalizeDN makes member values comparable with entry DNs, directories differ in case and spacing
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	parts := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attrs := make([]string, 0, len(rdn.Attributes))
+		for _, attr := range rdn.Attributes {
+			attrs = append(attrs, strings.ToLower(attr.Type)+"="+strings.ToLower(attr.Value))
+		}
+		parts = append(parts, strings.Join(attrs, "+"))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,179 @@
+package directory
+
+import (
+	"context"
+	"pr-assignment/internal/adapter/out/directory/ldapstub"
+	"pr-assignment/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBaseDN = "dc=example,dc=com"
+
+func testDirectory() []ldapstub.Entry {
+	person := func(uid string, name string, attrs map[string][]string) ldapstub.Entry {
+		entry := ldapstub.Entry{DN: "uid=" + uid + ",ou=people," + testBaseDN, Attributes: map[string][]string{
+			"objectClass": {"inetOrgPerson"},
+			"uid":         {uid},
+			"cn":          {name},
+		}}
+		for key, values := range attrs {
+			entry.Attributes[key] = values
+		}
+		return entry
+	}
+
+	return []ldapstub.Entry{
+		person("u1", "Alice", nil),
+		person("u2", "Bob", map[string][]string{"nsAccountLock": {"TRUE"}}),
+		person("u3", "Carol", map[string][]string{"employeeType": {"contractor"}}),
+		person("u4", "Dave", map[string][]string{"nsAccountLock": {"no"}}),
+		{DN: "uid=u5,ou=people,dc=other,dc=com", Attributes: map[string][]string{
+			"objectClass": {"inetOrgPerson"}, "uid": {"u5"}, "cn": {"Eve"}}},
+		{DN: "cn=backend,ou=groups," + testBaseDN, Attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"backend"},
+			"member": {
+				// spelled differently from the entry DN
+				"UID=U1, OU=People, DC=Example, DC=Com",
+				"uid=u2,ou=people," + testBaseDN,
+				// filtered out by UserFilter
+				"uid=u3,ou=people," + testBaseDN,
+				// not in the directory at all
+				"uid=ghost,ou=people," + testBaseDN,
+			},
+		}},
+		{DN: "cn=frontend,ou=groups," + testBaseDN, Attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"frontend"},
+			"member":      {"uid=u4,ou=people," + testBaseDN, "uid=u1,ou=people," + testBaseDN},
+		}},
+		{DN: "cn=nameless,ou=groups," + testBaseDN, Attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"member":      {"uid=u1,ou=people," + testBaseDN},
+		}},
+	}
+}
+
+func testLDAPConfig(url string) LDAPConfig {
+	return LDAPConfig{
+		URL:           url,
+		BindDN:        "cn=sync," + testBaseDN,
+		BindPassword:  "secret",
+		BaseDN:        testBaseDN,
+		GroupFilter:   "(objectClass=groupOfNames)",
+		GroupNameAttr: "cn",
+		MemberAttr:    "member",
+		UserFilter:    "(&(objectClass=inetOrgPerson)(!(employeeType=contractor)))",
+		UserIDAttr:    "uid",
+		UsernameAttr:  "cn",
+		DisabledAttr:  "nsAccountLock",
+		Timeout:       5 * time.Second,
+	}
+}
+
+func startStub(t *testing.T) *ldapstub.Server {
+	t.Helper()
+
+	server, err := ldapstub.Start("127.0.0.1:0", testDirectory())
+	if err != nil {
+		t.Fatalf("unable to start ldap stub: %v", err)
+	}
+	server.BindDN = "cn=sync," + testBaseDN
+	server.BindPassword = "secret"
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+func TestLDAPSourceGroups(t *testing.T) {
+	want := []model.DirectoryGroup{
+		{Name: "backend", Members: []model.DirectoryUser{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: false},
+		}},
+		{Name: "frontend", Members: []model.DirectoryUser{
+			{UserID: "u4", Username: "Dave", IsActive: true},
+			{UserID: "u1", Username: "Alice", IsActive: true},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		pageSize uint32
+		// users and groups, one request each without paging
+		searches int
+	}{
+		{name: "without paging", pageSize: 0, searches: 2},
+		// 4 users and 3 groups match, two pages of each
+		{name: "pages of two", pageSize: 2, searches: 4},
+		{name: "page larger than the result", pageSize: 100, searches: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startStub(t)
+			config := testLDAPConfig(server.URL())
+			config.PageSize = tt.pageSize
+
+			groups, err := NewLDAPSource(config).Groups(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(groups, want) {
+				t.Errorf("groups = %+v, want %+v", groups, want)
+			}
+			if server.Searches() != tt.searches {
+				t.Errorf("searches = %d, want %d", server.Searches(), tt.searches)
+			}
+		})
+	}
+}
+
+func TestLDAPSourceWithoutDisabledAttr(t *testing.T) {
+	server := startStub(t)
+	config := testLDAPConfig(server.URL())
+	config.DisabledAttr = ""
+
+	groups, err := NewLDAPSource(config).Groups(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, group := range groups {
+		for _, member := range group.Members {
+			if !member.IsActive {
+				t.Errorf("%s of %s is inactive without a disabled attribute", member.UserID, group.Name)
+			}
+		}
+	}
+}
+
+func TestLDAPSourceBindFailure(t *testing.T) {
+	server := startStub(t)
+	config := testLDAPConfig(server.URL())
+	config.BindPassword = "wrong"
+
+	_, err := NewLDAPSource(config).Groups(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unable to bind") {
+		t.Fatalf("err = %v, want a bind error", err)
+	}
+}
+
+func TestNormalizeDN(t *testing.T) {
+	tests := []struct {
+		dn   string
+		want string
+	}{
+		{dn: "uid=u1,ou=people,dc=example,dc=com", want: "uid=u1,ou=people,dc=example,dc=com"},
+		{dn: "UID=U1, OU=People , DC=Example,DC=Com", want: "uid=u1,ou=people,dc=example,dc=com"},
+		{dn: "cn=Alice+uid=u1,dc=com", want: "cn=alice+uid=u1,dc=com"},
+		{dn: "not a dn", want: "not a dn"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeDN(tt.dn); got != tt.want {
+			t.Errorf("normalizeDN(%q) = %q, want %q", tt.dn, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Package ldapstub is an in-process LDAP server for trying LDAPSource without a real directory.
+// It speaks just enough of the protocol for go-ldap: simple bind, search with and, or, not,
+// equality and presence filters, paged results, and unbind
+package ldapstub
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"pr-assignment/internal/model"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Entry is a directory object, attribute names are matched case-insensitively
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+type Server struct {
+	// when set, binds must use these credentials
+	BindDN       string
+	BindPassword string
+
+	listener net.Listener
+	entries  []Entry
+	wg       sync.WaitGroup
+	searches atomic.Int64
+}
+
+// Start serves the entries on addr, use 127.0.0.1:0 for a free port
+func Start(addr string, entries []Entry) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: listener, entries: entries}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// URL is the address for LDAPConfig.URL
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// Searches counts search requests served, every page is a request of its own
+func (s *Server) Searches() int {
+	return int(s.searches.Load())
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// GroupsToEntries lays groups out as ou=people and ou=groups under baseDN with inetOrgPerson
+// users (uid, cn, disabled) and groupOfNames groups (cn, member)
+func GroupsToEntries(baseDN string, groups []model.DirectoryGroup) []Entry {
+	entries := make([]Entry, 0)
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		members := make([]string, 0, len(group.Members))
+		for _, user := range group.Members {
+			userDN := fmt.Sprintf("uid=%s,ou=people,%s", ldap.EscapeDN(user.UserID), baseDN)
+			members = append(members, userDN)
+			if seen[userDN] {
+				continue
+			}
+			seen[userDN] = true
+			entries = append(entries, Entry{DN: userDN, Attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"},
+				"uid":         {user.UserID},
+				"cn":          {user.Username},
+				"disabled":    {fmt.Sprint(!user.IsActive)},
+			}})
+		}
+
+		entries = append(entries, Entry{DN: fmt.Sprintf("cn=%s,ou=groups,%s", ldap.EscapeDN(group.Name), baseDN),
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {group.Name},
+				"member":      members,
+			}})
+	}
+	return entries
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.serve(conn)
+		}()
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		op := packet.Children[1]
+		var responses []*ber.Packet
+		var controls []ldap.Control
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = []*ber.Packet{s.bind(op)}
+		case ldap.ApplicationSearchRequest:
+			var requestControls *ber.Packet
+			if len(packet.Children) > 2 {
+				requestControls = packet.Children[2]
+			}
+			responses, controls = s.search(op, requestControls)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationAbandonRequest:
+			continue
+		default:
+			responses = []*ber.Packet{result(ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform,
+				"operation is not supported by the stub")}
+		}
+
+		for i, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID,
+				"Message ID"))
+			envelope.AppendChild(response)
+			// controls go with the final message
+			if i == len(responses)-1 && len(controls) > 0 {
+				packet := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+				for _, control := range controls {
+					packet.AppendChild(control.Encode())
+				}
+				envelope.AppendChild(packet)
+			}
+			if _, err = conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) *ber.Packet {
+	if s.BindDN == "" {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+	if len(op.Children) < 3 {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "malformed bind request")
+	}
+
+	dn := stringValue(op.Children[1])
+	password := stringValue(op.Children[2])
+	if !strings.EqualFold(dn, s.BindDN) || password != s.BindPassword {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+	}
+	return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+}
+
+// search answers with matching entries. With a paging control the cookie is the offset of the
+// next page, an empty cookie ends the search and page size 0 abandons it
+func (s *Server) search(op *ber.Packet, controls *ber.Packet) ([]*ber.Packet, []ldap.Control) {
+	s.searches.Add(1)
+	if len(op.Children) < 8 {
+		return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError,
+			"malformed search request")}, nil
+	}
+
+	var paging *ldap.ControlPaging
+	if controls != nil {
+		for _, child := range controls.Children {
+			control, err := ldap.DecodeControl(child)
+			if err != nil {
+				return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError,
+					err.Error())}, nil
+			}
+			if control, ok := control.(*ldap.ControlPaging); ok {
+				paging = control
+			}
+		}
+	}
+
+	baseDN := strings.ToLower(stringValue(op.Children[0]))
+	filter := op.Children[6]
+	requested := make(map[string]bool)
+	for _, attr := range op.Children[7].Children {
+		requested[strings.ToLower(stringValue(attr))] = true
+	}
+
+	responses := make([]*ber.Packet, 0)
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), baseDN) {
+			continue
+		}
+		matched, err := matches(entry, filter)
+		if err != nil {
+			return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultUnwillingToPerform,
+				err.Error())}, nil
+		}
+		if matched {
+			responses = append(responses, entryPacket(entry, requested))
+		}
+	}
+
+	done := result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+	if paging == nil {
+		return append(responses, done), nil
+	}
+
+	offset := 0
+	if len(paging.Cookie) > 0 {
+		var err error
+		offset, err = strconv.Atoi(string(paging.Cookie))
+		if err != nil || offset < 0 || offset > len(responses) {
+			return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultUnwillingToPerform,
+				"invalid paging cookie")}, nil
+		}
+	}
+
+	next := &ldap.ControlPaging{}
+	if paging.PagingSize == 0 {
+		return []*ber.Packet{done}, []ldap.Control{next}
+	}
+	end := min(offset+int(paging.PagingSize), len(responses))
+	if end < len(responses) {
+		next.SetCookie([]byte(strconv.Itoa(end)))
+	}
+	return append(responses[offset:end], done), []ldap.Control{next}
+}
+
+func matches(entry Entry, filter *ber.Packet) (bool, error) {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			ok, err := matches(entry, child)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			ok, err := matches(entry, child)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("malformed not filter")
+		}
+		ok, err := matches(entry, filter.Children[0])
+		return !ok, err
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false, errors.New("malformed equality filter")
+		}
+		want := stringValue(filter.Children[1])
+		for _, value := range attribute(entry, stringValue(filter.Children[0])) {
+			if strings.EqualFold(value, want) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ldap.FilterPresent:
+		return len(attribute(entry, filter.Data.String())) > 0, nil
+	}
+	return false, fmt.Errorf("filter %s is not supported by the stub", ldap.FilterMap[uint64(filter.Tag)])
+}
+
+func attribute(entry Entry, name string) []string {
+	for key, values := range entry.Attributes {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func entryPacket(entry Entry, requested map[string]bool) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil,
+		"Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.Attributes {
+		if len(requested) > 0 && !requested[strings.ToLower(name)] {
+			continue
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	packet.AppendChild(attrs)
+	return packet
+}
+
+func result(application ber.Tag, code uint16, message string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, ldap.ApplicationMap[uint8(application)])
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code),
+		"Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message,
+		"Diagnostic Message"))
+	return packet
+}
+
+// stringValue reads octet strings and context specific primitives alike
+func stringValue(packet *ber.Packet) string {
+	if value, ok := packet.Value.(string); ok {
+		return value
+	}
+	return packet.Data.String()
+}
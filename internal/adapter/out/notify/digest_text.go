@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"fmt"
+	"pr-assignment/internal/model"
+	"strings"
+)
+
+func digestSubject(digest model.ReviewDigest) string {
+	return fmt.Sprintf("You have %d pull requests waiting for review", len(digest.PullRequests))
+}
+
+func digestBody(digest model.ReviewDigest) string {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hi %s,\r\n\r\nthese pull requests are still waiting for your review:\r\n\r\n", digest.Username)
+	for _, pr := range digest.PullRequests {
+		fmt.Fprintf(&body, "- %s %s by %s, open for %dh\r\n", pr.PullRequestID, pr.PullRequestName,
+			pr.AuthorID, pr.AgeHours)
+	}
+
+	return body.String()
+}
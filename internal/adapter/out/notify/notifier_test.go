@@ -0,0 +1,254 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"pr-assignment/internal/model"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDigest() model.ReviewDigest {
+	created := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	return model.ReviewDigest{
+		UserID:   "u2",
+		Username: "Bob",
+		Email:    "bob@example.com",
+		PullRequests: []model.DigestItem{
+			{PullRequestShort: model.PullRequestShort{PullRequestID: "pr-1", PullRequestName: "Add search",
+				AuthorID: "u1", Status: model.CREATED}, CreatedAt: created, AgeHours: 50},
+			{PullRequestShort: model.PullRequestShort{PullRequestID: "pr-2", PullRequestName: "Fix login",
+				AuthorID: "u3", Status: model.CREATED}, CreatedAt: created.Add(24 * time.Hour), AgeHours: 26},
+		},
+	}
+}
+
+// smtpMail is what the fake server received in one session
+type smtpMail struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// startSMTP accepts a single session on a local port and sends the mail it got to the channel
+func startSMTP(t *testing.T) (string, int, <-chan smtpMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	mails := make(chan smtpMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		mail := smtpMail{}
+		_ = text.PrintfLine("220 localhost fake smtp")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case command == "EHLO" || command == "HELO":
+				_ = text.PrintfLine("250 localhost")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				mail.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				mail.recipients = append(mail.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, err := io.ReadAll(text.DotReader())
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 bye")
+				mails <- mail
+				return
+			default:
+				_ = text.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, mails
+}
+
+func TestSMTPNotifierSendDigest(t *testing.T) {
+	host, port, mails := startSMTP(t)
+	digest := testDigest()
+
+	err := NewSMTPNotifier(host, port, "reviews@example.com", "", "").SendDigest(context.Background(), digest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var mail smtpMail
+	select {
+	case mail = <-mails:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake server got no mail")
+	}
+
+	if mail.from != "reviews@example.com" {
+		t.Errorf("from = %q, want reviews@example.com", mail.from)
+	}
+	if !reflect.DeepEqual(mail.recipients, []string{"bob@example.com"}) {
+		t.Errorf("recipients = %v, want [bob@example.com]", mail.recipients)
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(mail.data)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("unable to read headers: %v", err)
+	}
+	wantHeader := map[string]string{
+		"From":         "reviews@example.com",
+		"To":           "bob@example.com",
+		"Subject":      "You have 2 pull requests waiting for review",
+		"Content-Type": "text/plain; charset=utf-8",
+	}
+	for key, want := range wantHeader {
+		if got := header.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	body, err := io.ReadAll(reader.R)
+	if err != nil {
+		t.Fatalf("unable to read body: %v", err)
+	}
+	// the dot reader hands lines back with bare newlines
+	if want := strings.ReplaceAll(digestBody(digest), "\r\n", "\n"); string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestSMTPNotifierWithoutEmail(t *testing.T) {
+	digest := testDigest()
+	digest.Email = ""
+
+	err := NewSMTPNotifier("127.0.0.1", 1, "reviews@example.com", "", "").SendDigest(context.Background(), digest)
+	if err == nil || !strings.Contains(err.Error(), "has no email") {
+		t.Fatalf("err = %v, want a missing email error", err)
+	}
+}
+
+func TestDigestBody(t *testing.T) {
+	want := "Hi Bob,\r\n\r\nthese pull requests are still waiting for your review:\r\n\r\n" +
+		"- pr-1 Add search by u1, open for 50h\r\n" +
+		"- pr-2 Fix login by u3, open for 26h\r\n"
+	if got := digestBody(testDigest()); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookNotifierSendDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{name: "delivered", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusBadGateway, wantErr: "webhook responded 502 for digest of u2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload webhookPayload
+			var contentType, method string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				contentType = r.Header.Get("Content-Type")
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("unable to decode payload: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			digest := testDigest()
+			err := NewWebhookNotifier(server.URL).SendDigest(context.Background(), digest)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if method != http.MethodPost || contentType != "application/json" {
+				t.Errorf("request = %s %s, want POST application/json", method, contentType)
+			}
+			if payload.Subject != digestSubject(digest) || payload.Text != digestBody(digest) {
+				t.Errorf("subject/text = %q / %q", payload.Subject, payload.Text)
+			}
+			if !reflect.DeepEqual(payload.ReviewDigest, digest) {
+				t.Errorf("digest = %+v, want %+v", payload.ReviewDigest, digest)
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierStopsWithContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// the server accepts and never greets, like a stalled relay
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewSMTPNotifier(host, portNumber, "reviews@example.com", "", "").SendDigest(ctx, testDigest())
+	}()
+
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("want an error from a cancelled send")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send did not stop when the context was cancelled")
+	}
+}
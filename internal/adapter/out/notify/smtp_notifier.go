@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"pr-assignment/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// one digest must not hold the job for longer than this when the server stalls
+const smtpTimeout = 30 * time.Second
+
+// SMTPNotifier sends digests by email. Without username it talks to the server
+// without authentication, which is enough for a local fake SMTP server
+type SMTPNotifier struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPNotifier(host string, port int, from string, username string, password string) *SMTPNotifier {
+	return &SMTPNotifier{addr: net.JoinHostPort(host, strconv.Itoa(port)), host: host, from: from,
+		username: username, password: password}
+}
+
+func (n *SMTPNotifier) SendDigest(ctx context.Context, digest model.ReviewDigest) error {
+	if digest.Email == "" {
+		return fmt.Errorf("user %s has no email", digest.UserID)
+	}
+
+	err := n.send(ctx, digest)
+	if err != nil {
+		return fmt.Errorf("error sending digest to %s: %w", digest.UserID, err)
+	}
+	return nil
+}
+
+// send is smtp.SendMail over a connection bound to ctx: the dial and every command stop at
+// the ctx deadline or after smtpTimeout, and cancelling ctx closes the connection
+func (n *SMTPNotifier) send(ctx context.Context, digest model.ReviewDigest) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.username != "" {
+		if err = client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(n.from); err != nil {
+		return err
+	}
+	if err = client.Rcpt(digest.Email); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(n.message(digest)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *SMTPNotifier) message(digest model.ReviewDigest) []byte {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", digest.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", digestSubject(digest))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(digestBody(digest))
+
+	return []byte(msg.String())
+}
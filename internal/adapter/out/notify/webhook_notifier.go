@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pr-assignment/internal/model"
+	"time"
+)
+
+type webhookPayload struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	model.ReviewDigest
+}
+
+// WebhookNotifier posts digests as JSON to the configured url
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) SendDigest(ctx context.Context, digest model.ReviewDigest) error {
+	payload, err := json.Marshal(webhookPayload{Subject: digestSubject(digest), Text: digestBody(digest),
+		ReviewDigest: digest})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending digest to %s: %w", digest.UserID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded %d for digest of %s", resp.StatusCode, digest.UserID)
+	}
+	return nil
+}
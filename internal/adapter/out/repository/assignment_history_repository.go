@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pr-assignment/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// every reviewer assignment ever made, kept after reassignments
+
+type AssignmentHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAssignmentHistoryRepository(pool *pgxpool.Pool) *AssignmentHistoryRepository {
+	return &AssignmentHistoryRepository{pool: pool}
+}
+
+func (r *AssignmentHistoryRepository) AddAssignment(ctx context.Context, pullRequestID string, authorID string,
+	reviewerID string, assignedAt time.Time) error {
+	sql := `
+        INSERT INTO review_assignments (pull_request_id, author_id, reviewer_id, assigned_at)
+        VALUES ($1, $2, $3, $4)`
+
+	_, err := r.pool.Exec(ctx, sql, pullRequestID, authorID, reviewerID, assignedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reviewers of the author since the given time with number of reviews and the latest one
+func (r *AssignmentHistoryRepository) GetReviewersOfAuthor(ctx context.Context, authorID string,
+	since time.Time) (map[string]model.ReviewerPairing, error) {
+	sql := `
+        SELECT reviewer_id, COUNT(*), MAX(assigned_at) FROM review_assignments
+        WHERE author_id = $1 AND assigned_at >= $2
+        GROUP BY reviewer_id`
+
+	rows, err := r.pool.Query(ctx, sql, authorID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	pairings := make(map[string]model.ReviewerPairing)
+	for rows.Next() {
+		pairing := model.ReviewerPairing{}
+		err = rows.Scan(&pairing.ReviewerID, &pairing.Count, &pairing.LastAssignedAt)
+		if err != nil {
+			return nil, err
+		}
+		pairings[pairing.ReviewerID] = pairing
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment rows: %w", err)
+	}
+
+	return pairings, nil
+}
+
+// StreamPairingCounts passes the number of assignments of every author and reviewer pair to fn,
+// ordered by author
+func (r *AssignmentHistoryRepository) StreamPairingCounts(ctx context.Context, fn func(model.PairingCount) error) error {
+	sql := `
+        SELECT author_id, reviewer_id, COUNT(*) FROM review_assignments
+        GROUP BY author_id, reviewer_id
+        ORDER BY author_id, reviewer_id`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		pairing := model.PairingCount{}
+		err = rows.Scan(&pairing.AuthorID, &pairing.ReviewerID, &pairing.Count)
+		if err != nil {
+			return err
+		}
+		if err = fn(pairing); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating assignment rows: %w", err)
+	}
+
+	return nil
+}
+
+// assignments per user in [from, to) split into buckets, every active user and every
+// user assigned in the range gets a point for each bucket. Users are passed to fn one by one
+// with all their points, so only one series is held at a time. Empty team name means all teams
+func (r *AssignmentHistoryRepository) StreamReviewLoad(ctx context.Context, from time.Time, to time.Time,
+	bucket model.StatBucket, teamName string, fn func(model.UserReviewLoad) error) error {
+	sql := `
+        WITH buckets AS (
+            SELECT generate_series(date_trunc($3::text, $1::timestamptz), $2::timestamptz - interval '1 microsecond',
+                ('1 ' || $3::text)::interval) AS bucket_start
+        ), reviewers AS (
+            SELECT user_id AS reviewer_id FROM users WHERE is_active
+            UNION
+            SELECT reviewer_id FROM review_assignments WHERE assigned_at >= $1 AND assigned_at < $2
+        )
+        SELECT u.user_id, u.username, u.team_name, u.is_active, u.role, b.bucket_start, COUNT(a.assignment_id)
+        FROM reviewers rv
+        JOIN users u ON u.user_id = rv.reviewer_id
+        JOIN teams t ON t.team_id = u.team_name
+        CROSS JOIN buckets b
+        LEFT JOIN review_assignments a ON a.reviewer_id = rv.reviewer_id
+            AND a.assigned_at >= GREATEST(b.bucket_start, $1)
+            AND a.assigned_at < LEAST(b.bucket_start + ('1 ' || $3::text)::interval, $2)
+        WHERE $4::text = '' OR t.team_name = $4
+        GROUP BY u.user_id, b.bucket_start
+        ORDER BY u.user_id, b.bucket_start`
+
+	rows, err := r.pool.Query(ctx, sql, from, to, string(bucket), teamName)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	var load *model.UserReviewLoad
+	for rows.Next() {
+		user := model.User{}
+		point := model.ReviewLoadPoint{}
+		err = rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role,
+			&point.Start, &point.Count)
+		if err != nil {
+			return err
+		}
+
+		// rows are ordered by user, a new user means the previous series is complete
+		if load != nil && load.User.UserID != user.UserID {
+			if err = fn(*load); err != nil {
+				return err
+			}
+			load = nil
+		}
+		if load == nil {
+			load = &model.UserReviewLoad{User: user, Points: []model.ReviewLoadPoint{}}
+		}
+		load.Points = append(load.Points, point)
+		load.Total += point.Count
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating assignment rows: %w", err)
+	}
+
+	if load != nil {
+		return fn(*load)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExclusionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExclusionRepository(pool *pgxpool.Pool) *ExclusionRepository {
+	return &ExclusionRepository{pool: pool}
+}
+
+func (r *ExclusionRepository) AddExclusion(ctx context.Context, exclusion model.ReviewerExclusion) (*model.ReviewerExclusion, error) {
+	sql := `
+        INSERT INTO reviewer_exclusions (author_id, reviewer_id, is_symmetric, reason)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (author_id, reviewer_id) DO UPDATE SET is_symmetric = $3, reason = $4
+        RETURNING author_id, reviewer_id, is_symmetric, reason, created_at`
+
+	created := model.ReviewerExclusion{}
+	err := r.pool.QueryRow(ctx, sql, exclusion.AuthorID, exclusion.ReviewerID, exclusion.Symmetric,
+		exclusion.Reason).Scan(
+		&created.AuthorID,
+		&created.ReviewerID,
+		&created.Symmetric,
+		&created.Reason,
+		&created.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding exclusion: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (r *ExclusionRepository) RemoveExclusion(ctx context.Context, authorID string, reviewerID string) error {
+	sql := `
+        DELETE FROM reviewer_exclusions
+        WHERE author_id = $1 AND reviewer_id = $2
+        RETURNING author_id`
+
+	var deleted string
+	err := r.pool.QueryRow(ctx, sql, authorID, reviewerID).Scan(&deleted)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.NewError(model.NotFound, "exclusion of %s for %s not found", reviewerID, authorID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// exclusions where the user is on any side
+func (r *ExclusionRepository) GetExclusionsByUser(ctx context.Context, userID string) ([]model.ReviewerExclusion, error) {
+	sql := `
+        SELECT author_id, reviewer_id, is_symmetric, reason, created_at FROM reviewer_exclusions
+        WHERE author_id = $1 OR reviewer_id = $1
+        ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	exclusions := make([]model.ReviewerExclusion, 0)
+	for rows.Next() {
+		exclusion := model.ReviewerExclusion{}
+		err = rows.Scan(&exclusion.AuthorID, &exclusion.ReviewerID, &exclusion.Symmetric,
+			&exclusion.Reason, &exclusion.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		exclusions = append(exclusions, exclusion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exclusion rows: %w", err)
+	}
+
+	return exclusions, nil
+}
+
+// users that are not allowed to review PRs of the author
+func (r *ExclusionRepository) GetExcludedReviewers(ctx context.Context, authorID string) ([]string, error) {
+	sql := `
+        SELECT reviewer_id FROM reviewer_exclusions WHERE author_id = $1
+        UNION
+        SELECT author_id FROM reviewer_exclusions WHERE reviewer_id = $1 AND is_symmetric`
+
+	rows, err := r.pool.Query(ctx, sql, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	userIDs := make([]string, 0)
+	var userID string
+	for rows.Next() {
+		err = rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exclusion rows: %w", err)
+	}
+
+	return userIDs, nil
+}
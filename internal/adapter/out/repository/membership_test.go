@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"pr-assignment/database"
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // to migrate the test database
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to TEST_DATABASE_URL, a scratch database: it is migrated up and every
+// team with its users is truncated before the test. Without the variable the test is skipped
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	src, err := iofs.New(database.Migrations, database.MigrationsDir)
+	if err != nil {
+		t.Fatalf("unable to read migrations: %v", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		t.Fatalf("unable to init migrations: %v", err)
+	}
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("unable to apply migrations: %v", err)
+	}
+	_, _ = m.Close()
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if _, err = pool.Exec(ctx, "TRUNCATE teams CASCADE"); err != nil {
+		t.Fatalf("unable to clean the database: %v", err)
+	}
+	return pool
+}
+
+// addTestTeam creates the team with active members and returns its id
+func addTestTeam(t *testing.T, pool *pgxpool.Pool, teamName string, userIDs ...string) string {
+	t.Helper()
+
+	team := model.Team{TeamName: teamName}
+	for _, userID := range userIDs {
+		team.Members = append(team.Members, model.TeamMember{UserID: userID, Username: userID, IsActive: true})
+	}
+
+	ctx := context.Background()
+	teamID := uuid.New()
+	if err := NewTeamRepository(pool).AddTeam(ctx, team, teamID); err != nil {
+		t.Fatalf("unable to add team %s: %v", teamName, err)
+	}
+	if err := NewUserRepository(pool).AddTeam(ctx, team, teamID); err != nil {
+		t.Fatalf("unable to add members of %s: %v", teamName, err)
+	}
+	return teamID.String()
+}
+
+func TestTeamMembersView(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	users := NewUserRepository(pool)
+
+	backendID := addTestTeam(t, pool, "backend", "u1")
+	platformID := addTestTeam(t, pool, "platform", "u2")
+	addTestTeam(t, pool, "frontend", "u3")
+
+	// a leftover membership in the primary team must not list the team twice
+	if _, err := pool.Exec(ctx, "INSERT INTO team_memberships (user_id, team_id) VALUES ($1, $2)",
+		"u1", backendID); err != nil {
+		t.Fatalf("unable to add leftover membership: %v", err)
+	}
+
+	added, err := users.AddMembership(ctx, "u1", platformID)
+	if err != nil || !added {
+		t.Fatalf("AddMembership = %v, %v, want added", added, err)
+	}
+
+	teams, err := users.GetUserTeams(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUserTeams: %v", err)
+	}
+	want := &model.UserTeams{UserID: "u1", PrimaryTeam: "backend", Teams: []string{"backend", "platform"}}
+	if !reflect.DeepEqual(teams, want) {
+		t.Errorf("GetUserTeams = %+v, want %+v", teams, want)
+	}
+
+	for _, tt := range []struct {
+		userID string
+		teamID string
+		want   bool
+	}{
+		{userID: "u1", teamID: backendID, want: true},
+		{userID: "u1", teamID: platformID, want: true},
+		{userID: "u2", teamID: backendID},
+		{userID: "u3", teamID: platformID},
+	} {
+		isMember, err := users.IsMember(ctx, tt.userID, tt.teamID)
+		if err != nil {
+			t.Fatalf("IsMember: %v", err)
+		}
+		if isMember != tt.want {
+			t.Errorf("IsMember(%s, %s) = %v, want %v", tt.userID, tt.teamID, isMember, tt.want)
+		}
+	}
+
+	_, err = users.GetUserTeams(ctx, "nobody")
+	var customErr *model.CustomError
+	if !errors.As(err, &customErr) || customErr.Code != model.NotFound {
+		t.Errorf("GetUserTeams of an unknown user err = %v, want not found", err)
+	}
+}
+
+func TestMembershipChanges(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	users := NewUserRepository(pool)
+
+	backendID := addTestTeam(t, pool, "backend", "u1")
+	platformID := addTestTeam(t, pool, "platform")
+
+	added, err := users.AddMember(ctx, platformID, model.TeamMember{UserID: "u2", Username: "u2",
+		IsActive: true, Role: model.MEMBER})
+	if err != nil || !added {
+		t.Fatalf("AddMember of a new user = %v, %v, want added", added, err)
+	}
+
+	// a user of another team is not created again, the service adds a membership instead
+	added, err = users.AddMember(ctx, platformID, model.TeamMember{UserID: "u1", Username: "u1",
+		IsActive: true, Role: model.MEMBER})
+	if err != nil || added {
+		t.Fatalf("AddMember of an existing user = %v, %v, want not added", added, err)
+	}
+
+	steps := []struct {
+		name   string
+		change func() (bool, error)
+		want   bool
+	}{
+		{name: "primary team", change: func() (bool, error) { return users.AddMembership(ctx, "u1", backendID) }},
+		{name: "add", change: func() (bool, error) { return users.AddMembership(ctx, "u1", platformID) },
+			want: true},
+		{name: "add again", change: func() (bool, error) { return users.AddMembership(ctx, "u1", platformID) }},
+		{name: "remove", change: func() (bool, error) { return users.RemoveMembership(ctx, "u1", platformID) },
+			want: true},
+		{name: "remove again",
+			change: func() (bool, error) { return users.RemoveMembership(ctx, "u1", platformID) }},
+	}
+	for _, step := range steps {
+		changed, err := step.change()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if changed != step.want {
+			t.Errorf("%s changed = %v, want %v", step.name, changed, step.want)
+		}
+	}
+
+	teams, err := users.GetUserTeams(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUserTeams: %v", err)
+	}
+	if !reflect.DeepEqual(teams.Teams, []string{"backend"}) {
+		t.Errorf("teams after removal = %v, want [backend]", teams.Teams)
+	}
+}
+
+func TestMemberCountsIncludeSecondaryMembers(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	users := NewUserRepository(pool)
+	teams := NewTeamRepository(pool)
+
+	backendID := addTestTeam(t, pool, "backend", "u1", "u2")
+	platformID := addTestTeam(t, pool, "platform", "u3")
+	if _, err := pool.Exec(ctx, "UPDATE teams SET parent_team_id = $1 WHERE team_id = $2",
+		backendID, platformID); err != nil {
+		t.Fatalf("unable to set parent: %v", err)
+	}
+	if _, err := users.AddMembership(ctx, "u1", platformID); err != nil {
+		t.Fatalf("AddMembership: %v", err)
+	}
+
+	list, _, err := teams.ListTeams(ctx, "platform", 10, 0)
+	if err != nil {
+		t.Fatalf("ListTeams: %v", err)
+	}
+	if len(list) != 1 || list[0].Members != 2 || list[0].ActiveMembers != 2 {
+		t.Errorf("ListTeams = %+v, want platform with 2 members", list)
+	}
+
+	for _, tt := range []struct {
+		rollup bool
+		want   int
+	}{
+		{rollup: false, want: 2},
+		// u1 is in both teams of the subtree and is counted once
+		{rollup: true, want: 3},
+	} {
+		stats, err := teams.GetTeamStats(ctx, "", 1, tt.rollup)
+		if err != nil {
+			t.Fatalf("GetTeamStats: %v", err)
+		}
+		got := map[string]int{}
+		for _, stat := range stats {
+			got[stat.TeamName] = stat.ActiveMembers
+		}
+		if got["platform"] != 2 {
+			t.Errorf("rollup %v: platform active members = %d, want 2", tt.rollup, got["platform"])
+		}
+		if got["backend"] != tt.want {
+			t.Errorf("rollup %v: backend active members = %d, want %d", tt.rollup, got["backend"], tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"pr-assignment/internal/model"
 	"time"
 
@@ -20,19 +21,15 @@ func NewPullRequestRepository(pool *pgxpool.Pool) *PullRequestRepository {
 
 func (r *PullRequestRepository) GetPR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
 	sql := `
-        SELECT * FROM pull_requests
-        WHERE pull_request_id = $1`
+        SELECT p.pull_request_id, p.pull_request_name,
+               p.author_id, p.status, p.created_at, p.merged_at, p.team_id, t.team_name
+        FROM pull_requests p
+        JOIN teams t ON t.team_id = p.team_id
+        WHERE p.pull_request_id = $1`
 
 	row := r.pool.QueryRow(ctx, sql, pullRequestID)
 
-	pullRequest := model.PullRequest{}
-	err := row.Scan(
-		&pullRequest.PullRequestID,
-		&pullRequest.PullRequestName,
-		&pullRequest.AuthorID,
-		&pullRequest.Status,
-		&pullRequest.CreatedAt,
-		&pullRequest.MergedAt)
+	pullRequest, err := scanPR(row)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, model.NewError(model.NotFound, "NO SUCH RESOURCE")
@@ -42,28 +39,26 @@ func (r *PullRequestRepository) GetPR(ctx context.Context, pullRequestID string)
 		return nil, err
 	}
 
-	return &pullRequest, nil
+	return pullRequest, nil
 }
 
 func (r *PullRequestRepository) CreatePR(ctx context.Context, pr model.PullRequest) (*model.PullRequest, error) {
 	sql := `
-        INSERT INTO pull_requests(pull_request_id, pull_request_name, 
-                                  author_id, status, created_at, merged_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        ON CONFLICT (pull_request_id) DO NOTHING
-        RETURNING pull_request_id, pull_request_name, 
-                                  author_id, status, created_at, merged_at
+        WITH created AS (
+            INSERT INTO pull_requests(pull_request_id, pull_request_name,
+                                      author_id, status, created_at, merged_at, team_id)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            ON CONFLICT (pull_request_id) DO NOTHING
+            RETURNING pull_request_id, pull_request_name,
+                                      author_id, status, created_at, merged_at, team_id)
+        SELECT c.pull_request_id, c.pull_request_name, c.author_id, c.status, c.created_at, c.merged_at,
+               c.team_id, t.team_name
+        FROM created c
+        JOIN teams t ON t.team_id = c.team_id
         `
 
-	pullRequest := model.PullRequest{}
-	err := r.pool.QueryRow(ctx, sql, pr.PullRequestID, pr.PullRequestName,
-		pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt).Scan(
-		&pullRequest.PullRequestID,
-		&pullRequest.PullRequestName,
-		&pullRequest.AuthorID,
-		&pullRequest.Status,
-		&pullRequest.CreatedAt,
-		&pullRequest.MergedAt)
+	pullRequest, err := scanPR(r.pool.QueryRow(ctx, sql, pr.PullRequestID, pr.PullRequestName,
+		pr.AuthorID, pr.Status, pr.CreatedAt, nullTime(pr.MergedAt), pr.TeamID))
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, model.NewError(model.PrExists, "%s already exists", pr.PullRequestID)
@@ -73,25 +68,20 @@ func (r *PullRequestRepository) CreatePR(ctx context.Context, pr model.PullReque
 		return nil, err
 	}
 
-	return &pullRequest, nil
+	return pullRequest, nil
 }
 
+// MergePR is idempotent, merging an already merged PR keeps the original merged_at
 func (r *PullRequestRepository) MergePR(ctx context.Context, pullRequestID string, status model.PRstatus, time time.Time) (*model.PullRequest, error) {
 	sql := `
-        UPDATE pull_requests
-        SET status = $3, merged_at = $2
-        WHERE pull_request_id = $1
-        RETURNING pull_request_id, pull_request_name, 
-                                  author_id, status, created_at, merged_at`
+        UPDATE pull_requests p
+        SET status = $3, merged_at = COALESCE(p.merged_at, $2)
+        FROM teams t
+        WHERE p.pull_request_id = $1 AND t.team_id = p.team_id
+        RETURNING p.pull_request_id, p.pull_request_name,
+                  p.author_id, p.status, p.created_at, p.merged_at, p.team_id, t.team_name`
 
-	pullRequest := model.PullRequest{}
-	err := r.pool.QueryRow(ctx, sql, pullRequestID, time, status).Scan(
-		&pullRequest.PullRequestID,
-		&pullRequest.PullRequestName,
-		&pullRequest.AuthorID,
-		&pullRequest.Status,
-		&pullRequest.CreatedAt,
-		&pullRequest.MergedAt)
+	pullRequest, err := scanPR(r.pool.QueryRow(ctx, sql, pullRequestID, time, status))
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, model.NewError(model.NotFound, "%s not found", pullRequestID)
@@ -99,7 +89,7 @@ func (r *PullRequestRepository) MergePR(ctx context.Context, pullRequestID strin
 	if err != nil {
 		return nil, err
 	}
-	return &pullRequest, nil
+	return pullRequest, nil
 }
 
 func (r *PullRequestRepository) GetAuthor(ctx context.Context, pullRequestID string) (string, error) {
@@ -123,3 +113,113 @@ func (r *PullRequestRepository) GetAuthor(ctx context.Context, pullRequestID str
 
 	return authorID, nil
 }
+
+// GetOpenPRIDsByAuthor lists open PRs of the author, oldest first
+func (r *PullRequestRepository) GetOpenPRIDsByAuthor(ctx context.Context, authorID string) ([]string, error) {
+	sql := `
+        SELECT pull_request_id FROM pull_requests
+        WHERE author_id = $1 AND status = $2
+        ORDER BY created_at, pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, authorID, string(model.CREATED))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	prIDs := make([]string, 0)
+	var prID string
+	for rows.Next() {
+		if err = rows.Scan(&prID); err != nil {
+			return nil, err
+		}
+		prIDs = append(prIDs, prID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull request rows: %w", err)
+	}
+
+	return prIDs, nil
+}
+
+// StreamPRs calls fn for every matching PR as rows arrive from the database, the result
+// is never held in memory. Empty filters match everything
+func (r *PullRequestRepository) StreamPRs(ctx context.Context, teamName string, authorID string,
+	status model.PRstatus, fn func(*model.PullRequest) error) error {
+	sql := `
+        SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.created_at, p.merged_at,
+               p.team_id, t.team_name,
+               ARRAY(SELECT r.reviewer_id FROM pr_reviewers r
+                     WHERE r.pull_request_id = p.pull_request_id ORDER BY r.reviewer_id)
+        FROM pull_requests p
+        JOIN teams t ON t.team_id = p.team_id
+        WHERE ($1::text = '' OR t.team_name = $1)
+        AND ($2::text = '' OR p.author_id = $2)
+        AND ($3::text = '' OR p.status = $3)
+        ORDER BY p.created_at, p.pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, teamName, authorID, string(status))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		pullRequest := model.PullRequest{}
+		var mergedAt *time.Time
+		err = rows.Scan(&pullRequest.PullRequestID, &pullRequest.PullRequestName, &pullRequest.AuthorID,
+			&pullRequest.Status, &pullRequest.CreatedAt, &mergedAt, &pullRequest.TeamID, &pullRequest.TeamName,
+			&pullRequest.AssignedReviewers)
+		if err != nil {
+			return err
+		}
+		if mergedAt != nil {
+			pullRequest.MergedAt = *mergedAt
+		}
+
+		if err = fn(&pullRequest); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating pull request rows: %w", err)
+	}
+
+	return nil
+}
+
+// merged_at is NULL until the PR is merged
+func scanPR(row pgx.Row) (*model.PullRequest, error) {
+	pullRequest := model.PullRequest{}
+	var mergedAt *time.Time
+
+	err := row.Scan(
+		&pullRequest.PullRequestID,
+		&pullRequest.PullRequestName,
+		&pullRequest.AuthorID,
+		&pullRequest.Status,
+		&pullRequest.CreatedAt,
+		&mergedAt,
+		&pullRequest.TeamID,
+		&pullRequest.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if mergedAt != nil {
+		pullRequest.MergedAt = *mergedAt
+	}
+
+	return &pullRequest, nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
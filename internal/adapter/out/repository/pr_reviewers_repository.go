@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"pr-assignment/internal/model"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -20,11 +21,12 @@ func NewPrReviewersRepository(pool *pgxpool.Pool) *PrReviewersRepository {
 	return &PrReviewersRepository{pool: pool}
 }
 
-func (r *PrReviewersRepository) AddReviewer(ctx context.Context, pullRequestID string, reviewerID string) error {
+func (r *PrReviewersRepository) AddReviewer(ctx context.Context, pullRequestID string, reviewerID string,
+	fromFallback bool) error {
 	sql := `
-         INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2);`
+         INSERT INTO pr_reviewers (pull_request_id, reviewer_id, from_fallback) VALUES ($1, $2, $3);`
 
-	_, err := r.pool.Exec(ctx, sql, pullRequestID, reviewerID)
+	_, err := r.pool.Exec(ctx, sql, pullRequestID, reviewerID, fromFallback)
 	if err != nil {
 		return err
 	}
@@ -33,13 +35,13 @@ func (r *PrReviewersRepository) AddReviewer(ctx context.Context, pullRequestID s
 }
 
 func (r *PrReviewersRepository) ChangeReviewer(ctx context.Context, pullRequestID string, oldReviewerID string,
-	newReviewerID string) error {
+	newReviewerID string, fromFallback bool) error {
 	sql := `
         UPDATE pr_reviewers
-        SET reviewer_id = $2
+        SET reviewer_id = $2, from_fallback = $4, state = 'assigned', assigned_at = now(), responded_at = NULL
         WHERE pull_request_id = $1 AND reviewer_id = $3`
 
-	_, err := r.pool.Exec(ctx, sql, pullRequestID, newReviewerID, oldReviewerID)
+	_, err := r.pool.Exec(ctx, sql, pullRequestID, newReviewerID, oldReviewerID, fromFallback)
 	if err != nil {
 		return err
 	}
@@ -77,6 +79,207 @@ func (r *PrReviewersRepository) GetReviewers(ctx context.Context, pullRequestID
 	return reviewersIDs, nil
 }
 
+// GetPendingReviewers lists reviewers of the PR who have not accepted or reviewed it yet.
+// Reviewers picked from fallback teams are outside the PR's team on purpose and are left out
+func (r *PrReviewersRepository) GetPendingReviewers(ctx context.Context, pullRequestID string) ([]string, error) {
+	sql := `
+        SELECT reviewer_id FROM pr_reviewers
+        WHERE pull_request_id = $1 AND state = $2 AND NOT from_fallback
+        ORDER BY assigned_at, reviewer_id`
+
+	rows, err := r.pool.Query(ctx, sql, pullRequestID, string(model.ASSIGNED))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	reviewerIDs := make([]string, 0)
+	var reviewerID string
+	for rows.Next() {
+		if err = rows.Scan(&reviewerID); err != nil {
+			return nil, err
+		}
+		reviewerIDs = append(reviewerIDs, reviewerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer rows: %w", err)
+	}
+
+	return reviewerIDs, nil
+}
+
+// GetOpenReviewsByUser lists open PRs the user has not reviewed yet, oldest first. AgeHours
+// is left to the caller
+func (r *PrReviewersRepository) GetOpenReviewsByUser(ctx context.Context, userID string) ([]model.DigestItem, error) {
+	sql := `
+        SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.created_at
+        FROM pr_reviewers r
+        JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+        WHERE r.reviewer_id = $1 AND r.state <> $2 AND p.status = $3
+        ORDER BY p.created_at, p.pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, userID, string(model.REVIEWED), string(model.CREATED))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make([]model.DigestItem, 0)
+	for rows.Next() {
+		item := model.DigestItem{}
+		err = rows.Scan(&item.PullRequestID, &item.PullRequestName, &item.AuthorID, &item.Status, &item.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetUnfinishedReviewsOutsideTeams lists open PRs the user has not reviewed yet that are
+// reviewed by teams the user is not a member of. Fallback assignments are not tied to the
+// user's teams and stay
+func (r *PrReviewersRepository) GetUnfinishedReviewsOutsideTeams(ctx context.Context,
+	reviewerID string) ([]string, error) {
+	sql := `
+        SELECT r.pull_request_id FROM pr_reviewers r
+        JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+        WHERE r.reviewer_id = $1 AND r.state <> $2 AND p.status = $3 AND NOT r.from_fallback
+        AND NOT EXISTS (SELECT 1 FROM team_members m WHERE m.user_id = $1 AND m.team_id = p.team_id)
+        ORDER BY p.created_at, p.pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, reviewerID, string(model.REVIEWED), string(model.CREATED))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	prIDs := make([]string, 0)
+	var prID string
+	for rows.Next() {
+		if err = rows.Scan(&prID); err != nil {
+			return nil, err
+		}
+		prIDs = append(prIDs, prID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer rows: %w", err)
+	}
+
+	return prIDs, nil
+}
+
+// SetReviewState moves the assignment to state if it is in one of the from states. respondedAt
+// keeps the first reaction
+func (r *PrReviewersRepository) SetReviewState(ctx context.Context, pullRequestID string, reviewerID string,
+	state model.ReviewState, from []model.ReviewState, respondedAt time.Time) (*model.ReviewAssignment, error) {
+	sql := `
+        UPDATE pr_reviewers
+        SET state = $3, responded_at = COALESCE(responded_at, $4)
+        WHERE pull_request_id = $1 AND reviewer_id = $2 AND state = ANY($5)
+        RETURNING pull_request_id, reviewer_id, state, assigned_at, responded_at`
+
+	fromStates := make([]string, 0, len(from))
+	for _, fromState := range from {
+		fromStates = append(fromStates, string(fromState))
+	}
+
+	assignment := model.ReviewAssignment{}
+	err := r.pool.QueryRow(ctx, sql, pullRequestID, reviewerID, state, respondedAt, fromStates).Scan(
+		&assignment.PullRequestID,
+		&assignment.ReviewerID,
+		&assignment.State,
+		&assignment.AssignedAt,
+		&assignment.RespondedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := r.getReviewState(ctx, pullRequestID, reviewerID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, model.NewError(model.WrongState, "review of %s by %s is already %s", pullRequestID,
+			reviewerID, current)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &assignment, nil
+}
+
+// CheckReviewState fails the same way as SetReviewState when the review is not in one of the from states
+func (r *PrReviewersRepository) CheckReviewState(ctx context.Context, pullRequestID string, reviewerID string,
+	from []model.ReviewState) error {
+	current, err := r.getReviewState(ctx, pullRequestID, reviewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, fromState := range from {
+		if current == fromState {
+			return nil
+		}
+	}
+	return model.NewError(model.WrongState, "review of %s by %s is already %s", pullRequestID, reviewerID, current)
+}
+
+func (r *PrReviewersRepository) getReviewState(ctx context.Context, pullRequestID string,
+	reviewerID string) (model.ReviewState, error) {
+	var current model.ReviewState
+	err := r.pool.QueryRow(ctx, `
+        SELECT state FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`,
+		pullRequestID, reviewerID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", model.NewError(model.NotAssigned, "%s is not assigned to %s", reviewerID, pullRequestID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return current, nil
+}
+
+// reviewers taken from a fallback team of the author's team
+func (r *PrReviewersRepository) GetFallbackReviewers(ctx context.Context, pullRequestID string) ([]string, error) {
+	sql := `
+        SELECT reviewer_id FROM pr_reviewers
+        WHERE pull_request_id = $1 AND from_fallback = true`
+
+	rows, err := r.pool.Query(ctx, sql, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var reviewersIDs []string
+	var reviewerID string
+
+	for rows.Next() {
+		err = rows.Scan(&reviewerID)
+		if err != nil {
+			return nil, err
+		}
+		reviewersIDs = append(reviewersIDs, reviewerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer rows: %w", err)
+	}
+
+	return reviewersIDs, nil
+}
+
 func (r *PrReviewersRepository) GetPRsByUser(ctx context.Context, userID string) ([]string, error) {
 	sql := `
         SELECT pull_request_id FROM pr_reviewers
@@ -113,61 +316,78 @@ func (r *PrReviewersRepository) GetPRsByUser(ctx context.Context, userID string)
 	return prIDs, nil
 }
 
-// user - number of prs where they are reviewer
-func (r *PrReviewersRepository) GetNumberOfReviewsByUser(ctx context.Context) (map[string]int, error) {
+// users with number of prs where they are reviewer passed to fn row by row, empty team name means all teams
+func (r *PrReviewersRepository) StreamNumberOfReviewsByUser(ctx context.Context, teamName string,
+	fn func(model.UserReviewsCount) error) error {
 	sql := `
-          SELECT reviewer_id, COUNT(*) FROM pr_reviewers
-          GROUP BY reviewer_id`
-	rows, err := r.pool.Query(ctx, sql)
+          SELECT u.user_id, u.username, u.team_name, u.is_active, u.role, COUNT(*)
+          FROM pr_reviewers r
+          JOIN users u ON u.user_id = r.reviewer_id
+          JOIN teams t ON t.team_id = u.team_name
+          WHERE $1::text = '' OR t.team_name = $1
+          GROUP BY u.user_id
+          ORDER BY COUNT(*) DESC, u.user_id`
+	rows, err := r.pool.Query(ctx, sql, teamName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	defer rows.Close()
 
-	usersWithReviewCount := make(map[string]int)
-
-	var reviewerID string
-	var count int
 	for rows.Next() {
-		err = rows.Scan(&reviewerID, &count)
+		count := model.UserReviewsCount{}
+		err = rows.Scan(&count.User.UserID, &count.User.Username, &count.User.TeamName,
+			&count.User.IsActive, &count.User.Role, &count.ReviewsCount)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err = fn(count); err != nil {
+			return err
 		}
-		usersWithReviewCount[reviewerID] = count
 	}
-	return usersWithReviewCount, nil
-}
-
-func (r *PrReviewersRepository) GetPrsWithReviewer(ctx context.Context) (map[string]int, error) {
-	sql := `
-          SELECT pull_request_id, COUNT(*) FROM pr_reviewers
-          GROUP BY pull_request_id`
-
-	rows, err := r.pool.Query(ctx, sql)
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, model.NewError(model.NotFound, "PR reviewers not found")
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reviewer rows: %w", err)
 	}
 
+	return nil
+}
+
+// prs with at least one reviewer and number of reviewers passed to fn row by row, empty team name
+// means all teams
+func (r *PrReviewersRepository) StreamPrsWithReviewer(ctx context.Context, teamName string,
+	fn func(model.PrReviewersCount) error) error {
+	sql := `
+          SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, COUNT(*)
+          FROM pr_reviewers r
+          JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+          JOIN teams t ON t.team_id = p.team_id
+          WHERE $1::text = '' OR t.team_name = $1
+          GROUP BY p.pull_request_id
+          ORDER BY p.pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, teamName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	defer rows.Close()
 
-	var prID string
-	var count int
-
-	prsMap := make(map[string]int)
-
 	for rows.Next() {
-		err = rows.Scan(&prID, &count)
+		count := model.PrReviewersCount{}
+		err = rows.Scan(&count.PullRequest.PullRequestID, &count.PullRequest.PullRequestName,
+			&count.PullRequest.AuthorID, &count.PullRequest.Status, &count.Count)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err = fn(count); err != nil {
+			return err
 		}
-		prsMap[prID] = count
 	}
 
-	return prsMap, nil
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reviewer rows: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pr-assignment/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReviewDecisionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewDecisionRepository(pool *pgxpool.Pool) *ReviewDecisionRepository {
+	return &ReviewDecisionRepository{pool: pool}
+}
+
+func (r *ReviewDecisionRepository) AddDecision(ctx context.Context, decision model.ReviewDecision) (*model.ReviewDecision, error) {
+	sql := `
+        INSERT INTO review_decisions (pull_request_id, reviewer_id, decision, decided_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING pull_request_id, reviewer_id, decision, decided_at`
+
+	created := model.ReviewDecision{}
+	err := r.pool.QueryRow(ctx, sql, decision.PullRequestID, decision.ReviewerID, decision.Decision,
+		decision.DecidedAt).Scan(
+		&created.PullRequestID,
+		&created.ReviewerID,
+		&created.Decision,
+		&created.DecidedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding review decision: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetFlowReport computes flow percentiles for PRs created in [from, to), grouped both
+// by the team of the PR and by the author. Empty team name means all teams
+func (r *ReviewDecisionRepository) GetFlowReport(ctx context.Context, from time.Time, to time.Time,
+	teamName string) (*model.FlowReport, error) {
+	sql := `
+        WITH flow AS (
+            SELECT p.author_id, t.team_name,
+                   EXTRACT(EPOCH FROM fr.first_review_at - p.created_at)::float8 AS to_first_review,
+                   EXTRACT(EPOCH FROM ap.approved_at - p.created_at)::float8 AS to_approval,
+                   EXTRACT(EPOCH FROM p.merged_at - p.created_at)::float8 AS to_merge
+            FROM pull_requests p
+            JOIN teams t ON t.team_id = p.team_id
+            LEFT JOIN LATERAL (
+                SELECT MIN(d.decided_at) AS first_review_at FROM review_decisions d
+                WHERE d.pull_request_id = p.pull_request_id) fr ON true
+            LEFT JOIN LATERAL (
+                SELECT MIN(d.decided_at) AS approved_at FROM review_decisions d
+                WHERE d.pull_request_id = p.pull_request_id AND d.decision = 'approved') ap ON true
+            WHERE p.created_at >= $1 AND p.created_at < $2
+            AND ($3::text = '' OR t.team_name = $3)
+        )
+        SELECT GROUPING(team_name) = 0, COALESCE(team_name, ''), COALESCE(author_id, ''), COUNT(*),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY to_first_review),
+               percentile_cont(0.9) WITHIN GROUP (ORDER BY to_first_review),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY to_approval),
+               percentile_cont(0.9) WITHIN GROUP (ORDER BY to_approval),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY to_merge),
+               percentile_cont(0.9) WITHIN GROUP (ORDER BY to_merge)
+        FROM flow
+        GROUP BY GROUPING SETS ((team_name), (author_id))
+        ORDER BY 2, 3`
+
+	rows, err := r.pool.Query(ctx, sql, from, to, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	report := model.FlowReport{Teams: []model.TeamFlowMetrics{}, Users: []model.UserFlowMetrics{}}
+	for rows.Next() {
+		var byTeam bool
+		var team, userID string
+		metrics := model.FlowMetrics{}
+		err = rows.Scan(&byTeam, &team, &userID, &metrics.PullRequests,
+			&metrics.TimeToFirstReview.Median, &metrics.TimeToFirstReview.P90,
+			&metrics.TimeToApproval.Median, &metrics.TimeToApproval.P90,
+			&metrics.TimeToMerge.Median, &metrics.TimeToMerge.P90)
+		if err != nil {
+			return nil, err
+		}
+
+		if byTeam {
+			report.Teams = append(report.Teams, model.TeamFlowMetrics{TeamName: team, FlowMetrics: metrics})
+		} else {
+			report.Users = append(report.Users, model.UserFlowMetrics{UserID: userID, FlowMetrics: metrics})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating flow rows: %w", err)
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReviewDeclineRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewDeclineRepository(pool *pgxpool.Pool) *ReviewDeclineRepository {
+	return &ReviewDeclineRepository{pool: pool}
+}
+
+func (r *ReviewDeclineRepository) AddDecline(ctx context.Context, pullRequestID string, reviewerID string,
+	reason string) error {
+	sql := `
+        INSERT INTO review_declines (pull_request_id, reviewer_id, reason) VALUES ($1, $2, $3)`
+
+	_, err := r.pool.Exec(ctx, sql, pullRequestID, reviewerID, reason)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// users who already declined the PR
+func (r *ReviewDeclineRepository) GetDecliners(ctx context.Context, pullRequestID string) ([]string, error) {
+	sql := `
+        SELECT DISTINCT reviewer_id FROM review_declines
+        WHERE pull_request_id = $1`
+
+	rows, err := r.pool.Query(ctx, sql, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	userIDs := make([]string, 0)
+	var userID string
+	for rows.Next() {
+		err = rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating decline rows: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// users with number of declines passed to fn row by row
+func (r *ReviewDeclineRepository) StreamDeclinesCountByUser(ctx context.Context,
+	fn func(model.UserDeclinesCount) error) error {
+	sql := `
+        SELECT u.user_id, u.username, u.team_name, u.is_active, u.role, COUNT(*)
+        FROM review_declines d
+        JOIN users u ON u.user_id = d.reviewer_id
+        GROUP BY u.user_id
+        ORDER BY COUNT(*) DESC, u.user_id`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		count := model.UserDeclinesCount{}
+		err = rows.Scan(&count.User.UserID, &count.User.Username, &count.User.TeamName,
+			&count.User.IsActive, &count.User.Role, &count.DeclinesCount)
+		if err != nil {
+			return err
+		}
+		if err = fn(count); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating decline rows: %w", err)
+	}
+
+	return nil
+}
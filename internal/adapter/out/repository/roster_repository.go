@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RosterRepository reads and writes teams and users together for bulk imports
+type RosterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRosterRepository(pool *pgxpool.Pool) *RosterRepository {
+	return &RosterRepository{pool: pool}
+}
+
+// GetUsers returns every user with the name of their team in TeamName
+func (r *RosterRepository) GetUsers(ctx context.Context) ([]model.User, error) {
+	sql := `
+        SELECT u.user_id, u.username, t.team_name, u.is_active, u.role
+        FROM users u
+        JOIN teams t ON t.team_id = u.team_name
+        ORDER BY t.team_name, u.user_id`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("error getting users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		user := model.User{}
+		err = rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetTeamNames returns names of all teams
+func (r *RosterRepository) GetTeamNames(ctx context.Context) ([]string, error) {
+	sql := `
+        SELECT team_name FROM teams ORDER BY team_name`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("error getting teams: %w", err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	return names, nil
+}
+
+// Apply creates the teams, upserts the users (TeamName is the team name) and deactivates
+// the given users in one transaction
+func (r *RosterRepository) Apply(ctx context.Context, newTeams []string, users []model.User,
+	deactivateIDs []string) error {
+	insertTeamSQL := `
+        INSERT INTO teams (team_id, team_name) VALUES ($1, $2)`
+	// a user who changes teams gets an audit record of the move
+	upsertUserSQL := `
+        WITH previous AS (
+            SELECT u.user_id, t.team_name FROM users u
+            JOIN teams t ON t.team_id = u.team_name
+            WHERE u.user_id = $1 AND t.team_name <> $3),
+        upserted AS (
+            INSERT INTO users (user_id, username, team_name, is_active, role)
+            SELECT $1, $2, team_id, $4, $5 FROM teams WHERE team_name = $3
+            ON CONFLICT (user_id) DO UPDATE
+            SET username = EXCLUDED.username, team_name = EXCLUDED.team_name,
+                is_active = EXCLUDED.is_active, role = EXCLUDED.role
+            RETURNING user_id),
+        cleared AS (
+            DELETE FROM team_memberships m USING teams t
+            WHERE m.user_id = $1 AND m.team_id = t.team_id AND t.team_name = $3),
+        moved AS (
+            INSERT INTO team_moves (user_id, from_team, to_team, source, review_policy)
+            SELECT p.user_id, p.team_name, $3, $6, $7
+            FROM previous p
+            JOIN upserted ON upserted.user_id = p.user_id)
+        SELECT COUNT(*) FROM upserted`
+	deactivateSQL := `
+        UPDATE users SET is_active = false WHERE user_id = ANY($1)`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, teamName := range newTeams {
+		_, err = tx.Exec(ctx, insertTeamSQL, uuid.New(), teamName)
+		if err != nil {
+			return fmt.Errorf("error adding team %s: %w", teamName, err)
+		}
+	}
+
+	for _, user := range users {
+		var upserted int
+		err = tx.QueryRow(ctx, upsertUserSQL, user.UserID, user.Username, user.TeamName, user.IsActive, user.Role,
+			string(model.MoveRoster), string(model.KeepReviews)).Scan(&upserted)
+		if err != nil {
+			return fmt.Errorf("error importing user %s: %w", user.UserID, err)
+		}
+		if upserted == 0 {
+			return model.NewError(model.NotFound, "team %s of user %s not found", user.TeamName, user.UserID)
+		}
+	}
+
+	if len(deactivateIDs) > 0 {
+		_, err = tx.Exec(ctx, deactivateSQL, deactivateIDs)
+		if err != nil {
+			return fmt.Errorf("error deactivating users: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
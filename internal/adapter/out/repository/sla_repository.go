@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SlaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSlaRepository(pool *pgxpool.Pool) *SlaRepository {
+	return &SlaRepository{pool: pool}
+}
+
+func (r *SlaRepository) SetTeamSla(ctx context.Context, teamID string, sla model.TeamSla) error {
+	sql := `
+        INSERT INTO team_sla (team_id, first_review_hours, policy) VALUES ($1, $2, $3)
+        ON CONFLICT (team_id) DO UPDATE SET first_review_hours = $2, policy = $3`
+
+	_, err := r.pool.Exec(ctx, sql, teamID, sla.FirstReviewHours, sla.Policy)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// nil when the team has no SLA
+func (r *SlaRepository) GetTeamSla(ctx context.Context, teamID string) (*model.TeamSla, error) {
+	sql := `
+        SELECT first_review_hours, policy FROM team_sla
+        WHERE team_id = $1`
+
+	sla := model.TeamSla{}
+	err := r.pool.QueryRow(ctx, sql, teamID).Scan(&sla.FirstReviewHours, &sla.Policy)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sla, nil
+}
+
+// assignments of open PRs nobody acted on for longer than the SLA in calendar hours
+// and not escalated yet. Weekends are filtered out by the caller
+func (r *SlaRepository) GetPendingReviews(ctx context.Context) ([]model.PendingReview, error) {
+	sql := `
+        SELECT r.pull_request_id, r.reviewer_id, s.team_id, r.assigned_at, s.first_review_hours, s.policy
+        FROM pr_reviewers r
+        JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+        JOIN team_sla s ON s.team_id = p.team_id
+        WHERE p.status = 'created'
+        AND r.state = 'assigned'
+        AND r.assigned_at < now() - make_interval(hours => s.first_review_hours)
+        AND NOT EXISTS (
+            SELECT 1 FROM sla_escalations e
+            WHERE e.pull_request_id = r.pull_request_id AND e.reviewer_id = r.reviewer_id)`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	reviews := make([]model.PendingReview, 0)
+	for rows.Next() {
+		review := model.PendingReview{}
+		err = rows.Scan(&review.PullRequestID, &review.ReviewerID, &review.TeamID, &review.AssignedAt,
+			&review.Sla.FirstReviewHours, &review.Sla.Policy)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending review rows: %w", err)
+	}
+
+	return reviews, nil
+}
+
+func (r *SlaRepository) AddEscalation(ctx context.Context, escalation model.SlaEscalation) error {
+	sql := `
+        INSERT INTO sla_escalations (pull_request_id, reviewer_id, team_id, action, new_reviewer_id, escalated_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+        ON CONFLICT (pull_request_id, reviewer_id) DO NOTHING`
+
+	_, err := r.pool.Exec(ctx, sql, escalation.PullRequestID, escalation.ReviewerID, escalation.TeamID,
+		escalation.Action, escalation.NewReviewerID, escalation.EscalatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SlaRepository) GetBreachesByTeam(ctx context.Context) ([]model.TeamSlaBreaches, error) {
+	sql := `
+        SELECT t.team_name, COUNT(*) FROM sla_escalations e
+        JOIN teams t ON t.team_id = e.team_id
+        GROUP BY t.team_name
+        ORDER BY COUNT(*) DESC, t.team_name`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	breaches := make([]model.TeamSlaBreaches, 0)
+	for rows.Next() {
+		breach := model.TeamSlaBreaches{}
+		err = rows.Scan(&breach.TeamName, &breach.Breaches)
+		if err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, breach)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating breach rows: %w", err)
+	}
+
+	return breaches, nil
+}
+
+func (r *SlaRepository) GetBreachesByReviewer(ctx context.Context) ([]model.ReviewerSlaBreaches, error) {
+	sql := `
+        SELECT reviewer_id, COUNT(*) FROM sla_escalations
+        GROUP BY reviewer_id
+        ORDER BY COUNT(*) DESC, reviewer_id`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	breaches := make([]model.ReviewerSlaBreaches, 0)
+	for rows.Next() {
+		breach := model.ReviewerSlaBreaches{}
+		err = rows.Scan(&breach.ReviewerID, &breach.Breaches)
+		if err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, breach)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating breach rows: %w", err)
+	}
+
+	return breaches, nil
+}
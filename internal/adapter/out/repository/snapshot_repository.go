@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// snapshotTables are wiped before a replace, identities restart so history ids begin from 1
+const snapshotTables = `teams, team_fallbacks, team_role_rules, team_sla, users, team_memberships, reviewer_exclusions,
+    pull_requests, pr_reviewers, review_assignments, review_declines, review_decisions, sla_escalations,
+    team_moves`
+
+// SnapshotRepository reads and writes the whole dataset for backups
+type SnapshotRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSnapshotRepository(pool *pgxpool.Pool) *SnapshotRepository {
+	return &SnapshotRepository{pool: pool}
+}
+
+// Export reads every table in one repeatable read transaction, so the snapshot is consistent
+// while requests keep writing
+func (r *SnapshotRepository) Export(ctx context.Context) (*model.Snapshot, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	snapshot := &model.Snapshot{}
+
+	snapshot.Teams, err = collect(ctx, tx, `
+        SELECT team_id, team_name, COALESCE(parent_team_id::text, '') FROM teams ORDER BY team_name, team_id`,
+		func(rows pgx.Rows, t *model.SnapshotTeam) error {
+			return rows.Scan(&t.TeamID, &t.TeamName, &t.ParentTeamID)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting teams: %w", err)
+	}
+
+	snapshot.TeamFallbacks, err = collect(ctx, tx, `
+        SELECT team_id, fallback_team_id, position FROM team_fallbacks ORDER BY team_id, position`,
+		func(rows pgx.Rows, f *model.SnapshotFallback) error {
+			return rows.Scan(&f.TeamID, &f.FallbackTeamID, &f.Position)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting team fallbacks: %w", err)
+	}
+
+	snapshot.TeamRoleRules, err = collect(ctx, tx, `
+        SELECT team_id, role, min_count FROM team_role_rules ORDER BY team_id, role`,
+		func(rows pgx.Rows, rule *model.SnapshotRoleRule) error {
+			return rows.Scan(&rule.TeamID, &rule.Role, &rule.MinCount)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting team role rules: %w", err)
+	}
+
+	snapshot.TeamSla, err = collect(ctx, tx, `
+        SELECT team_id, first_review_hours, policy FROM team_sla ORDER BY team_id`,
+		func(rows pgx.Rows, sla *model.SnapshotTeamSla) error {
+			return rows.Scan(&sla.TeamID, &sla.FirstReviewHours, &sla.Policy)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting team sla: %w", err)
+	}
+
+	snapshot.Users, err = collect(ctx, tx, `
+        SELECT user_id, username, team_name, is_active, role, email, digest_frequency, digest_sent_at
+        FROM users ORDER BY user_id`,
+		func(rows pgx.Rows, u *model.SnapshotUser) error {
+			return rows.Scan(&u.UserID, &u.Username, &u.TeamID, &u.IsActive, &u.Role, &u.Email,
+				&u.DigestFrequency, &u.DigestSentAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting users: %w", err)
+	}
+
+	snapshot.TeamMemberships, err = collect(ctx, tx, `
+        SELECT user_id, team_id, added_at
+        FROM team_memberships ORDER BY user_id, team_id`,
+		func(rows pgx.Rows, m *model.SnapshotMembership) error {
+			return rows.Scan(&m.UserID, &m.TeamID, &m.AddedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting team memberships: %w", err)
+	}
+
+	snapshot.ReviewerExclusions, err = collect(ctx, tx, `
+        SELECT author_id, reviewer_id, is_symmetric, reason, created_at
+        FROM reviewer_exclusions ORDER BY author_id, reviewer_id`,
+		func(rows pgx.Rows, e *model.SnapshotExclusion) error {
+			return rows.Scan(&e.AuthorID, &e.ReviewerID, &e.IsSymmetric, &e.Reason, &e.CreatedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting reviewer exclusions: %w", err)
+	}
+
+	snapshot.PullRequests, err = collect(ctx, tx, `
+        SELECT pull_request_id, pull_request_name, author_id, team_id, status, created_at, merged_at
+        FROM pull_requests ORDER BY created_at, pull_request_id`,
+		func(rows pgx.Rows, pr *model.SnapshotPullRequest) error {
+			return rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.TeamID, &pr.Status,
+				&pr.CreatedAt, &pr.MergedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting pull requests: %w", err)
+	}
+
+	snapshot.Reviewers, err = collect(ctx, tx, `
+        SELECT pull_request_id, reviewer_id, from_fallback, state, assigned_at, responded_at
+        FROM pr_reviewers ORDER BY pull_request_id, assigned_at, reviewer_id`,
+		func(rows pgx.Rows, rv *model.SnapshotReviewer) error {
+			return rows.Scan(&rv.PullRequestID, &rv.ReviewerID, &rv.FromFallback, &rv.State, &rv.AssignedAt,
+				&rv.RespondedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting reviewers: %w", err)
+	}
+
+	snapshot.Assignments, err = collect(ctx, tx, `
+        SELECT pull_request_id, author_id, reviewer_id, assigned_at
+        FROM review_assignments ORDER BY assignment_id`,
+		func(rows pgx.Rows, a *model.SnapshotAssignment) error {
+			return rows.Scan(&a.PullRequestID, &a.AuthorID, &a.ReviewerID, &a.AssignedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting assignment history: %w", err)
+	}
+
+	snapshot.Declines, err = collect(ctx, tx, `
+        SELECT pull_request_id, reviewer_id, reason, declined_at
+        FROM review_declines ORDER BY decline_id`,
+		func(rows pgx.Rows, d *model.SnapshotDecline) error {
+			return rows.Scan(&d.PullRequestID, &d.ReviewerID, &d.Reason, &d.DeclinedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting declines: %w", err)
+	}
+
+	snapshot.Decisions, err = collect(ctx, tx, `
+        SELECT pull_request_id, reviewer_id, decision, decided_at
+        FROM review_decisions ORDER BY decision_id`,
+		func(rows pgx.Rows, d *model.SnapshotDecision) error {
+			return rows.Scan(&d.PullRequestID, &d.ReviewerID, &d.Decision, &d.DecidedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting decisions: %w", err)
+	}
+
+	snapshot.Escalations, err = collect(ctx, tx, `
+        SELECT pull_request_id, reviewer_id, team_id, action, new_reviewer_id, escalated_at
+        FROM sla_escalations ORDER BY escalation_id`,
+		func(rows pgx.Rows, e *model.SnapshotEscalation) error {
+			return rows.Scan(&e.PullRequestID, &e.ReviewerID, &e.TeamID, &e.Action, &e.NewReviewerID,
+				&e.EscalatedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting escalations: %w", err)
+	}
+
+	snapshot.TeamMoves, err = collect(ctx, tx, `
+        SELECT user_id, from_team, to_team, source, review_policy, reevaluate_authored, reassigned_reviews,
+            reassigned_authored, moved_at
+        FROM team_moves ORDER BY move_id`,
+		func(rows pgx.Rows, m *model.SnapshotTeamMove) error {
+			return rows.Scan(&m.UserID, &m.FromTeam, &m.ToTeam, &m.Source, &m.ReviewPolicy, &m.ReevaluateAuthored,
+				&m.ReassignedReviews, &m.ReassignedAuthored, &m.MovedAt)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting team moves: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetTeamIDs maps team names to ids
+func (r *SnapshotRepository) GetTeamIDs(ctx context.Context) (map[string]string, error) {
+	sql := `
+        SELECT team_name, team_id FROM teams`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("error getting teams: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var name, id string
+		if err = rows.Scan(&name, &id); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		ids[name] = id
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Import writes the snapshot in one transaction and counts inserted or changed rows per section.
+// Rows are upserted by their keys and only touched when something differs, history rows are
+// matched by content, so importing the same snapshot twice writes nothing the second time.
+// With replace every table is truncated first. With dryRun the transaction is rolled back
+func (r *SnapshotRepository) Import(ctx context.Context, snapshot *model.Snapshot, replace bool,
+	dryRun bool) (map[string]int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if replace {
+		if _, err = tx.Exec(ctx, `TRUNCATE `+snapshotTables+` RESTART IDENTITY CASCADE`); err != nil {
+			return nil, fmt.Errorf("error truncating tables: %w", err)
+		}
+	}
+
+	sections := []struct {
+		name string
+		sql  string
+		args [][]any
+	}{
+		{"teams", `
+        INSERT INTO teams (team_id, team_name) VALUES ($1, $2)
+        ON CONFLICT (team_id) DO UPDATE SET team_name = EXCLUDED.team_name
+        WHERE teams.team_name IS DISTINCT FROM EXCLUDED.team_name`,
+			rowArgs(snapshot.Teams, func(t model.SnapshotTeam) []any {
+				return []any{t.TeamID, t.TeamName}
+			})},
+		// parents go after all teams exist. Merged into existing teams a parent that would make
+		// a cycle with the hierarchy already there is skipped like in /team/setParent
+		{"team_parents", `
+        WITH RECURSIVE ancestors AS (
+            SELECT team_id, parent_team_id FROM teams WHERE team_id = NULLIF($2::text, '')::uuid
+            UNION
+            SELECT t.team_id, t.parent_team_id FROM teams t
+            JOIN ancestors a ON t.team_id = a.parent_team_id)
+        UPDATE teams SET parent_team_id = NULLIF($2::text, '')::uuid
+        WHERE team_id = $1 AND parent_team_id IS DISTINCT FROM NULLIF($2::text, '')::uuid
+        AND NOT EXISTS (SELECT 1 FROM ancestors WHERE team_id = $1)`,
+			rowArgs(snapshot.Teams, func(t model.SnapshotTeam) []any {
+				return []any{t.TeamID, t.ParentTeamID}
+			})},
+		{"team_fallbacks", `
+        INSERT INTO team_fallbacks (team_id, fallback_team_id, position) VALUES ($1, $2, $3)
+        ON CONFLICT (team_id, fallback_team_id) DO UPDATE SET position = EXCLUDED.position
+        WHERE team_fallbacks.position IS DISTINCT FROM EXCLUDED.position`,
+			rowArgs(snapshot.TeamFallbacks, func(f model.SnapshotFallback) []any {
+				return []any{f.TeamID, f.FallbackTeamID, f.Position}
+			})},
+		{"team_role_rules", `
+        INSERT INTO team_role_rules (team_id, role, min_count) VALUES ($1, $2, $3)
+        ON CONFLICT (team_id, role) DO UPDATE SET min_count = EXCLUDED.min_count
+        WHERE team_role_rules.min_count IS DISTINCT FROM EXCLUDED.min_count`,
+			rowArgs(snapshot.TeamRoleRules, func(rule model.SnapshotRoleRule) []any {
+				return []any{rule.TeamID, rule.Role, rule.MinCount}
+			})},
+		{"team_sla", `
+        INSERT INTO team_sla (team_id, first_review_hours, policy) VALUES ($1, $2, $3)
+        ON CONFLICT (team_id) DO UPDATE SET first_review_hours = EXCLUDED.first_review_hours,
+            policy = EXCLUDED.policy
+        WHERE (team_sla.first_review_hours, team_sla.policy)
+            IS DISTINCT FROM (EXCLUDED.first_review_hours, EXCLUDED.policy)`,
+			rowArgs(snapshot.TeamSla, func(sla model.SnapshotTeamSla) []any {
+				return []any{sla.TeamID, sla.FirstReviewHours, sla.Policy}
+			})},
+		{"users", `
+        INSERT INTO users (user_id, username, team_name, is_active, role, email, digest_frequency, digest_sent_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, team_name = EXCLUDED.team_name,
+            is_active = EXCLUDED.is_active, role = EXCLUDED.role, email = EXCLUDED.email,
+            digest_frequency = EXCLUDED.digest_frequency, digest_sent_at = EXCLUDED.digest_sent_at
+        WHERE (users.username, users.team_name, users.is_active, users.role, users.email,
+            users.digest_frequency, users.digest_sent_at)
+            IS DISTINCT FROM (EXCLUDED.username, EXCLUDED.team_name, EXCLUDED.is_active, EXCLUDED.role,
+            EXCLUDED.email, EXCLUDED.digest_frequency, EXCLUDED.digest_sent_at)`,
+			rowArgs(snapshot.Users, func(u model.SnapshotUser) []any {
+				return []any{u.UserID, u.Username, u.TeamID, u.IsActive, u.Role, u.Email, u.DigestFrequency,
+					u.DigestSentAt}
+			})},
+		{"team_memberships", `
+        INSERT INTO team_memberships (user_id, team_id, added_at) VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, team_id) DO UPDATE SET added_at = EXCLUDED.added_at
+        WHERE team_memberships.added_at IS DISTINCT FROM EXCLUDED.added_at`,
+			rowArgs(snapshot.TeamMemberships, func(m model.SnapshotMembership) []any {
+				return []any{m.UserID, m.TeamID, m.AddedAt}
+			})},
+		{"reviewer_exclusions", `
+        INSERT INTO reviewer_exclusions (author_id, reviewer_id, is_symmetric, reason, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (author_id, reviewer_id) DO UPDATE SET is_symmetric = EXCLUDED.is_symmetric,
+            reason = EXCLUDED.reason, created_at = EXCLUDED.created_at
+        WHERE (reviewer_exclusions.is_symmetric, reviewer_exclusions.reason, reviewer_exclusions.created_at)
+            IS DISTINCT FROM (EXCLUDED.is_symmetric, EXCLUDED.reason, EXCLUDED.created_at)`,
+			rowArgs(snapshot.ReviewerExclusions, func(e model.SnapshotExclusion) []any {
+				return []any{e.AuthorID, e.ReviewerID, e.IsSymmetric, e.Reason, e.CreatedAt}
+			})},
+		{"pull_requests", `
+        INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_id, status, created_at,
+            merged_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (pull_request_id) DO UPDATE SET pull_request_name = EXCLUDED.pull_request_name,
+            author_id = EXCLUDED.author_id, team_id = EXCLUDED.team_id, status = EXCLUDED.status,
+            created_at = EXCLUDED.created_at, merged_at = EXCLUDED.merged_at
+        WHERE (pull_requests.pull_request_name, pull_requests.author_id, pull_requests.team_id,
+            pull_requests.status, pull_requests.created_at, pull_requests.merged_at)
+            IS DISTINCT FROM (EXCLUDED.pull_request_name, EXCLUDED.author_id, EXCLUDED.team_id, EXCLUDED.status,
+            EXCLUDED.created_at, EXCLUDED.merged_at)`,
+			rowArgs(snapshot.PullRequests, func(pr model.SnapshotPullRequest) []any {
+				return []any{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.TeamID, pr.Status, pr.CreatedAt,
+					pr.MergedAt}
+			})},
+		{"reviewers", `
+        INSERT INTO pr_reviewers (pull_request_id, reviewer_id, from_fallback, state, assigned_at, responded_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (pull_request_id, reviewer_id) DO UPDATE SET from_fallback = EXCLUDED.from_fallback,
+            state = EXCLUDED.state, assigned_at = EXCLUDED.assigned_at, responded_at = EXCLUDED.responded_at
+        WHERE (pr_reviewers.from_fallback, pr_reviewers.state, pr_reviewers.assigned_at, pr_reviewers.responded_at)
+            IS DISTINCT FROM (EXCLUDED.from_fallback, EXCLUDED.state, EXCLUDED.assigned_at, EXCLUDED.responded_at)`,
+			rowArgs(snapshot.Reviewers, func(rv model.SnapshotReviewer) []any {
+				return []any{rv.PullRequestID, rv.ReviewerID, rv.FromFallback, rv.State, rv.AssignedAt, rv.RespondedAt}
+			})},
+		{"assignments", `
+        INSERT INTO review_assignments (pull_request_id, author_id, reviewer_id, assigned_at)
+        SELECT $1::varchar, $2::varchar, $3::varchar, $4::timestamptz
+        WHERE NOT EXISTS (
+            SELECT 1 FROM review_assignments
+            WHERE pull_request_id = $1 AND reviewer_id = $3 AND assigned_at = $4)`,
+			rowArgs(snapshot.Assignments, func(a model.SnapshotAssignment) []any {
+				return []any{a.PullRequestID, a.AuthorID, a.ReviewerID, a.AssignedAt}
+			})},
+		{"declines", `
+        INSERT INTO review_declines (pull_request_id, reviewer_id, reason, declined_at)
+        SELECT $1::varchar, $2::varchar, $3::text, $4::timestamptz
+        WHERE NOT EXISTS (
+            SELECT 1 FROM review_declines
+            WHERE pull_request_id = $1 AND reviewer_id = $2 AND declined_at = $4)`,
+			rowArgs(snapshot.Declines, func(d model.SnapshotDecline) []any {
+				return []any{d.PullRequestID, d.ReviewerID, d.Reason, d.DeclinedAt}
+			})},
+		{"decisions", `
+        INSERT INTO review_decisions (pull_request_id, reviewer_id, decision, decided_at)
+        SELECT $1::varchar, $2::varchar, $3::varchar, $4::timestamptz
+        WHERE NOT EXISTS (
+            SELECT 1 FROM review_decisions
+            WHERE pull_request_id = $1 AND reviewer_id = $2 AND decided_at = $4)`,
+			rowArgs(snapshot.Decisions, func(d model.SnapshotDecision) []any {
+				return []any{d.PullRequestID, d.ReviewerID, d.Decision, d.DecidedAt}
+			})},
+		{"escalations", `
+        INSERT INTO sla_escalations (pull_request_id, reviewer_id, team_id, action, new_reviewer_id, escalated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (pull_request_id, reviewer_id) DO UPDATE SET team_id = EXCLUDED.team_id,
+            action = EXCLUDED.action, new_reviewer_id = EXCLUDED.new_reviewer_id,
+            escalated_at = EXCLUDED.escalated_at
+        WHERE (sla_escalations.team_id, sla_escalations.action, sla_escalations.new_reviewer_id,
+            sla_escalations.escalated_at)
+            IS DISTINCT FROM (EXCLUDED.team_id, EXCLUDED.action, EXCLUDED.new_reviewer_id, EXCLUDED.escalated_at)`,
+			rowArgs(snapshot.Escalations, func(e model.SnapshotEscalation) []any {
+				return []any{e.PullRequestID, e.ReviewerID, e.TeamID, e.Action, e.NewReviewerID, e.EscalatedAt}
+			})},
+		{"team_moves", `
+        INSERT INTO team_moves (user_id, from_team, to_team, source, review_policy, reevaluate_authored,
+            reassigned_reviews, reassigned_authored, moved_at)
+        SELECT $1::varchar, $2::varchar, $3::varchar, $4::varchar, $5::varchar, $6::boolean, $7::int, $8::int,
+            $9::timestamptz
+        WHERE NOT EXISTS (
+            SELECT 1 FROM team_moves
+            WHERE user_id = $1 AND to_team = $3 AND moved_at = $9)`,
+			rowArgs(snapshot.TeamMoves, func(m model.SnapshotTeamMove) []any {
+				return []any{m.UserID, m.FromTeam, m.ToTeam, m.Source, m.ReviewPolicy, m.ReevaluateAuthored,
+					m.ReassignedReviews, m.ReassignedAuthored, m.MovedAt}
+			})},
+	}
+
+	written := make(map[string]int, len(sections))
+	for _, section := range sections {
+		count, err := execBatch(ctx, tx, section.sql, section.args)
+		if err != nil {
+			return nil, fmt.Errorf("error importing %s: %w", section.name, err)
+		}
+		written[section.name] = count
+	}
+
+	if dryRun {
+		return written, nil
+	}
+	return written, tx.Commit(ctx)
+}
+
+func collect[T any](ctx context.Context, tx pgx.Tx, sql string, scan func(rows pgx.Rows, item *T) error) ([]T,
+	error) {
+	rows, err := tx.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		var item T
+		if err = scan(rows, &item); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func rowArgs[T any](items []T, args func(T) []any) [][]any {
+	result := make([][]any, 0, len(items))
+	for _, item := range items {
+		result = append(result, args(item))
+	}
+	return result
+}
+
+// execBatch runs the statement once per row in a single round trip and sums affected rows
+func execBatch(ctx context.Context, tx pgx.Tx, sql string, rows [][]any) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, args := range rows {
+		batch.Queue(sql, args...)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	count := 0
+	for range rows {
+		tag, err := results.Exec()
+		if err != nil {
+			results.Close()
+			return 0, err
+		}
+		count += int(tag.RowsAffected())
+	}
+	return count, results.Close()
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-assignment/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TeamMoveRepository moves users between teams and keeps the audit trail of team changes
+type TeamMoveRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTeamMoveRepository(pool *pgxpool.Pool) *TeamMoveRepository {
+	return &TeamMoveRepository{pool: pool}
+}
+
+// MoveUser changes the team of the user and records the move in one statement. The user
+// must still be in fromTeamID, a concurrent move makes it fail
+func (r *TeamMoveRepository) MoveUser(ctx context.Context, userID string, fromTeamID string, toTeamID string,
+	policy model.MoveReviewPolicy, reevaluateAuthored bool) (*model.TeamMove, error) {
+	sql := `
+        WITH moved AS (
+            UPDATE users SET team_name = $3 WHERE user_id = $1 AND team_name = $2
+            RETURNING user_id),
+        promoted AS (
+            DELETE FROM team_memberships WHERE user_id = $1 AND team_id = $3
+            AND EXISTS (SELECT 1 FROM moved))
+        INSERT INTO team_moves (user_id, from_team, to_team, source, review_policy, reevaluate_authored)
+        SELECT m.user_id, f.team_name, t.team_name, $4, $5, $6
+        FROM moved m
+        JOIN teams f ON f.team_id = $2
+        JOIN teams t ON t.team_id = $3
+        RETURNING move_id, user_id, from_team, to_team, source, review_policy, reevaluate_authored,
+            reassigned_reviews, reassigned_authored, moved_at`
+
+	row := r.pool.QueryRow(ctx, sql, userID, fromTeamID, toTeamID, string(model.MoveExplicit), string(policy),
+		reevaluateAuthored)
+	move, err := scanTeamMove(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.NewError(model.BadRequest, "user %s changed teams in the meantime, try again", userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error moving user: %w", err)
+	}
+	return move, nil
+}
+
+// SetReassigned stores how many reviewers the move replaced
+func (r *TeamMoveRepository) SetReassigned(ctx context.Context, moveID int64, reviews int, authored int) error {
+	sql := `
+        UPDATE team_moves SET reassigned_reviews = $2, reassigned_authored = $3 WHERE move_id = $1`
+
+	_, err := r.pool.Exec(ctx, sql, moveID, reviews, authored)
+	if err != nil {
+		return fmt.Errorf("error updating team move: %w", err)
+	}
+	return nil
+}
+
+// GetMoves returns the latest moves first. Empty userID or teamName means any, the team
+// matches both the team left and the team joined
+func (r *TeamMoveRepository) GetMoves(ctx context.Context, userID string, teamName string,
+	limit int) ([]model.TeamMove, error) {
+	sql := `
+        SELECT move_id, user_id, from_team, to_team, source, review_policy, reevaluate_authored,
+            reassigned_reviews, reassigned_authored, moved_at
+        FROM team_moves
+        WHERE ($1::text = '' OR user_id = $1)
+            AND ($2::text = '' OR from_team = $2 OR to_team = $2)
+        ORDER BY moved_at DESC, move_id DESC
+        LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, sql, userID, teamName, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	moves := make([]model.TeamMove, 0)
+	for rows.Next() {
+		move, err := scanTeamMove(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		moves = append(moves, *move)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team move rows: %w", err)
+	}
+
+	return moves, nil
+}
+
+func scanTeamMove(row pgx.Row) (*model.TeamMove, error) {
+	move := model.TeamMove{}
+	err := row.Scan(&move.MoveID, &move.UserID, &move.FromTeam, &move.ToTeam, &move.Source, &move.ReviewPolicy,
+		&move.ReevaluateAuthored, &move.ReassignedReviews, &move.ReassignedAuthored, &move.MovedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &move, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"pr-assignment/internal/model"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,18 +24,16 @@ func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, err
 	sql := `
            SELECT team_name FROM public.teams
            WHERE team_name = $1`
-	fmt.Println("team name " + teamName)
 	var name string
 	queryRow := r.pool.QueryRow(ctx, sql, teamName)
 
 	err := queryRow.Scan(&name)
-	fmt.Println(err)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
 
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 
 	return true, nil
@@ -78,3 +77,580 @@ func (r *TeamRepository) AddTeam(ctx context.Context, newTeam model.Team, teamID
 	}
 	return nil
 }
+
+// fallback teams in order of priority
+func (r *TeamRepository) GetFallbackTeamIDs(ctx context.Context, teamID string) ([]string, error) {
+	sql := `
+           SELECT fallback_team_id FROM team_fallbacks
+           WHERE team_id = $1
+           ORDER BY position`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teamIDs := make([]string, 0)
+	var fallbackID string
+	for rows.Next() {
+		err = rows.Scan(&fallbackID)
+		if err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, fallbackID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fallback rows: %w", err)
+	}
+
+	return teamIDs, nil
+}
+
+func (r *TeamRepository) GetFallbackTeamNames(ctx context.Context, teamID string) ([]string, error) {
+	sql := `
+           SELECT t.team_name FROM team_fallbacks f
+           JOIN teams t ON t.team_id = f.fallback_team_id
+           WHERE f.team_id = $1
+           ORDER BY f.position`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teamNames := make([]string, 0)
+	var teamName string
+	for rows.Next() {
+		err = rows.Scan(&teamName)
+		if err != nil {
+			return nil, err
+		}
+		teamNames = append(teamNames, teamName)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fallback rows: %w", err)
+	}
+
+	return teamNames, nil
+}
+
+// replaces the whole fallback list of the team
+func (r *TeamRepository) SetFallbackTeams(ctx context.Context, teamID string, fallbackIDs []string) error {
+	deleteSQL := `
+           DELETE FROM team_fallbacks WHERE team_id = $1`
+	insertSQL := `
+           INSERT INTO team_fallbacks (team_id, fallback_team_id, position) VALUES ($1, $2, $3)`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, deleteSQL, teamID)
+	if err != nil {
+		return err
+	}
+
+	for position, fallbackID := range fallbackIDs {
+		_, err = tx.Exec(ctx, insertSQL, teamID, fallbackID, position)
+		if err != nil {
+			return fmt.Errorf("error adding fallback team %s: %w", fallbackID, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetParentTeamName returns an empty name for a top level team
+func (r *TeamRepository) GetParentTeamName(ctx context.Context, teamID string) (string, error) {
+	sql := `
+           SELECT COALESCE(p.team_name, '') FROM teams t
+           LEFT JOIN teams p ON p.team_id = t.parent_team_id
+           WHERE t.team_id = $1`
+
+	var parentName string
+	err := r.pool.QueryRow(ctx, sql, teamID).Scan(&parentName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", model.NewError(model.NotFound, "team %s not found", teamID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return parentName, nil
+}
+
+// GetTeamSubtree returns the team and all its sub-teams with members, fallback teams, role rules
+// and SLA in one statement. The team comes first, every sub-team after its parent
+func (r *TeamRepository) GetTeamSubtree(ctx context.Context, teamID string) ([]model.Team, error) {
+	sql := `
+           WITH RECURSIVE subtree AS (
+               SELECT team_id, team_name, parent_team_id, 0 AS depth FROM teams WHERE team_id = $1
+               UNION ALL
+               SELECT t.team_id, t.team_name, t.parent_team_id, s.depth + 1 FROM teams t
+               JOIN subtree s ON t.parent_team_id = s.team_id)
+           SELECT s.team_name, COALESCE(p.team_name, ''),
+                  COALESCE((SELECT json_agg(json_build_object('user_id', u.user_id, 'username', u.username,
+                                'is_active', u.is_active, 'role', u.role) ORDER BY u.user_id)
+                            FROM users u WHERE u.team_name = s.team_id), '[]'),
+                  COALESCE((SELECT json_agg(json_build_object('user_id', u.user_id, 'username', u.username,
+                                'is_active', u.is_active, 'role', u.role) ORDER BY u.user_id)
+                            FROM team_members m JOIN users u ON u.user_id = m.user_id
+                            WHERE m.team_id = s.team_id AND NOT m.is_primary), '[]'),
+                  ARRAY(SELECT ft.team_name FROM team_fallbacks f
+                        JOIN teams ft ON ft.team_id = f.fallback_team_id
+                        WHERE f.team_id = s.team_id ORDER BY f.position),
+                  COALESCE((SELECT json_agg(json_build_object('role', rr.role, 'min_count', rr.min_count)
+                                ORDER BY rr.role)
+                            FROM team_role_rules rr WHERE rr.team_id = s.team_id), '[]'),
+                  sla.first_review_hours, sla.policy
+           FROM subtree s
+           LEFT JOIN teams p ON p.team_id = s.parent_team_id
+           LEFT JOIN team_sla sla ON sla.team_id = s.team_id
+           ORDER BY s.depth, s.team_name`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teams := make([]model.Team, 0)
+	for rows.Next() {
+		team := model.Team{}
+		var slaHours *int
+		var slaPolicy *model.SlaPolicy
+		err = rows.Scan(&team.TeamName, &team.ParentTeam, &team.Members, &team.SecondaryMembers,
+			&team.FallbackTeams, &team.RoleRules, &slaHours, &slaPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if slaHours != nil && slaPolicy != nil {
+			team.Sla = &model.TeamSla{FirstReviewHours: *slaHours, Policy: *slaPolicy}
+		}
+		teams = append(teams, team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sub-team rows: %w", err)
+	}
+
+	if len(teams) == 0 {
+		return nil, model.NewError(model.NotFound, "team %s not found", teamID)
+	}
+	return teams, nil
+}
+
+// SetParentTeam puts the team under the parent, empty parentID makes it a top level team.
+// A parent inside the team's own subtree would make a cycle and is refused in the same
+// statement, so concurrent changes can not build one either. False means refused
+func (r *TeamRepository) SetParentTeam(ctx context.Context, teamID string, parentID string) (bool, error) {
+	sql := `
+           WITH RECURSIVE ancestors AS (
+               SELECT team_id, parent_team_id FROM teams WHERE team_id = NULLIF($2::text, '')::uuid
+               UNION
+               SELECT t.team_id, t.parent_team_id FROM teams t
+               JOIN ancestors a ON t.team_id = a.parent_team_id)
+           UPDATE teams SET parent_team_id = NULLIF($2::text, '')::uuid
+           WHERE team_id = $1
+           AND NOT EXISTS (SELECT 1 FROM ancestors WHERE team_id = $1)`
+
+	tag, err := r.pool.Exec(ctx, sql, teamID, parentID)
+	if err != nil {
+		return false, fmt.Errorf("error setting parent team: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetHierarchyTeams returns the team, its ancestors and the sub-teams of every ancestor, which
+// is all the fallback walk up the hierarchy can reach
+func (r *TeamRepository) GetHierarchyTeams(ctx context.Context, teamID string) ([]model.TeamNode, error) {
+	sql := `
+           WITH RECURSIVE ancestors AS (
+               SELECT team_id, parent_team_id FROM teams WHERE team_id = $1
+               UNION ALL
+               SELECT t.team_id, t.parent_team_id FROM teams t
+               JOIN ancestors a ON t.team_id = a.parent_team_id)
+           SELECT t.team_id, t.team_name, COALESCE(t.parent_team_id::text, '') FROM teams t
+           WHERE t.team_id IN (SELECT team_id FROM ancestors)
+           OR t.parent_team_id IN (SELECT parent_team_id FROM ancestors)`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	nodes := make([]model.TeamNode, 0)
+	for rows.Next() {
+		node := model.TeamNode{}
+		if err = rows.Scan(&node.TeamID, &node.TeamName, &node.ParentID); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hierarchy rows: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// GetAncestorIDs returns the team and every team above it, nearest first
+func (r *TeamRepository) GetAncestorIDs(ctx context.Context, teamID string) ([]string, error) {
+	sql := `
+           WITH RECURSIVE ancestors AS (
+               SELECT team_id, parent_team_id, 0 AS depth FROM teams WHERE team_id = $1
+               UNION ALL
+               SELECT t.team_id, t.parent_team_id, a.depth + 1 FROM teams t
+               JOIN ancestors a ON t.team_id = a.parent_team_id)
+           SELECT team_id FROM ancestors
+           ORDER BY depth`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teamIDs := make([]string, 0)
+	var ancestorID string
+	for rows.Next() {
+		if err = rows.Scan(&ancestorID); err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, ancestorID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hierarchy rows: %w", err)
+	}
+
+	return teamIDs, nil
+}
+
+func (r *TeamRepository) GetRoleRules(ctx context.Context, teamID string) ([]model.RoleRule, error) {
+	sql := `
+           SELECT role, min_count FROM team_role_rules
+           WHERE team_id = $1
+           ORDER BY role`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rules := make([]model.RoleRule, 0)
+	for rows.Next() {
+		rule := model.RoleRule{}
+		err = rows.Scan(&rule.Role, &rule.MinCount)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rule rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// min count 0 removes the rule
+func (r *TeamRepository) SetRoleRule(ctx context.Context, teamID string, rule model.RoleRule) error {
+	if rule.MinCount == 0 {
+		sql := `
+           DELETE FROM team_role_rules WHERE team_id = $1 AND role = $2`
+
+		_, err := r.pool.Exec(ctx, sql, teamID, rule.Role)
+		return err
+	}
+
+	sql := `
+           INSERT INTO team_role_rules (team_id, role, min_count) VALUES ($1, $2, $3)
+           ON CONFLICT (team_id, role) DO UPDATE SET min_count = $3`
+
+	_, err := r.pool.Exec(ctx, sql, teamID, rule.Role, rule.MinCount)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// per team PR and member aggregates, minReviewers is the required count without role rules.
+// Members are primary and secondary ones, with rollup a team also counts PRs and members of its
+// whole subtree, a user in several teams of the subtree once. Empty team name means all teams
+func (r *TeamRepository) GetTeamStats(ctx context.Context, teamName string, minReviewers int,
+	rollup bool) ([]model.TeamStats, error) {
+	sql := `
+        WITH RECURSIVE subtree AS (
+            SELECT team_id AS root_id, team_id FROM teams
+            UNION ALL
+            SELECT st.root_id, c.team_id FROM subtree st
+            JOIN teams c ON c.parent_team_id = st.team_id
+            WHERE $5::bool
+        ), required AS (
+            SELECT t.team_id, GREATEST($2::int, COALESCE(MAX(rr.min_count), 0)) AS required
+            FROM teams t
+            LEFT JOIN team_role_rules rr ON rr.team_id = t.team_id
+            GROUP BY t.team_id
+        ), pr_counts AS (
+            SELECT p.pull_request_id, p.status, p.team_id, COUNT(r.reviewer_id) < rq.required AS under_reviewed,
+                   COUNT(r.reviewer_id) AS reviewers
+            FROM pull_requests p
+            JOIN required rq ON rq.team_id = p.team_id
+            LEFT JOIN pr_reviewers r ON r.pull_request_id = p.pull_request_id
+            GROUP BY p.pull_request_id, rq.required
+        ), members AS (
+            SELECT st.root_id AS team_id,
+                   COUNT(DISTINCT u.user_id) FILTER (WHERE u.is_active) AS active,
+                   COUNT(DISTINCT u.user_id) FILTER (WHERE NOT u.is_active) AS inactive
+            FROM subtree st
+            JOIN team_members tm ON tm.team_id = st.team_id
+            JOIN users u ON u.user_id = tm.user_id
+            GROUP BY st.root_id
+        )
+        SELECT t.team_name, COALESCE(parent.team_name, ''),
+               COUNT(pc.pull_request_id) FILTER (WHERE pc.status = $3),
+               COUNT(pc.pull_request_id) FILTER (WHERE pc.status = $4),
+               COALESCE(AVG(pc.reviewers), 0)::float8,
+               COALESCE(m.active, 0), COALESCE(m.inactive, 0), rq.required,
+               COUNT(pc.pull_request_id) FILTER (WHERE pc.under_reviewed)
+        FROM teams t
+        JOIN required rq ON rq.team_id = t.team_id
+        JOIN subtree st ON st.root_id = t.team_id
+        LEFT JOIN teams parent ON parent.team_id = t.parent_team_id
+        LEFT JOIN members m ON m.team_id = t.team_id
+        LEFT JOIN pr_counts pc ON pc.team_id = st.team_id
+        WHERE $1::text = '' OR t.team_name = $1
+        GROUP BY t.team_id, t.team_name, parent.team_name, m.active, m.inactive, rq.required
+        ORDER BY t.team_name`
+
+	rows, err := r.pool.Query(ctx, sql, teamName, minReviewers, string(model.CREATED), string(model.MERGED),
+		rollup)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	stats := make([]model.TeamStats, 0)
+	for rows.Next() {
+		stat := model.TeamStats{}
+		err = rows.Scan(&stat.TeamName, &stat.ParentTeam, &stat.OpenPRs, &stat.MergedPRs, &stat.AvgReviewers,
+			&stat.ActiveMembers, &stat.InactiveMembers, &stat.RequiredReviewers, &stat.UnderReviewedPRs)
+		if err != nil {
+			return nil, err
+		}
+		if total := stat.OpenPRs + stat.MergedPRs; total > 0 {
+			stat.UnderReviewedShare = float64(stat.UnderReviewedPRs) / float64(total)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ListTeams returns a page of teams whose name contains search, case-insensitive, and the
+// count of all matching teams. Member counts include secondary members
+func (r *TeamRepository) ListTeams(ctx context.Context, search string, limit int,
+	offset int) ([]model.TeamSummary, int, error) {
+	sql := `
+        SELECT t.team_name, COALESCE(p.team_name, ''), COUNT(u.user_id),
+               COUNT(u.user_id) FILTER (WHERE u.is_active), COUNT(*) OVER ()
+        FROM teams t
+        LEFT JOIN teams p ON p.team_id = t.parent_team_id
+        LEFT JOIN team_members tm ON tm.team_id = t.team_id
+        LEFT JOIN users u ON u.user_id = tm.user_id
+        WHERE t.team_name ILIKE '%' || $1 || '%' ESCAPE '\'
+        GROUP BY t.team_id, t.team_name, p.team_name
+        ORDER BY t.team_name
+        LIMIT $2 OFFSET $3`
+
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	rows, err := r.pool.Query(ctx, sql, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	teams := make([]model.TeamSummary, 0)
+	total := 0
+	for rows.Next() {
+		team := model.TeamSummary{}
+		err = rows.Scan(&team.TeamName, &team.ParentTeam, &team.Members, &team.ActiveMembers, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		teams = append(teams, team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	// a page past the end has no rows to carry the total
+	if len(teams) == 0 && offset > 0 {
+		err = r.pool.QueryRow(ctx, `
+        SELECT COUNT(*) FROM teams
+        WHERE team_name ILIKE '%' || $1 || '%' ESCAPE '\'`, pattern).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return teams, total, nil
+}
+
+func (r *TeamRepository) RenameTeam(ctx context.Context, teamID string, newTeamName string) error {
+	sql := `
+           UPDATE teams SET team_name = $2 WHERE team_id = $1`
+
+	tag, err := r.pool.Exec(ctx, sql, teamID, newTeamName)
+	if err != nil {
+		return fmt.Errorf("error renaming team: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return model.NewError(model.NotFound, "team %s not found", teamID)
+	}
+	return nil
+}
+
+// GetOpenPRIDs lists open PRs reviewed by the team
+func (r *TeamRepository) GetOpenPRIDs(ctx context.Context, teamID string) ([]string, error) {
+	sql := `
+           SELECT pull_request_id FROM pull_requests
+           WHERE team_id = $1 AND status = $2
+           ORDER BY created_at, pull_request_id`
+
+	rows, err := r.pool.Query(ctx, sql, teamID, string(model.CREATED))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	prIDs := make([]string, 0)
+	var prID string
+	for rows.Next() {
+		if err = rows.Scan(&prID); err != nil {
+			return nil, err
+		}
+		prIDs = append(prIDs, prID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull request rows: %w", err)
+	}
+
+	return prIDs, nil
+}
+
+// DeleteTeam moves all primary members, the PRs filed in the team and the SLA escalation history
+// to the target team and deletes the team with its fallbacks, role rules, SLA and secondary
+// memberships in one transaction. Sub-teams move up to the parent of the team. Empty target is
+// only allowed for a team without primary members, its escalations are deleted with it and its
+// PRs go to the primary teams of their authors. Moves of members are recorded in the audit
+// trail. Returns moved users
+func (r *TeamRepository) DeleteTeam(ctx context.Context, teamID string, targetTeamID string,
+	reevaluateAuthored bool) ([]string, error) {
+	moveUsersSQL := `
+           WITH moved AS (
+               UPDATE users SET team_name = $2 WHERE team_name = $1
+               RETURNING user_id),
+           audited AS (
+               INSERT INTO team_moves (user_id, from_team, to_team, source, review_policy, reevaluate_authored)
+               SELECT m.user_id, f.team_name, t.team_name, $3, $4, $5
+               FROM moved m
+               JOIN teams f ON f.team_id = $1
+               JOIN teams t ON t.team_id = $2),
+           promoted AS (
+               DELETE FROM team_memberships m USING moved
+               WHERE m.user_id = moved.user_id AND m.team_id = $2)
+           SELECT user_id FROM moved`
+	movePRsSQL := `
+           UPDATE pull_requests p SET team_id = COALESCE(NULLIF($2::text, '')::uuid, u.team_name)
+           FROM users u
+           WHERE u.user_id = p.author_id AND p.team_id = $1`
+	reparentSQL := `
+           UPDATE teams c SET parent_team_id = t.parent_team_id
+           FROM teams t
+           WHERE t.team_id = $1 AND c.parent_team_id = $1`
+	moveEscalationsSQL := `
+           UPDATE sla_escalations SET team_id = $2 WHERE team_id = $1`
+	// users of a team are deleted with it by the foreign key, a member added in the meantime
+	// must stop the delete instead
+	deleteSQL := `
+           DELETE FROM teams WHERE team_id = $1
+           AND NOT EXISTS (SELECT 1 FROM users WHERE team_name = $1)`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	moved := make([]string, 0)
+	if targetTeamID != "" {
+		rows, err := tx.Query(ctx, moveUsersSQL, teamID, targetTeamID, string(model.MoveTeamDelete),
+			string(model.KeepReviews), reevaluateAuthored)
+		if err != nil {
+			return nil, fmt.Errorf("error moving members: %w", err)
+		}
+		var userID string
+		for rows.Next() {
+			if err = rows.Scan(&userID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			moved = append(moved, userID)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("error moving members: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, moveEscalationsSQL, teamID, targetTeamID); err != nil {
+			return nil, fmt.Errorf("error moving escalations: %w", err)
+		}
+	}
+
+	if _, err = tx.Exec(ctx, movePRsSQL, teamID, targetTeamID); err != nil {
+		return nil, fmt.Errorf("error moving pull requests: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, reparentSQL, teamID); err != nil {
+		return nil, fmt.Errorf("error moving sub-teams: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, deleteSQL, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting team: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, model.NewError(model.BadRequest, "team %s not found or still has members", teamID)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
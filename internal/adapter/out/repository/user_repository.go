@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"pr-assignment/internal/model"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -24,13 +25,13 @@ func (r *UserRepository) UpdateUserStatus(ctx context.Context, userID string, ne
         UPDATE users
         SET is_active = $1
         WHERE user_id = $2
-        RETURNING user_id, username, team_name, is_active
+        RETURNING user_id, username, team_name, is_active, role
     `
 
 	row := r.pool.QueryRow(ctx, sql, newStatus, userID)
 
 	user := model.User{}
-	err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, model.NewError(model.NotFound, "user not found %s", userID)
@@ -43,33 +44,72 @@ func (r *UserRepository) UpdateUserStatus(ctx context.Context, userID string, ne
 	return &user, nil
 }
 
+// AddTeam inserts the members of a new team. Users that already have a primary team are
+// never moved here, that is the job of /team/moveMember
 func (r *UserRepository) AddTeam(ctx context.Context, newTeam model.Team, teamID uuid.UUID) error {
 	sql := `
-        INSERT INTO users(user_id, username, team_name, is_active)
-        VALUES ($1, $2, $3, $4)
-        ON CONFLICT (user_id) DO UPDATE SET team_name = $3
+        INSERT INTO users(user_id, username, team_name, is_active, role)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (user_id) DO NOTHING
         `
 
 	for _, member := range newTeam.Members {
-		_, err := r.pool.Exec(ctx, sql, member.UserID, member.Username,
-			teamID, member.IsActive)
+		role := member.Role
+		if role == "" {
+			role = model.MEMBER
+		}
+
+		tag, err := r.pool.Exec(ctx, sql, member.UserID, member.Username, teamID, member.IsActive, role)
 
 		if err != nil {
 			return fmt.Errorf("error adding team on user: %s %w", member.UserID, err)
 		}
+		if tag.RowsAffected() == 0 {
+			return model.NewError(model.BadRequest,
+				"%s already has a primary team, use /team/moveMember to move the user", member.UserID)
+		}
 
 	}
 	return nil
 }
 
+// GetPrimaryTeams maps the given users that exist to the names of their primary teams
+func (r *UserRepository) GetPrimaryTeams(ctx context.Context, userIDs []string) (map[string]string, error) {
+	sql := `
+        SELECT u.user_id, t.team_name FROM users u
+        JOIN teams t ON t.team_id = u.team_name
+        WHERE u.user_id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, sql, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teams := make(map[string]string)
+	var userID, teamName string
+	for rows.Next() {
+		if err = rows.Scan(&userID, &teamName); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		teams[userID] = teamName
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return teams, nil
+}
+
 func (r *UserRepository) GetTeam(ctx context.Context, teamID string) (*model.Team, error) {
 	sql := `
-        SELECT * FROM users WHERE team_name = $1`
+        SELECT user_id, username, team_name, is_active, role FROM users WHERE team_name = $1`
 
 	rows, err := r.pool.Query(ctx, sql, teamID)
 
 	if err != nil {
-		fmt.Println("error getting team userrepo")
 		return nil, model.NewError(model.NotFound, "team not found %s", teamID)
 	}
 
@@ -86,7 +126,8 @@ func (r *UserRepository) GetTeam(ctx context.Context, teamID string) (*model.Tea
 			&teamMember.UserID,
 			&teamMember.Username,
 			&teamID,
-			&teamMember.IsActive)
+			&teamMember.IsActive,
+			&teamMember.Role)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row: %w", err)
 		}
@@ -155,10 +196,10 @@ func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamID string
 
 func (r *UserRepository) GetUserByID(ctx context.Context, userID string) (*model.User, error) {
 	sql := `
-           SELECT * FROM users WHERE user_id = $1`
+           SELECT user_id, username, team_name, is_active, role FROM users WHERE user_id = $1`
 	row := r.pool.QueryRow(ctx, sql, userID)
 	user := model.User{}
-	err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, model.NewError(model.NotFound, "user not found %s", userID)
 	}
@@ -167,3 +208,272 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID string) (*model
 	}
 	return &user, nil
 }
+
+// GetActiveMembersByTeam returns active users of the team, secondary members included
+func (r *UserRepository) GetActiveMembersByTeam(ctx context.Context, teamID string) ([]model.TeamMember, error) {
+	sql := `
+        SELECT u.user_id, u.username, u.is_active, u.role FROM team_members m
+        JOIN users u ON u.user_id = m.user_id
+        WHERE m.team_id = $1
+        AND u.is_active = true`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := make([]model.TeamMember, 0)
+	for rows.Next() {
+		member := model.TeamMember{}
+		err = rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	sql := `
+        UPDATE users
+        SET role = $1
+        WHERE user_id = $2
+        RETURNING user_id, username, team_name, is_active, role
+    `
+
+	row := r.pool.QueryRow(ctx, sql, role, userID)
+
+	user := model.User{}
+	err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.NewError(model.NotFound, "user not found %s", userID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error updating user role: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) UpdateDigestSettings(ctx context.Context, settings model.DigestSettings) (*model.DigestSettings, error) {
+	sql := `
+        UPDATE users
+        SET email = $2, digest_frequency = $3
+        WHERE user_id = $1
+        RETURNING user_id, email, digest_frequency
+    `
+
+	updated := model.DigestSettings{}
+	err := r.pool.QueryRow(ctx, sql, settings.UserID, settings.Email, settings.Frequency).Scan(
+		&updated.UserID, &updated.Email, &updated.Frequency)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.NewError(model.NotFound, "user not found %s", settings.UserID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error updating digest settings: %w", err)
+	}
+
+	return &updated, nil
+}
+
+// active users whose last digest is older than their frequency
+func (r *UserRepository) GetUsersDueForDigest(ctx context.Context) ([]model.ReviewDigest, error) {
+	sql := `
+        SELECT user_id, username, email FROM users
+        WHERE is_active = true
+        AND (
+            (digest_frequency = 'daily' AND (digest_sent_at IS NULL OR digest_sent_at <= now() - interval '1 day'))
+            OR (digest_frequency = 'weekly' AND (digest_sent_at IS NULL OR digest_sent_at <= now() - interval '7 days'))
+        )`
+
+	rows, err := r.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	digests := make([]model.ReviewDigest, 0)
+	for rows.Next() {
+		digest := model.ReviewDigest{}
+		err = rows.Scan(&digest.UserID, &digest.Username, &digest.Email)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return digests, nil
+}
+
+func (r *UserRepository) MarkDigestSent(ctx context.Context, userID string, sentAt time.Time) error {
+	sql := `
+        UPDATE users
+        SET digest_sent_at = $2
+        WHERE user_id = $1`
+
+	_, err := r.pool.Exec(ctx, sql, userID, sentAt)
+	if err != nil {
+		return fmt.Errorf("error marking digest sent: %w", err)
+	}
+	return nil
+}
+
+// AddMember creates the user in the team, false when the user already exists in any team
+func (r *UserRepository) AddMember(ctx context.Context, teamID string, member model.TeamMember) (bool, error) {
+	sql := `
+        INSERT INTO users(user_id, username, team_name, is_active, role)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (user_id) DO NOTHING`
+
+	tag, err := r.pool.Exec(ctx, sql, member.UserID, member.Username, teamID, member.IsActive, member.Role)
+	if err != nil {
+		return false, fmt.Errorf("error adding member %s: %w", member.UserID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteUnusedUser deletes the user when nothing references them, false when the user has
+// authored PRs, reviews or any history and has to stay
+func (r *UserRepository) DeleteUnusedUser(ctx context.Context, userID string) (bool, error) {
+	sql := `
+        DELETE FROM users u
+        WHERE u.user_id = $1
+        AND NOT EXISTS (SELECT 1 FROM pull_requests WHERE author_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM pr_reviewers WHERE reviewer_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM review_assignments WHERE reviewer_id = u.user_id OR author_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM review_declines WHERE reviewer_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM review_decisions WHERE reviewer_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM sla_escalations WHERE reviewer_id = u.user_id OR new_reviewer_id = u.user_id)
+        AND NOT EXISTS (SELECT 1 FROM team_moves WHERE user_id = u.user_id)`
+
+	tag, err := r.pool.Exec(ctx, sql, userID)
+	if err != nil {
+		return false, fmt.Errorf("error deleting user %s: %w", userID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetSecondaryMembers returns users who are in the team besides their primary team
+func (r *UserRepository) GetSecondaryMembers(ctx context.Context, teamID string) ([]model.TeamMember, error) {
+	sql := `
+        SELECT u.user_id, u.username, u.is_active, u.role FROM team_members m
+        JOIN users u ON u.user_id = m.user_id
+        WHERE m.team_id = $1 AND NOT m.is_primary
+        ORDER BY u.user_id`
+
+	rows, err := r.pool.Query(ctx, sql, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := make([]model.TeamMember, 0)
+	for rows.Next() {
+		member := model.TeamMember{}
+		err = rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetUserTeams returns names of all teams of the user, the primary team first
+func (r *UserRepository) GetUserTeams(ctx context.Context, userID string) (*model.UserTeams, error) {
+	sql := `
+        SELECT t.team_name, m.is_primary FROM team_members m
+        JOIN teams t ON t.team_id = m.team_id
+        WHERE m.user_id = $1
+        ORDER BY m.is_primary DESC, t.team_name`
+
+	rows, err := r.pool.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	teams := model.UserTeams{UserID: userID, Teams: make([]string, 0)}
+	for rows.Next() {
+		var teamName string
+		var isPrimary bool
+		if err = rows.Scan(&teamName, &isPrimary); err != nil {
+			return nil, err
+		}
+		if isPrimary {
+			teams.PrimaryTeam = teamName
+		}
+		teams.Teams = append(teams.Teams, teamName)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	if len(teams.Teams) == 0 {
+		return nil, model.NewError(model.NotFound, "user %s not found", userID)
+	}
+	return &teams, nil
+}
+
+// IsMember tells whether the team is the primary or a secondary team of the user
+func (r *UserRepository) IsMember(ctx context.Context, userID string, teamID string) (bool, error) {
+	sql := `
+        SELECT EXISTS (SELECT 1 FROM team_members WHERE user_id = $1 AND team_id = $2)`
+
+	var isMember bool
+	if err := r.pool.QueryRow(ctx, sql, userID, teamID).Scan(&isMember); err != nil {
+		return false, fmt.Errorf("error checking membership: %w", err)
+	}
+	return isMember, nil
+}
+
+// AddMembership adds a secondary team to the user, false when the user is already in it
+func (r *UserRepository) AddMembership(ctx context.Context, userID string, teamID string) (bool, error) {
+	sql := `
+        INSERT INTO team_memberships (user_id, team_id)
+        SELECT user_id, $2 FROM users WHERE user_id = $1 AND team_name <> $2
+        ON CONFLICT (user_id, team_id) DO NOTHING`
+
+	tag, err := r.pool.Exec(ctx, sql, userID, teamID)
+	if err != nil {
+		return false, fmt.Errorf("error adding membership of %s: %w", userID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RemoveMembership drops a secondary team of the user, false when the user was not in it
+func (r *UserRepository) RemoveMembership(ctx context.Context, userID string, teamID string) (bool, error) {
+	sql := `
+        DELETE FROM team_memberships WHERE user_id = $1 AND team_id = $2`
+
+	tag, err := r.pool.Exec(ctx, sql, userID, teamID)
+	if err != nil {
+		return false, fmt.Errorf("error removing membership of %s: %w", userID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -1,47 +1,154 @@
 package app
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"pr-assignment/internal/adapter/in/http/handler"
+	"pr-assignment/internal/adapter/in/http/middleware"
+	"pr-assignment/internal/app/config/env"
+	"pr-assignment/internal/metrics"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	prHandler   *handler.PullRequestHandler
-	userHandler *handler.UserHandler
-	statHandler *handler.StatHandler
+	prHandler        *handler.PullRequestHandler
+	userHandler      *handler.UserHandler
+	statHandler      *handler.StatHandler
+	exclusionHandler *handler.ExclusionHandler
+	slaHandler       *handler.SlaHandler
+	healthHandler    *handler.HealthHandler
+	rosterHandler    *handler.RosterHandler
+	snapshotHandler  *handler.SnapshotHandler
+	teamHandler      *handler.TeamHandler
+	metrics          *metrics.Metrics
+	config           env.ConfigServer
+	features         env.ConfigFeatures
+	logger           *slog.Logger
 }
 
-func NewServer(prHandler *handler.PullRequestHandler, userHandler *handler.UserHandler, statHandler *handler.StatHandler) *Server {
-	return &Server{prHandler: prHandler, userHandler: userHandler, statHandler: statHandler}
+func NewServer(prHandler *handler.PullRequestHandler, userHandler *handler.UserHandler, statHandler *handler.StatHandler,
+	exclusionHandler *handler.ExclusionHandler, slaHandler *handler.SlaHandler, healthHandler *handler.HealthHandler,
+	rosterHandler *handler.RosterHandler, snapshotHandler *handler.SnapshotHandler, teamHandler *handler.TeamHandler,
+	metrics *metrics.Metrics, config env.ConfigServer, features env.ConfigFeatures, logger *slog.Logger) *Server {
+	return &Server{prHandler: prHandler, userHandler: userHandler, statHandler: statHandler,
+		exclusionHandler: exclusionHandler, slaHandler: slaHandler, healthHandler: healthHandler,
+		rosterHandler: rosterHandler, snapshotHandler: snapshotHandler, teamHandler: teamHandler, metrics: metrics,
+		config: config, features: features, logger: logger}
 }
 
-func (s *Server) RunServer() error {
-	router := gin.Default()
+// RunServer serves until ctx is cancelled, then stops accepting connections and waits up to
+// the shutdown timeout for in-flight requests
+func (s *Server) RunServer(ctx context.Context) error {
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	// the span has to be in the request context before AccessLog runs to get trace_id into its line
+	router.Use(otelgin.Middleware("pr-assignment", otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	router.Use(middleware.AccessLog(s.logger))
+	// outside Recovery, so a panicking request is still counted with the 500 Recovery writes
+	router.Use(s.metrics.Middleware())
 	router.Use(gin.Recovery())
 
+	router.GET("/healthz", s.healthHandler.Healthz)
+	router.GET("/readyz", s.healthHandler.Readyz)
+
+	if s.features.Metrics {
+		router.GET("/metrics", gin.WrapH(s.metrics.Handler()))
+	}
+
+	router.GET("/teams", s.teamHandler.ListTeams)
 	router.GET("/team/get", s.userHandler.GetTeam)
 	router.POST("/team/add", s.userHandler.AddTeam)
 	router.POST("/team/kill", s.userHandler.KillTeam)
+	router.POST("/team/rename", s.teamHandler.RenameTeam)
+	router.POST("/team/delete", s.teamHandler.DeleteTeam)
+	router.POST("/team/addMember", s.teamHandler.AddMember)
+	router.POST("/team/removeMember", s.teamHandler.RemoveMember)
+	router.POST("/team/moveMember", s.teamHandler.MoveMember)
+	router.GET("/team/moves", s.teamHandler.GetMoves)
+	router.POST("/team/setFallbacks", s.userHandler.SetFallbackTeams)
+	router.POST("/team/setParent", s.teamHandler.SetParentTeam)
+	router.POST("/team/setRoleRule", s.userHandler.SetRoleRule)
+	router.POST("/team/setSla", s.slaHandler.SetTeamSla)
+	router.POST("/team/import", s.rosterHandler.ImportRoster)
+	router.POST("/team/sync", s.rosterHandler.SyncDirectory)
 
 	router.POST("/users/setIsActive", s.userHandler.SetIsUserActive)
+	router.POST("/users/setRole", s.userHandler.SetUserRole)
+	router.POST("/users/setDigest", s.userHandler.SetDigest)
 	router.GET("/users/getReview", s.userHandler.GetReviews)
+	router.GET("/users/getTeams", s.teamHandler.GetUserTeams)
+
+	router.POST("/exclusions/add", s.exclusionHandler.AddExclusion)
+	router.POST("/exclusions/remove", s.exclusionHandler.RemoveExclusion)
+	router.GET("/exclusions/get", s.exclusionHandler.GetExclusions)
 
 	router.POST("/pullRequest/create", s.prHandler.CreatePullRequest)
 	router.POST("/pullRequest/merge", s.prHandler.MergePullRequest)
 	router.POST("/pullRequest/reassign", s.prHandler.ReassignPullRequest)
+	router.POST("/pullRequest/accept", s.prHandler.AcceptReview)
+	router.POST("/pullRequest/decline", s.prHandler.DeclineReview)
+	router.POST("/pullRequest/review", s.prHandler.SubmitReview)
+	router.GET("/pullRequest/list", s.prHandler.ListPullRequests)
 
 	router.GET("/stat/pull_request/reviewers", s.statHandler.GetReviewersCountedByPR)
 	router.GET("/stat/users/reviews", s.statHandler.GetReviewsCountedByUser)
+	router.GET("/stat/pairings", s.statHandler.GetPairingMatrix)
+	router.GET("/stat/users/declines", s.statHandler.GetDeclinesCountedByUser)
+	router.GET("/stat/sla", s.slaHandler.GetSlaReport)
+	router.GET("/stat/flow", s.statHandler.GetFlowReport)
+	router.GET("/stat/teams", s.statHandler.GetTeamStats)
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if s.features.Snapshots {
+		router.GET("/snapshot/export", s.snapshotHandler.ExportSnapshot)
+		router.POST("/snapshot/import", s.snapshotHandler.ImportSnapshot)
+	}
+
+	if s.features.Swagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	server := &http.Server{
+		Addr:              s.config.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("starting server", "addr", s.config.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("shutting down server", "timeout", s.config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
 
-	err := router.Run(":8080")
-	if err != nil {
-		fmt.Println(err)
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 	return nil
@@ -2,29 +2,32 @@ package db
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"pr-assignment/internal/app/config/env"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres" // to connect to db
-	_ "github.com/golang-migrate/migrate/v4/source/file"       // to run migrations
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type DB struct {
-	Pool *pgxpool.Pool
-	DSN  string
+	Pool   *pgxpool.Pool
+	DSN    string
+	config env.ConfigDb
+	// schema version the binary expects, checked by Ready
+	migrationVersion uint
+	logger           *slog.Logger
 }
 
-func InitDatabase(ctx context.Context, config env.ConfigDb) (*DB, error) {
-	dsn := fmt.Sprintf("postgres://%s:%s@db:5432/%s?sslmode=disable", config.DbUsername,
-		config.DbPassword, config.DbName)
-	log.Printf("dsn=%s", dsn)
-	db := &DB{DSN: dsn}
+func InitDatabase(ctx context.Context, config env.ConfigDb, logger *slog.Logger) (*DB, error) {
+	db := &DB{DSN: config.DSN(), config: config, logger: logger}
 
-	err := db.connectDB(ctx)
+	version, err := LatestMigration()
+	if err != nil {
+		return nil, err
+	}
+	db.migrationVersion = version
+
+	err = db.connectDB(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -34,32 +37,27 @@ func InitDatabase(ctx context.Context, config env.ConfigDb) (*DB, error) {
 
 func (d *DB) connectDB(ctx context.Context) error {
 
-	var err error
-	d.Pool, err = pgxpool.New(ctx, d.DSN)
-
+	config, err := pgxpool.ParseConfig(d.DSN)
 	if err != nil {
-		return fmt.Errorf("unable to connect to database: %v", err)
-	}
-
-	if err := d.Pool.Ping(ctx); err != nil {
-		return fmt.Errorf("unable to ping database: %v", err)
+		return fmt.Errorf("unable to parse database config: %v", err)
 	}
+	config.ConnConfig.Tracer = queryTracer{}
+	config.ConnConfig.ConnectTimeout = d.config.ConnectTimeout
+	config.MaxConns = d.config.MaxConns
+	config.MinConns = d.config.MinConns
+	config.MaxConnLifetime = d.config.MaxConnLifetime
+	config.MaxConnIdleTime = d.config.MaxConnIdleTime
 
-	fmt.Println("Connected to PostgreSQL database!")
-	return nil
-}
-
-func (d *DB) RunMigrations() error {
+	d.Pool, err = pgxpool.NewWithConfig(ctx, config)
 
-	m, err := migrate.New("file://database/migrations", d.DSN)
 	if err != nil {
-		return fmt.Errorf("unable to run migrations: %v", err)
+		return fmt.Errorf("unable to connect to database: %v", err)
 	}
 
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("failed to apply migrations: %v", err)
+	if err := d.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("unable to ping database: %v", err)
 	}
 
-	fmt.Println("Migrations applied successfully!")
+	d.logger.InfoContext(ctx, "connected to database", "database", d.Pool.Config().ConnConfig.Database)
 	return nil
 }
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ready pings the pool and checks that the schema is at least at the version this binary
+// ships with. Newer schemas are accepted so old replicas stay ready during rollouts
+func (d *DB) Ready(ctx context.Context) error {
+	if err := d.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unreachable: %w", err)
+	}
+
+	var version uint
+	var dirty bool
+	err := d.Pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("migrations are not applied, want version %d", d.migrationVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read migration version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < d.migrationVersion {
+		return fmt.Errorf("migration version is %d, want %d", version, d.migrationVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"pr-assignment/database"
+	"pr-assignment/internal/app/config/env"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // to connect to db
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the migrations embedded in the binary
+type Migrator struct {
+	m      *migrate.Migrate
+	logger *slog.Logger
+}
+
+func NewMigrator(config env.ConfigDb, logger *slog.Logger) (*Migrator, error) {
+	src, err := iofs.New(database.Migrations, database.MigrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, config.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("unable to init migrations: %w", err)
+	}
+	m.Log = migrateLogger{logger: logger}
+
+	return &Migrator{m: m, logger: logger}, nil
+}
+
+// Up applies all pending migrations, nothing to apply is not an error
+func (m *Migrator) Up() error {
+	err := m.m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		m.logger.Info("migrations are up to date")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	m.logger.Info("migrations applied")
+	return nil
+}
+
+// Down rolls back the given number of migrations
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	err := m.m.Steps(-steps)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	m.logger.Info("migrations rolled back", "steps", steps)
+	return nil
+}
+
+// Version returns the applied version, 0 when nothing is applied yet
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Force sets the version without running migrations, used to recover from a dirty state
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("unable to force version %d: %w", version, err)
+	}
+
+	m.logger.Info("migration version forced", "version", version)
+	return nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// LatestMigration is the highest version embedded in the binary
+func LatestMigration() (uint, error) {
+	src, err := iofs.New(database.Migrations, database.MigrationsDir)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read migrations: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to read migrations: %w", err)
+		}
+		version = next
+	}
+}
+
+// migrateLogger sends golang-migrate output to slog
+type migrateLogger struct {
+	logger *slog.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...any) {
+	l.logger.Debug(strings.TrimSpace(fmt.Sprintf(format, v...)), "component", "migrate")
+}
+
+func (l migrateLogger) Verbose() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelDebug)
+}
@@ -2,29 +2,232 @@ package env
 
 import (
 	"fmt"
-
-	"github.com/caarlos0/env/v6"
-	"github.com/joho/godotenv"
+	"log/slog"
+	"net"
+	"net/url"
+	"pr-assignment/internal/model"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Config is the whole runtime configuration. Values come from Defaults, then the optional
+// YAML file, then the optional .env file and then the environment, later sources win
+type Config struct {
+	Server     ConfigServer     `yaml:"server"`
+	Db         ConfigDb         `yaml:"db"`
+	Log        ConfigLog        `yaml:"log"`
+	Assignment ConfigAssignment `yaml:"assignment"`
+	Scheduler  ConfigScheduler  `yaml:"scheduler"`
+	Notifier   ConfigNotifier   `yaml:"notifier"`
+	Tracing    ConfigTracing    `yaml:"tracing"`
+	Features   ConfigFeatures   `yaml:"features"`
+	Directory  ConfigDirectory  `yaml:"directory"`
+}
+
+type ConfigServer struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	// 0 disables the limit, long exports may need it
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	// how long in-flight requests may run after SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
 type ConfigDb struct {
-	DbUsername string `env:"DB_USERNAME,required"`
-	DbPassword string `env:"DB_PASSWORD,required"`
-	DbName     string `env:"DB_NAME,required"`
+	Host       string `yaml:"host" env:"DB_HOST"`
+	Port       int    `yaml:"port" env:"DB_PORT"`
+	DbUsername string `yaml:"username" env:"DB_USERNAME"`
+	DbPassword string `yaml:"password" env:"DB_PASSWORD"`
+	DbName     string `yaml:"name" env:"DB_NAME"`
+	// disable, require, verify-ca or verify-full
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	MaxConns        int32         `yaml:"max_conns" env:"DB_MAX_CONNS"`
+	MinConns        int32         `yaml:"min_conns" env:"DB_MIN_CONNS"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	// apply pending migrations before the server starts
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+type ConfigLog struct {
+	// debug, info, warn or error
+	Level slog.Level `yaml:"level" env:"LOG_LEVEL"`
+	// json or text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type ConfigAssignment struct {
+	Strategy      model.AssignmentStrategy `yaml:"strategy" env:"ASSIGNMENT_STRATEGY"`
+	PairingWindow time.Duration            `yaml:"pairing_window" env:"ASSIGNMENT_PAIRING_WINDOW"`
+	// reviewers per PR when team role rules do not ask for more
+	RequiredReviewers int `yaml:"required_reviewers" env:"ASSIGNMENT_REQUIRED_REVIEWERS"`
+}
+
+type ConfigScheduler struct {
+	SlaCheckInterval    time.Duration `yaml:"sla_check_interval" env:"SLA_CHECK_INTERVAL"`
+	DigestCheckInterval time.Duration `yaml:"digest_check_interval" env:"DIGEST_CHECK_INTERVAL"`
+}
+
+type ConfigNotifier struct {
+	// smtp, webhook or none
+	Notifier     string `yaml:"notifier" env:"DIGEST_NOTIFIER"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPFrom     string `yaml:"smtp_from" env:"SMTP_FROM"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+	WebhookURL   string `yaml:"webhook_url" env:"DIGEST_WEBHOOK_URL"`
+}
+
+type ConfigTracing struct {
+	// otlp, stdout or none
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// host:port of an OTLP/HTTP collector
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool    `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type ConfigFeatures struct {
+	// serve /metrics
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS"`
+	// serve /swagger
+	Swagger bool `yaml:"swagger" env:"FEATURE_SWAGGER"`
+	// run the background SLA escalation job
+	SlaChecks bool `yaml:"sla_checks" env:"FEATURE_SLA_CHECKS"`
+	// serve /snapshot/export and /snapshot/import, the export holds every email and
+	// the replace import wipes all tables
+	Snapshots bool `yaml:"snapshots" env:"FEATURE_SNAPSHOTS"`
+}
+
+type ConfigDirectory struct {
+	// file, ldap or none
+	Source       string        `yaml:"source" env:"DIRECTORY_SOURCE"`
+	SyncInterval time.Duration `yaml:"sync_interval" env:"DIRECTORY_SYNC_INTERVAL"`
+	// group to team names as group:team,group:team, empty maps every group to the team of the same name
+	TeamMap TeamMap `yaml:"team_map" env:"DIRECTORY_TEAM_MAP"`
+	// roster file in the /team/import format
+	File string     `yaml:"file" env:"DIRECTORY_FILE"`
+	LDAP ConfigLDAP `yaml:"ldap"`
 }
 
-func LoadConfigEnv() (*ConfigDb, error) {
-	err := godotenv.Load()
-	if err != nil {
-		return nil, fmt.Errorf("error loading .env file %w", err)
+// TeamMap is a mapping in YAML and group:team,group:team in the environment
+type TeamMap map[string]string
+
+func (m *TeamMap) UnmarshalText(text []byte) error {
+	parsed := make(TeamMap)
+	for _, pair := range strings.Split(string(text), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, team, ok := strings.Cut(pair, ":")
+		if !ok || group == "" || team == "" {
+			return fmt.Errorf("team map entry %q is not group:team", pair)
+		}
+		parsed[group] = team
 	}
+	*m = parsed
+	return nil
+}
 
-	configDb := ConfigDb{}
+type ConfigLDAP struct {
+	URL           string `yaml:"url" env:"LDAP_URL"`
+	BindDN        string `yaml:"bind_dn" env:"LDAP_BIND_DN"`
+	BindPassword  string `yaml:"bind_password" env:"LDAP_BIND_PASSWORD"`
+	BaseDN        string `yaml:"base_dn" env:"LDAP_BASE_DN"`
+	GroupFilter   string `yaml:"group_filter" env:"LDAP_GROUP_FILTER"`
+	GroupNameAttr string `yaml:"group_name_attr" env:"LDAP_GROUP_NAME_ATTR"`
+	MemberAttr    string `yaml:"member_attr" env:"LDAP_MEMBER_ATTR"`
+	UserFilter    string `yaml:"user_filter" env:"LDAP_USER_FILTER"`
+	UserIDAttr    string `yaml:"user_id_attr" env:"LDAP_USER_ID_ATTR"`
+	UsernameAttr  string `yaml:"username_attr" env:"LDAP_USERNAME_ATTR"`
+	// optional, true, yes or 1 in it deactivates the user
+	DisabledAttr string        `yaml:"disabled_attr" env:"LDAP_DISABLED_ATTR"`
+	Timeout      time.Duration `yaml:"timeout" env:"LDAP_TIMEOUT"`
+	// 0 turns paging off
+	PageSize uint32 `yaml:"page_size" env:"LDAP_PAGE_SIZE"`
+}
 
-	err = env.Parse(&configDb)
-	if err != nil {
-		return nil, err
+func Defaults() Config {
+	return Config{
+		Server: ConfigServer{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Db: ConfigDb{
+			Host:            "db",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxConns:        10,
+			MinConns:        0,
+			ConnectTimeout:  5 * time.Second,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+		},
+		Log: ConfigLog{
+			Level:  slog.LevelInfo,
+			Format: "json",
+		},
+		Assignment: ConfigAssignment{
+			Strategy:          model.DefaultStrategy,
+			PairingWindow:     720 * time.Hour,
+			RequiredReviewers: 2,
+		},
+		Scheduler: ConfigScheduler{
+			SlaCheckInterval:    5 * time.Minute,
+			DigestCheckInterval: time.Hour,
+		},
+		Notifier: ConfigNotifier{
+			Notifier: "none",
+			SMTPHost: "localhost",
+			SMTPPort: 25,
+			SMTPFrom: "pr-assignment@localhost",
+		},
+		Tracing: ConfigTracing{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			OTLPInsecure: true,
+			SampleRatio:  1,
+		},
+		Features: ConfigFeatures{
+			Metrics:   true,
+			Swagger:   true,
+			SlaChecks: true,
+		},
+		Directory: ConfigDirectory{
+			Source:       "none",
+			SyncInterval: 15 * time.Minute,
+			LDAP: ConfigLDAP{
+				GroupFilter:   "(objectClass=groupOfNames)",
+				GroupNameAttr: "cn",
+				MemberAttr:    "member",
+				UserFilter:    "(objectClass=inetOrgPerson)",
+				UserIDAttr:    "uid",
+				UsernameAttr:  "cn",
+				Timeout:       10 * time.Second,
+				PageSize:      500,
+			},
+		},
 	}
+}
 
-	return &configDb, nil
+// DSN is the connection url for pgx and migrations
+func (c ConfigDb) DSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.DbUsername, c.DbPassword),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.DbName,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return dsn.String()
 }
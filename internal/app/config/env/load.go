@@ -0,0 +1,155 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
+)
+
+const redacted = "******"
+
+// Load builds the config from defaults, the YAML file at path (skipped when path is empty),
+// .env in the working directory when it exists and the environment
+func Load(path string) (*Config, error) {
+	config := Defaults()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config file: %w", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unable to parse config file %s: %w", path, err)
+		}
+	}
+
+	// variables already set in the environment are not overridden by .env
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error loading .env file %w", err)
+	}
+
+	err = env.Parse(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate reports all invalid values at once
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "SERVER_ADDR is required")
+	check(c.Server.ReadHeaderTimeout >= 0 && c.Server.ReadTimeout >= 0 && c.Server.WriteTimeout >= 0 &&
+		c.Server.IdleTimeout >= 0, "server timeouts must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT must be positive, got %s", c.Server.ShutdownTimeout)
+
+	check(c.Db.Host != "", "DB_HOST is required")
+	check(c.Db.Port > 0 && c.Db.Port < 65536, "DB_PORT must be a valid port, got %d", c.Db.Port)
+	check(c.Db.DbUsername != "", "DB_USERNAME is required")
+	check(c.Db.DbName != "", "DB_NAME is required")
+	switch c.Db.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		check(false, "unknown DB_SSLMODE %s", c.Db.SSLMode)
+	}
+	check(c.Db.MaxConns > 0, "DB_MAX_CONNS must be positive, got %d", c.Db.MaxConns)
+	check(c.Db.MinConns >= 0 && c.Db.MinConns <= c.Db.MaxConns,
+		"DB_MIN_CONNS must be between 0 and DB_MAX_CONNS, got %d", c.Db.MinConns)
+	check(c.Db.ConnectTimeout > 0, "DB_CONNECT_TIMEOUT must be positive, got %s", c.Db.ConnectTimeout)
+	check(c.Db.MaxConnLifetime > 0, "DB_MAX_CONN_LIFETIME must be positive, got %s", c.Db.MaxConnLifetime)
+	check(c.Db.MaxConnIdleTime > 0, "DB_MAX_CONN_IDLE_TIME must be positive, got %s", c.Db.MaxConnIdleTime)
+
+	check(c.Log.Format == "json" || c.Log.Format == "text", "unknown LOG_FORMAT %s", c.Log.Format)
+
+	check(c.Assignment.Strategy.IsValid(), "unknown ASSIGNMENT_STRATEGY %s", c.Assignment.Strategy)
+	check(c.Assignment.PairingWindow > 0, "ASSIGNMENT_PAIRING_WINDOW must be positive, got %s",
+		c.Assignment.PairingWindow)
+	check(c.Assignment.RequiredReviewers > 0, "ASSIGNMENT_REQUIRED_REVIEWERS must be positive, got %d",
+		c.Assignment.RequiredReviewers)
+
+	check(c.Scheduler.SlaCheckInterval > 0, "SLA_CHECK_INTERVAL must be positive, got %s",
+		c.Scheduler.SlaCheckInterval)
+	check(c.Scheduler.DigestCheckInterval > 0, "DIGEST_CHECK_INTERVAL must be positive, got %s",
+		c.Scheduler.DigestCheckInterval)
+
+	switch c.Notifier.Notifier {
+	case "none", "smtp":
+	case "webhook":
+		check(c.Notifier.WebhookURL != "", "DIGEST_WEBHOOK_URL is required for webhook notifier")
+	default:
+		check(false, "unknown DIGEST_NOTIFIER %s", c.Notifier.Notifier)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		check(false, "unknown TRACING_EXPORTER %s", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	switch c.Directory.Source {
+	case "none":
+	case "file":
+		check(c.Directory.File != "", "DIRECTORY_FILE is required for file directory source")
+	case "ldap":
+		check(c.Directory.LDAP.URL != "", "LDAP_URL is required for ldap directory source")
+		check(c.Directory.LDAP.BaseDN != "", "LDAP_BASE_DN is required for ldap directory source")
+		check(c.Directory.LDAP.GroupFilter != "" && c.Directory.LDAP.UserFilter != "",
+			"LDAP_GROUP_FILTER and LDAP_USER_FILTER are required for ldap directory source")
+		check(c.Directory.LDAP.GroupNameAttr != "" && c.Directory.LDAP.MemberAttr != "" &&
+			c.Directory.LDAP.UserIDAttr != "" && c.Directory.LDAP.UsernameAttr != "",
+			"ldap attribute names are required for ldap directory source")
+		check(c.Directory.LDAP.Timeout > 0, "LDAP_TIMEOUT must be positive, got %s", c.Directory.LDAP.Timeout)
+	default:
+		check(false, "unknown DIRECTORY_SOURCE %s", c.Directory.Source)
+	}
+	check(c.Directory.SyncInterval > 0, "DIRECTORY_SYNC_INTERVAL must be positive, got %s",
+		c.Directory.SyncInterval)
+
+	return errors.Join(errs...)
+}
+
+// Redacted is a copy safe to print, secrets are replaced when set
+func (c Config) Redacted() Config {
+	hide := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	c.Db.DbPassword = hide(c.Db.DbPassword)
+	c.Notifier.SMTPPassword = hide(c.Notifier.SMTPPassword)
+	// webhook urls usually carry a token
+	c.Notifier.WebhookURL = hide(c.Notifier.WebhookURL)
+	c.Directory.LDAP.BindPassword = hide(c.Directory.LDAP.BindPassword)
+	return c
+}
+
+// YAML renders the redacted config in the config file format
+func (c Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
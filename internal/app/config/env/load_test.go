@@ -0,0 +1,163 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	config := Defaults()
+	config.Db.DbUsername = "postgres"
+	config.Db.DbName = "reviews"
+	return config
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr []string
+	}{
+		{name: "defaults with credentials", change: func(c *Config) {}},
+		{name: "defaults need a database", change: func(c *Config) { *c = Defaults() },
+			wantErr: []string{"DB_USERNAME is required", "DB_NAME is required"}},
+		{name: "every problem is reported", change: func(c *Config) {
+			c.Db.Port = 70000
+			c.Db.SSLMode = "sometimes"
+			c.Db.MinConns = 20
+			c.Log.Format = "xml"
+			c.Tracing.SampleRatio = 2
+		}, wantErr: []string{"DB_PORT must be a valid port, got 70000", "unknown DB_SSLMODE sometimes",
+			"DB_MIN_CONNS must be between 0 and DB_MAX_CONNS, got 20", "unknown LOG_FORMAT xml",
+			"TRACING_SAMPLE_RATIO must be between 0 and 1, got 2"}},
+		{name: "negative server timeout", change: func(c *Config) { c.Server.WriteTimeout = -time.Second },
+			wantErr: []string{"server timeouts must not be negative"}},
+		{name: "webhook notifier needs a url", change: func(c *Config) { c.Notifier.Notifier = "webhook" },
+			wantErr: []string{"DIGEST_WEBHOOK_URL is required for webhook notifier"}},
+		{name: "file directory needs a file", change: func(c *Config) { c.Directory.Source = "file" },
+			wantErr: []string{"DIRECTORY_FILE is required for file directory source"}},
+		{name: "ldap directory needs a server", change: func(c *Config) { c.Directory.Source = "ldap" },
+			wantErr: []string{"LDAP_URL is required", "LDAP_BASE_DN is required"}},
+		{name: "ldap defaults are enough with a server", change: func(c *Config) {
+			c.Directory.Source = "ldap"
+			c.Directory.LDAP.URL = "ldap://localhost:389"
+			c.Directory.LDAP.BaseDN = "dc=example,dc=com"
+		}},
+		{name: "unknown choices", change: func(c *Config) {
+			c.Assignment.Strategy = "random"
+			c.Notifier.Notifier = "pigeon"
+			c.Tracing.Exporter = "jaeger"
+			c.Directory.Source = "ad"
+		}, wantErr: []string{"unknown ASSIGNMENT_STRATEGY random", "unknown DIGEST_NOTIFIER pigeon",
+			"unknown TRACING_EXPORTER jaeger", "unknown DIRECTORY_SOURCE ad"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.change(&config)
+
+			err := config.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("err = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTeamMapUnmarshalText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    TeamMap
+		wantErr bool
+	}{
+		{text: "", want: TeamMap{}},
+		{text: "backend-devs:backend", want: TeamMap{"backend-devs": "backend"}},
+		{text: " backend-devs:backend , frontend-devs:web,", want: TeamMap{"backend-devs": "backend",
+			"frontend-devs": "web"}},
+		{text: "backend-devs", wantErr: true},
+		{text: "backend-devs:", wantErr: true},
+		{text: ":backend", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got TeamMap
+		err := got.UnmarshalText([]byte(tt.text))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("UnmarshalText(%q) = %v, want an error", tt.text, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnmarshalText(%q) unexpected error: %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnmarshalText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadSourcesOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+db:
+  username: postgres
+  name: reviews
+  port: 6432
+assignment:
+  required_reviewers: 3
+directory:
+  team_map:
+    backend-devs: backend
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ASSIGNMENT_REQUIRED_REVIEWERS", "4")
+	t.Setenv("DIRECTORY_TEAM_MAP", "frontend-devs:web")
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the file overrides defaults
+	if config.Db.Port != 6432 || config.Db.Host != "db" {
+		t.Errorf("db = %s:%d, want db:6432", config.Db.Host, config.Db.Port)
+	}
+	// the environment overrides the file
+	if config.Assignment.RequiredReviewers != 4 {
+		t.Errorf("required reviewers = %d, want 4", config.Assignment.RequiredReviewers)
+	}
+	if want := (TeamMap{"frontend-devs": "web"}); !reflect.DeepEqual(config.Directory.TeamMap, want) {
+		t.Errorf("team map = %v, want %v", config.Directory.TeamMap, want)
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("db:\n  hostname: db\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "unable to parse config file") {
+		t.Fatalf("err = %v, want a parse error", err)
+	}
+}
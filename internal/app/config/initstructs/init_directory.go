@@ -0,0 +1,33 @@
+package initstructs
+
+import (
+	"pr-assignment/internal/adapter/out/directory"
+	"pr-assignment/internal/app/config/env"
+	"pr-assignment/internal/service"
+)
+
+// InitDirectorySource returns nil when directory sync is turned off
+func InitDirectorySource(config env.ConfigDirectory) service.DirectorySource {
+	switch config.Source {
+	case "file":
+		return directory.NewFileSource(config.File)
+	case "ldap":
+		return directory.NewLDAPSource(directory.LDAPConfig{
+			URL:           config.LDAP.URL,
+			BindDN:        config.LDAP.BindDN,
+			BindPassword:  config.LDAP.BindPassword,
+			BaseDN:        config.LDAP.BaseDN,
+			GroupFilter:   config.LDAP.GroupFilter,
+			GroupNameAttr: config.LDAP.GroupNameAttr,
+			MemberAttr:    config.LDAP.MemberAttr,
+			UserFilter:    config.LDAP.UserFilter,
+			UserIDAttr:    config.LDAP.UserIDAttr,
+			UsernameAttr:  config.LDAP.UsernameAttr,
+			DisabledAttr:  config.LDAP.DisabledAttr,
+			Timeout:       config.LDAP.Timeout,
+			PageSize:      config.LDAP.PageSize,
+		})
+	default:
+		return nil
+	}
+}
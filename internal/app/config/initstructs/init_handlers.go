@@ -8,16 +8,34 @@ type Handlers struct {
 	UserHandler        *handler.UserHandler
 	PullRequestHandler *handler.PullRequestHandler
 	StatHandler        *handler.StatHandler
+	ExclusionHandler   *handler.ExclusionHandler
+	SlaHandler         *handler.SlaHandler
+	HealthHandler      *handler.HealthHandler
+	RosterHandler      *handler.RosterHandler
+	SnapshotHandler    *handler.SnapshotHandler
+	TeamHandler        *handler.TeamHandler
 }
 
-func InitHandlers(services Services) Handlers {
-	userHandler := handler.NewUserHandler(services.userService, services.pullRequestService)
+func InitHandlers(services Services, readiness handler.Readiness) Handlers {
+	userHandler := handler.NewUserHandler(services.userService, services.pullRequestService, services.DigestService)
 	prHandler := handler.NewPullRequestHandler(services.pullRequestService)
 	statHandler := handler.NewStatHandler(services.statService)
+	exclusionHandler := handler.NewExclusionHandler(services.exclusionService)
+	slaHandler := handler.NewSlaHandler(services.SlaService)
+	healthHandler := handler.NewHealthHandler(readiness)
+	rosterHandler := handler.NewRosterHandler(services.rosterService, services.DirectorySyncService)
+	snapshotHandler := handler.NewSnapshotHandler(services.snapshotService)
+	teamHandler := handler.NewTeamHandler(services.teamService)
 
 	return Handlers{
 		UserHandler:        userHandler,
 		PullRequestHandler: prHandler,
 		StatHandler:        statHandler,
+		ExclusionHandler:   exclusionHandler,
+		SlaHandler:         slaHandler,
+		HealthHandler:      healthHandler,
+		RosterHandler:      rosterHandler,
+		SnapshotHandler:    snapshotHandler,
+		TeamHandler:        teamHandler,
 	}
 }
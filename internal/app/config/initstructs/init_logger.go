@@ -0,0 +1,15 @@
+package initstructs
+
+import (
+	"log/slog"
+	"os"
+	"pr-assignment/internal/app/config/env"
+	"pr-assignment/internal/logging"
+)
+
+// InitLogger also makes the logger the default one, for libraries logging through slog
+func InitLogger(config env.ConfigLog) *slog.Logger {
+	logger := logging.New(os.Stdout, config.Level, config.Format)
+	slog.SetDefault(logger)
+	return logger
+}
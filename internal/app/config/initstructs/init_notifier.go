@@ -0,0 +1,20 @@
+package initstructs
+
+import (
+	"pr-assignment/internal/adapter/out/notify"
+	"pr-assignment/internal/app/config/env"
+	"pr-assignment/internal/service"
+)
+
+// InitNotifier returns nil when digests are turned off
+func InitNotifier(config env.ConfigNotifier) service.Notifier {
+	switch config.Notifier {
+	case "smtp":
+		return notify.NewSMTPNotifier(config.SMTPHost, config.SMTPPort, config.SMTPFrom,
+			config.SMTPUsername, config.SMTPPassword)
+	case "webhook":
+		return notify.NewWebhookNotifier(config.WebhookURL)
+	default:
+		return nil
+	}
+}
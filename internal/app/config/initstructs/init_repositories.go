@@ -11,6 +11,14 @@ type Repositories struct {
 	prRepo        *repository.PullRequestRepository
 	userRepo      *repository.UserRepository
 	prReviewsRepo *repository.PrReviewersRepository
+	historyRepo   *repository.AssignmentHistoryRepository
+	exclusionRepo *repository.ExclusionRepository
+	declineRepo   *repository.ReviewDeclineRepository
+	slaRepo       *repository.SlaRepository
+	decisionRepo  *repository.ReviewDecisionRepository
+	rosterRepo    *repository.RosterRepository
+	snapshotRepo  *repository.SnapshotRepository
+	moveRepo      *repository.TeamMoveRepository
 }
 
 func InitRepositories(pool *pgxpool.Pool) Repositories {
@@ -18,11 +26,27 @@ func InitRepositories(pool *pgxpool.Pool) Repositories {
 	prRepo := repository.NewPullRequestRepository(pool)
 	userRepo := repository.NewUserRepository(pool)
 	prReviewersRepo := repository.NewPrReviewersRepository(pool)
+	historyRepo := repository.NewAssignmentHistoryRepository(pool)
+	exclusionRepo := repository.NewExclusionRepository(pool)
+	declineRepo := repository.NewReviewDeclineRepository(pool)
+	slaRepo := repository.NewSlaRepository(pool)
+	decisionRepo := repository.NewReviewDecisionRepository(pool)
+	rosterRepo := repository.NewRosterRepository(pool)
+	snapshotRepo := repository.NewSnapshotRepository(pool)
+	moveRepo := repository.NewTeamMoveRepository(pool)
 
 	return Repositories{
 		teamRepo:      teamRepo,
 		prRepo:        prRepo,
 		userRepo:      userRepo,
 		prReviewsRepo: prReviewersRepo,
+		historyRepo:   historyRepo,
+		exclusionRepo: exclusionRepo,
+		declineRepo:   declineRepo,
+		slaRepo:       slaRepo,
+		decisionRepo:  decisionRepo,
+		rosterRepo:    rosterRepo,
+		snapshotRepo:  snapshotRepo,
+		moveRepo:      moveRepo,
 	}
 }
@@ -1,6 +1,9 @@
 package initstructs
 
 import (
+	"log/slog"
+	"pr-assignment/internal/app/config/env"
+	"pr-assignment/internal/metrics"
 	"pr-assignment/internal/service"
 )
 
@@ -8,16 +11,53 @@ type Services struct {
 	userService        *service.UserService
 	pullRequestService *service.PullRequestService
 	statService        *service.StatService
+	exclusionService   *service.ExclusionService
+	SlaService         *service.SlaService
+	DigestService      *service.DigestService
+	rosterService      *service.RosterService
+	snapshotService    *service.SnapshotService
+	teamService        *service.TeamService
+	// nil when no directory source is configured
+	DirectorySyncService *service.DirectorySyncService
 }
 
-func InitServices(repos Repositories) Services {
-	userService := service.NewUserService(repos.userRepo, repos.teamRepo)
-	prService := service.NewPullRequestService(repos.prRepo, repos.prReviewsRepo, repos.teamRepo, repos.userRepo, userService)
-	statService := service.NewStatService(repos.prReviewsRepo, repos.userRepo, repos.prRepo)
+func InitServices(repos Repositories, configAssignment env.ConfigAssignment, notifier service.Notifier,
+	directorySource service.DirectorySource, configDirectory env.ConfigDirectory, metrics *metrics.Metrics,
+	logger *slog.Logger) Services {
+	userService := service.NewUserService(repos.userRepo, repos.teamRepo, repos.slaRepo, logger)
+	prService := service.NewPullRequestService(repos.prRepo, repos.prReviewsRepo, repos.teamRepo, repos.userRepo,
+		repos.historyRepo, repos.exclusionRepo, repos.declineRepo, repos.decisionRepo, userService, metrics,
+		logger, configAssignment.Strategy, configAssignment.PairingWindow,
+		configAssignment.RequiredReviewers)
+	statService := service.NewStatService(repos.prReviewsRepo, repos.teamRepo, repos.historyRepo,
+		repos.declineRepo, repos.decisionRepo, configAssignment.RequiredReviewers)
+	exclusionService := service.NewExclusionService(repos.exclusionRepo, repos.userRepo)
+	slaService := service.NewSlaService(repos.slaRepo, repos.teamRepo, prService, logger)
+	digestService := service.NewDigestService(repos.userRepo, repos.prReviewsRepo, notifier, logger)
+
+	rosterService := service.NewRosterService(repos.rosterRepo, prService, logger)
+	snapshotService := service.NewSnapshotService(repos.snapshotRepo, logger)
+	teamService := service.NewTeamService(repos.teamRepo, repos.userRepo, repos.moveRepo, userService, prService,
+		logger)
+	var directorySyncService *service.DirectorySyncService
+	if directorySource != nil {
+		directorySyncService = service.NewDirectorySyncService(directorySource, rosterService,
+			configDirectory.TeamMap, logger)
+	}
+
+	metrics.RegisterTeamStats(statService)
 
 	return Services{
 		userService:        userService,
 		pullRequestService: prService,
 		statService:        statService,
+		exclusionService:   exclusionService,
+		SlaService:         slaService,
+		DigestService:      digestService,
+		rosterService:      rosterService,
+		snapshotService:    snapshotService,
+		teamService:        teamService,
+
+		DirectorySyncService: directorySyncService,
 	}
 }
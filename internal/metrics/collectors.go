@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// team gauges are read from the database on every scrape
+const teamStatsTimeout = 5 * time.Second
+
+type TeamStatsSource interface {
+	GetTeamStats(ctx context.Context, teamName string, rollup bool) ([]model.TeamStats, error)
+}
+
+type teamCollector struct {
+	source      TeamStatsSource
+	logger      *slog.Logger
+	openPRs     *prometheus.Desc
+	activeUsers *prometheus.Desc
+}
+
+// RegisterTeamStats exports open PRs and active users of every team
+func (m *Metrics) RegisterTeamStats(source TeamStatsSource) {
+	m.registry.MustRegister(&teamCollector{
+		source: source,
+		logger: m.logger,
+		openPRs: prometheus.NewDesc(prometheus.BuildFQName(namespace, "team", "open_pull_requests"),
+			"Open pull requests by the team of the pull request.", []string{"team"}, nil),
+		activeUsers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "team", "active_users"),
+			"Active members of the team.", []string{"team"}, nil),
+	})
+}
+
+func (c *teamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openPRs
+	ch <- c.activeUsers
+}
+
+func (c *teamCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), teamStatsTimeout)
+	defer cancel()
+
+	// every team on its own, rolled up gauges would count sub-teams twice in sums
+	stats, err := c.source.GetTeamStats(ctx, "", false)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "unable to collect team metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.openPRs, err)
+		return
+	}
+
+	for _, team := range stats {
+		ch <- prometheus.MustNewConstMetric(c.openPRs, prometheus.GaugeValue, float64(team.OpenPRs), team.TeamName)
+		ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(team.ActiveMembers),
+			team.TeamName)
+	}
+}
+
+type poolCollector struct {
+	pool             *pgxpool.Pool
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireWait      *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	emptyAcquireWait *prometheus.Desc
+}
+
+// RegisterPool exports connection pool stats
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+
+	m.registry.MustRegister(&poolCollector{
+		pool:          pool,
+		acquiredConns: desc("acquired_conns", "Connections currently acquired."),
+		idleConns:     desc("idle_conns", "Idle connections."),
+		totalConns:    desc("total_conns", "Connections in the pool."),
+		maxConns:      desc("max_conns", "Maximum size of the pool."),
+		acquireCount:  desc("acquire_total", "Successful acquires."),
+		acquireWait:   desc("acquire_duration_seconds_total", "Total time spent in successful acquires."),
+		emptyAcquire: desc("empty_acquire_total",
+			"Acquires that had to wait for a connection because the pool was empty."),
+		emptyAcquireWait: desc("empty_acquire_wait_seconds_total",
+			"Total time acquires waited for a connection because the pool was empty."),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireWait
+	ch <- c.emptyAcquire
+	ch <- c.emptyAcquireWait
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireWait, prometheus.CounterValue,
+		stat.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"pr-assignment/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_assignment"
+
+// AssignOperation is the NO_CANDIDATE operation label for reviewers missing on PR creation
+const AssignOperation = "assign"
+
+type Metrics struct {
+	registry      *prometheus.Registry
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	prsCreated    prometheus.Counter
+	prsMerged     prometheus.Counter
+	reassignments *prometheus.CounterVec
+	noCandidate   *prometheus.CounterVec
+	logger        *slog.Logger
+}
+
+func NewMetrics(logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		logger:   logger,
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		prsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_created_total",
+			Help:      "Pull requests created.",
+		}),
+		prsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_merged_total",
+			Help:      "Pull requests merged.",
+		}),
+		reassignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reassignments_total",
+			Help:      "Reviewers replaced by someone else, by reason.",
+		}, []string{"reason"}),
+		noCandidate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "no_candidate_total",
+			Help:      "Times no reviewer could be found, by operation: assign or a reassignment reason.",
+		}, []string{"operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.prsCreated, m.prsMerged, m.reassignments, m.noCandidate,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records every request under its gin route, not the raw path,
+// so that path parameters do not blow up the number of series
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) PRCreated() {
+	m.prsCreated.Inc()
+}
+
+func (m *Metrics) PRMerged() {
+	m.prsMerged.Inc()
+}
+
+func (m *Metrics) Reassigned(reason model.ReassignReason) {
+	m.reassignments.WithLabelValues(string(reason)).Inc()
+}
+
+func (m *Metrics) NoCandidate(operation string) {
+	m.noCandidate.WithLabelValues(operation).Inc()
+}
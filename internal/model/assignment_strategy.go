@@ -0,0 +1,14 @@
+package model
+
+type AssignmentStrategy string
+
+const (
+	// DefaultStrategy takes candidates in team order
+	DefaultStrategy AssignmentStrategy = "default"
+	// PairingDiversityStrategy puts recent reviewers of the author to the end of the queue
+	PairingDiversityStrategy AssignmentStrategy = "pairing_diversity"
+)
+
+func (s AssignmentStrategy) IsValid() bool {
+	return s == DefaultStrategy || s == PairingDiversityStrategy
+}
@@ -12,7 +12,12 @@ const (
 	NotAssigned   ErrCode = "NOT_ASSIGNED"
 	NoCandidate   ErrCode = "NO_CANDIDATE"
 	NotFound      ErrCode = "NOT_FOUND"
+	BadRequest    ErrCode = "BAD_REQUEST"
+	Excluded      ErrCode = "REVIEWER_EXCLUDED"
 	InternalError ErrCode = "INTERNAL_ERROR"
+	NotReady      ErrCode = "NOT_READY"
+	HasOpenPRs    ErrCode = "HAS_OPEN_PRS"
+	WrongState    ErrCode = "WRONG_REVIEW_STATE"
 )
 
 type CustomError struct {
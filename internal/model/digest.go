@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type DigestFrequency string
+
+const (
+	DAILY  DigestFrequency = "daily"
+	WEEKLY DigestFrequency = "weekly"
+	NONE   DigestFrequency = "none"
+)
+
+func (f DigestFrequency) IsValid() bool {
+	return f == DAILY || f == WEEKLY || f == NONE
+}
+
+type DigestSettings struct {
+	UserID    string          `json:"user_id"`
+	Email     string          `json:"email"`
+	Frequency DigestFrequency `json:"frequency"`
+}
+
+type DigestItem struct {
+	PullRequestShort
+	CreatedAt time.Time `json:"created_at"`
+	AgeHours  int       `json:"age_hours"`
+}
+
+// ReviewDigest lists open PRs the user still has to review, oldest first
+type ReviewDigest struct {
+	UserID       string       `json:"user_id"`
+	Username     string       `json:"username"`
+	Email        string       `json:"email"`
+	PullRequests []DigestItem `json:"pull_requests"`
+}
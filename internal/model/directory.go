@@ -0,0 +1,14 @@
+package model
+
+// DirectoryUser is a person as the corporate directory knows them
+type DirectoryUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+// DirectoryGroup is a directory group that maps to a team
+type DirectoryGroup struct {
+	Name    string          `json:"name"`
+	Members []DirectoryUser `json:"members"`
+}
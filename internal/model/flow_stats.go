@@ -0,0 +1,30 @@
+package model
+
+// Percentiles are in seconds, nil when no PR in the range reached the stage
+type Percentiles struct {
+	Median *float64 `json:"median_seconds"`
+	P90    *float64 `json:"p90_seconds"`
+}
+
+type FlowMetrics struct {
+	PullRequests      int         `json:"pull_requests"`
+	TimeToFirstReview Percentiles `json:"time_to_first_review"`
+	TimeToApproval    Percentiles `json:"time_to_approval"`
+	TimeToMerge       Percentiles `json:"time_to_merge"`
+}
+
+type TeamFlowMetrics struct {
+	TeamName string `json:"team_name"`
+	FlowMetrics
+}
+
+// UserFlowMetrics is computed over PRs authored by the user
+type UserFlowMetrics struct {
+	UserID string `json:"user_id"`
+	FlowMetrics
+}
+
+type FlowReport struct {
+	Teams []TeamFlowMetrics `json:"teams"`
+	Users []UserFlowMetrics `json:"users"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// ReviewerPairing is the review history of one reviewer for one author
+type ReviewerPairing struct {
+	ReviewerID     string    `json:"reviewer_id"`
+	Count          int       `json:"count"`
+	LastAssignedAt time.Time `json:"last_assigned_at"`
+}
+
+type PairingCount struct {
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+	Count      int    `json:"count"`
+}
+
+// PairingMatrix rows are authors, columns are reviewers
+type PairingMatrix struct {
+	Authors   []string `json:"authors"`
+	Reviewers []string `json:"reviewers"`
+	Counts    [][]int  `json:"counts"`
+}
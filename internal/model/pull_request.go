@@ -4,7 +4,12 @@ import "time"
 
 type PullRequest struct {
 	PullRequestShort
-	AssignedReviewers []string  `json:"assigned_reviewers"`
-	CreatedAt         time.Time `json:"createdAt"`
-	MergedAt          time.Time `json:"mergedAt"`
+	// team that reviews the PR
+	TeamID             string    `json:"-"`
+	TeamName           string    `json:"team_name,omitempty"`
+	AssignedReviewers  []string  `json:"assigned_reviewers"`
+	FallbackReviewers  []string  `json:"fallback_reviewers,omitempty"`
+	ExcludedCandidates []string  `json:"excluded_candidates,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+	MergedAt           time.Time `json:"mergedAt"`
 }
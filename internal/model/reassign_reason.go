@@ -0,0 +1,12 @@
+package model
+
+// ReassignReason tells why a reviewer was replaced
+type ReassignReason string
+
+const (
+	ManualReassign       ReassignReason = "manual"
+	DeactivationReassign ReassignReason = "deactivation"
+	DeclineReassign      ReassignReason = "decline"
+	SlaReassign          ReassignReason = "sla"
+	TeamChangeReassign   ReassignReason = "team_change"
+)
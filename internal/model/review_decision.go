@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Decision string
+
+const (
+	APPROVED         Decision = "approved"
+	ChangesRequested Decision = "changes_requested"
+	COMMENTED        Decision = "commented"
+)
+
+func (d Decision) IsValid() bool {
+	return d == APPROVED || d == ChangesRequested || d == COMMENTED
+}
+
+type ReviewDecision struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	Decision      Decision  `json:"decision"`
+	DecidedAt     time.Time `json:"decided_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type StatBucket string
+
+const (
+	DAY   StatBucket = "day"
+	WEEK  StatBucket = "week"
+	MONTH StatBucket = "month"
+)
+
+func (b StatBucket) IsValid() bool {
+	return b == DAY || b == WEEK || b == MONTH
+}
+
+// ReviewLoadPoint is the number of assignments in the bucket starting at Start
+type ReviewLoadPoint struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// UserReviewLoad has a point for every bucket of the range, empty buckets included
+type UserReviewLoad struct {
+	User   User              `json:"user"`
+	Total  int               `json:"total"`
+	Points []ReviewLoadPoint `json:"points"`
+}
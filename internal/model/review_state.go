@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type ReviewState string
+
+const (
+	ASSIGNED ReviewState = "assigned"
+	ACCEPTED ReviewState = "accepted"
+	REVIEWED ReviewState = "reviewed"
+)
+
+type ReviewAssignment struct {
+	PullRequestID string      `json:"pull_request_id"`
+	ReviewerID    string      `json:"reviewer_id"`
+	State         ReviewState `json:"state"`
+	AssignedAt    time.Time   `json:"assigned_at"`
+	RespondedAt   *time.Time  `json:"responded_at,omitempty"`
+}
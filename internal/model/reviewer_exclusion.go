@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ReviewerExclusion forbids ReviewerID to review PRs of AuthorID, symmetric one works both ways
+type ReviewerExclusion struct {
+	AuthorID   string    `json:"author_id"`
+	ReviewerID string    `json:"reviewer_id"`
+	Symmetric  bool      `json:"symmetric"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
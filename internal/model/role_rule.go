@@ -0,0 +1,7 @@
+package model
+
+// RoleRule requires at least MinCount reviewers with Role or higher on every PR of the team
+type RoleRule struct {
+	Role     UserRole `json:"role"`
+	MinCount int      `json:"min_count"`
+}
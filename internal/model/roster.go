@@ -0,0 +1,43 @@
+package model
+
+// RosterEntry is one member line of an imported roster
+type RosterEntry struct {
+	Line     int      `json:"line"`
+	TeamName string   `json:"team_name"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	IsActive bool     `json:"is_active"`
+	Role     UserRole `json:"role,omitempty"`
+}
+
+type RosterChange string
+
+const (
+	RosterCreated     RosterChange = "created"
+	RosterUpdated     RosterChange = "updated"
+	RosterUnchanged   RosterChange = "unchanged"
+	RosterDeactivated RosterChange = "deactivated"
+)
+
+// RosterRow is the outcome for one user. Line is 0 for users deactivated by reconcile,
+// they are not in the roster
+type RosterRow struct {
+	Line     int          `json:"line,omitempty"`
+	TeamName string       `json:"team_name"`
+	UserID   string       `json:"user_id"`
+	Username string       `json:"username"`
+	Change   RosterChange `json:"change"`
+	// changed fields of updated users
+	Fields []string `json:"fields,omitempty"`
+}
+
+// RosterImportResult lists in ReconciledTeams the teams whose members missing from the roster
+// were deactivated
+type RosterImportResult struct {
+	DryRun          bool                 `json:"dry_run"`
+	Reconcile       bool                 `json:"reconcile"`
+	ReconciledTeams []string             `json:"reconciled_teams,omitempty"`
+	CreatedTeams    []string             `json:"created_teams"`
+	Rows            []RosterRow          `json:"rows"`
+	Summary         map[RosterChange]int `json:"summary"`
+}
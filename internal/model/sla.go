@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type SlaPolicy string
+
+const (
+	// AddLead keeps the late reviewer and adds a lead of the team
+	AddLead SlaPolicy = "add_lead"
+	// Reassign replaces the late reviewer with someone else
+	Reassign SlaPolicy = "reassign"
+)
+
+func (p SlaPolicy) IsValid() bool {
+	return p == AddLead || p == Reassign
+}
+
+// TeamSla is the time reviewers of the team have to act on an assignment.
+// Hours are business hours, weekends are not counted
+type TeamSla struct {
+	FirstReviewHours int       `json:"first_review_hours"`
+	Policy           SlaPolicy `json:"policy"`
+}
+
+type PendingReview struct {
+	PullRequestID string
+	ReviewerID    string
+	TeamID        string
+	AssignedAt    time.Time
+	Sla           TeamSla
+}
+
+type SlaEscalation struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	TeamID        string    `json:"team_id"`
+	Action        SlaPolicy `json:"action"`
+	NewReviewerID string    `json:"new_reviewer_id,omitempty"`
+	EscalatedAt   time.Time `json:"escalated_at"`
+}
+
+type TeamSlaBreaches struct {
+	TeamName string `json:"team_name"`
+	Breaches int    `json:"breaches"`
+}
+
+type ReviewerSlaBreaches struct {
+	ReviewerID string `json:"reviewer_id"`
+	Breaches   int    `json:"breaches"`
+}
+
+type SlaReport struct {
+	Teams     []TeamSlaBreaches     `json:"teams"`
+	Reviewers []ReviewerSlaBreaches `json:"reviewers"`
+}
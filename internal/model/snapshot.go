@@ -0,0 +1,165 @@
+package model
+
+import "time"
+
+// SnapshotVersion is the archive format version, bumped on incompatible changes
+const SnapshotVersion = 1
+
+type SnapshotMode string
+
+const (
+	// SnapshotReplace wipes all data and loads the archive as is
+	SnapshotReplace SnapshotMode = "replace"
+	// SnapshotMerge adds and updates rows of the archive and never deletes
+	SnapshotMerge SnapshotMode = "merge"
+)
+
+func (m SnapshotMode) IsValid() bool {
+	return m == SnapshotReplace || m == SnapshotMerge
+}
+
+// Snapshot is the whole dataset. Teams are referenced by team_id inside the archive
+type Snapshot struct {
+	Version            int                   `json:"version"`
+	CreatedAt          time.Time             `json:"created_at"`
+	Teams              []SnapshotTeam        `json:"teams"`
+	TeamFallbacks      []SnapshotFallback    `json:"team_fallbacks"`
+	TeamRoleRules      []SnapshotRoleRule    `json:"team_role_rules"`
+	TeamSla            []SnapshotTeamSla     `json:"team_sla"`
+	Users              []SnapshotUser        `json:"users"`
+	TeamMemberships    []SnapshotMembership  `json:"team_memberships"`
+	ReviewerExclusions []SnapshotExclusion   `json:"reviewer_exclusions"`
+	PullRequests       []SnapshotPullRequest `json:"pull_requests"`
+	Reviewers          []SnapshotReviewer    `json:"reviewers"`
+	Assignments        []SnapshotAssignment  `json:"assignments"`
+	Declines           []SnapshotDecline     `json:"declines"`
+	Decisions          []SnapshotDecision    `json:"decisions"`
+	Escalations        []SnapshotEscalation  `json:"escalations"`
+	TeamMoves          []SnapshotTeamMove    `json:"team_moves"`
+}
+
+type SnapshotTeam struct {
+	TeamID       string `json:"team_id"`
+	TeamName     string `json:"team_name"`
+	ParentTeamID string `json:"parent_team_id,omitempty"`
+}
+
+type SnapshotFallback struct {
+	TeamID         string `json:"team_id"`
+	FallbackTeamID string `json:"fallback_team_id"`
+	Position       int    `json:"position"`
+}
+
+type SnapshotRoleRule struct {
+	TeamID   string   `json:"team_id"`
+	Role     UserRole `json:"role"`
+	MinCount int      `json:"min_count"`
+}
+
+type SnapshotTeamSla struct {
+	TeamID           string    `json:"team_id"`
+	FirstReviewHours int       `json:"first_review_hours"`
+	Policy           SlaPolicy `json:"policy"`
+}
+
+type SnapshotUser struct {
+	UserID          string          `json:"user_id"`
+	Username        string          `json:"username"`
+	TeamID          string          `json:"team_id"`
+	IsActive        bool            `json:"is_active"`
+	Role            UserRole        `json:"role"`
+	Email           string          `json:"email"`
+	DigestFrequency DigestFrequency `json:"digest_frequency"`
+	DigestSentAt    *time.Time      `json:"digest_sent_at,omitempty"`
+}
+
+// SnapshotMembership is a secondary team of a user, the primary team is SnapshotUser.TeamID
+type SnapshotMembership struct {
+	UserID  string    `json:"user_id"`
+	TeamID  string    `json:"team_id"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type SnapshotExclusion struct {
+	AuthorID    string    `json:"author_id"`
+	ReviewerID  string    `json:"reviewer_id"`
+	IsSymmetric bool      `json:"is_symmetric"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SnapshotPullRequest has no team_id in archives made before PRs had a team, such PRs get
+// the primary team of their author
+type SnapshotPullRequest struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamID          string     `json:"team_id,omitempty"`
+	Status          PRstatus   `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at,omitempty"`
+}
+
+// SnapshotReviewer is a current reviewer of a PR
+type SnapshotReviewer struct {
+	PullRequestID string      `json:"pull_request_id"`
+	ReviewerID    string      `json:"reviewer_id"`
+	FromFallback  bool        `json:"from_fallback"`
+	State         ReviewState `json:"state"`
+	AssignedAt    time.Time   `json:"assigned_at"`
+	RespondedAt   *time.Time  `json:"responded_at,omitempty"`
+}
+
+// SnapshotAssignment is a row of the assignment history
+type SnapshotAssignment struct {
+	PullRequestID string    `json:"pull_request_id"`
+	AuthorID      string    `json:"author_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	AssignedAt    time.Time `json:"assigned_at"`
+}
+
+type SnapshotDecline struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	Reason        string    `json:"reason"`
+	DeclinedAt    time.Time `json:"declined_at"`
+}
+
+type SnapshotDecision struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	Decision      Decision  `json:"decision"`
+	DecidedAt     time.Time `json:"decided_at"`
+}
+
+type SnapshotEscalation struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	TeamID        string    `json:"team_id"`
+	Action        SlaPolicy `json:"action"`
+	NewReviewerID *string   `json:"new_reviewer_id,omitempty"`
+	EscalatedAt   time.Time `json:"escalated_at"`
+}
+
+// SnapshotTeamMove is a row of the team change audit, teams are kept by name as recorded
+type SnapshotTeamMove struct {
+	UserID             string           `json:"user_id"`
+	FromTeam           string           `json:"from_team"`
+	ToTeam             string           `json:"to_team"`
+	Source             MoveSource       `json:"source"`
+	ReviewPolicy       MoveReviewPolicy `json:"review_policy"`
+	ReevaluateAuthored bool             `json:"reevaluate_authored"`
+	ReassignedReviews  int              `json:"reassigned_reviews"`
+	ReassignedAuthored int              `json:"reassigned_authored"`
+	MovedAt            time.Time        `json:"moved_at"`
+}
+
+// SnapshotImportResult counts rows inserted or changed per section, a repeated merge of the
+// same archive writes nothing
+type SnapshotImportResult struct {
+	Mode    SnapshotMode   `json:"mode"`
+	DryRun  bool           `json:"dry_run"`
+	Written map[string]int `json:"written"`
+	// archive teams merged into existing teams of the same name
+	MatchedTeams []string `json:"matched_teams"`
+}
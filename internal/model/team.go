@@ -1,6 +1,22 @@
 package model
 
+// Team lists primary members in Members. SecondaryMembers are users whose primary team is
+// another one, they review PRs of this team too. SubTeams is only filled when the subtree
+// is asked for
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName         string       `json:"team_name"`
+	ParentTeam       string       `json:"parent_team,omitempty"`
+	Members          []TeamMember `json:"members"`
+	SecondaryMembers []TeamMember `json:"secondary_members,omitempty"`
+	FallbackTeams    []string     `json:"fallback_teams,omitempty"`
+	RoleRules        []RoleRule   `json:"role_rules,omitempty"`
+	Sla              *TeamSla     `json:"sla,omitempty"`
+	SubTeams         []Team       `json:"sub_teams,omitempty"`
+}
+
+// TeamNode is a team with its place in the hierarchy, ParentID is empty for a top level team
+type TeamNode struct {
+	TeamID   string
+	TeamName string
+	ParentID string
 }
@@ -0,0 +1,61 @@
+package model
+
+// TeamSummary is a row of the team list, members are primary and secondary ones like in /team/get
+type TeamSummary struct {
+	TeamName      string `json:"team_name"`
+	ParentTeam    string `json:"parent_team,omitempty"`
+	Members       int    `json:"members"`
+	ActiveMembers int    `json:"active_members"`
+}
+
+type TeamList struct {
+	Teams []TeamSummary `json:"teams"`
+	// matching teams without limit and offset
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// OpenPRPolicy tells what happens to open PRs filed in a team when the team is deleted
+type OpenPRPolicy string
+
+const (
+	// BlockOpenPRs refuses to delete a team while it has open PRs
+	BlockOpenPRs OpenPRPolicy = "block"
+	// KeepOpenPRs moves open PRs to the new team and keeps the reviewers
+	KeepOpenPRs OpenPRPolicy = "keep"
+	// ReassignOpenPRs moves open PRs with their authors and picks pending reviewers again
+	// from the new team
+	ReassignOpenPRs OpenPRPolicy = "reassign"
+)
+
+func (p OpenPRPolicy) IsValid() bool {
+	return p == BlockOpenPRs || p == KeepOpenPRs || p == ReassignOpenPRs
+}
+
+type TeamDeletion struct {
+	TeamName     string       `json:"team_name"`
+	MovedTo      string       `json:"moved_to,omitempty"`
+	MovedMembers []string     `json:"moved_members"`
+	OpenPRs      []string     `json:"open_prs"`
+	Policy       OpenPRPolicy `json:"open_prs_policy"`
+	// reviewers replaced under the reassign policy
+	Reassigned int `json:"reassigned"`
+}
+
+type MemberRemoval string
+
+const (
+	// MemberDeleted means the user had no PRs or reviews and is gone
+	MemberDeleted MemberRemoval = "deleted"
+	// MemberDeactivated means the user is kept for the history, inactive
+	MemberDeactivated MemberRemoval = "deactivated"
+	// MembershipRemoved means the team was a secondary team of the user, the user stays in the others
+	MembershipRemoved MemberRemoval = "membership_removed"
+)
+
+type MemberRemovalResult struct {
+	TeamName string        `json:"team_name"`
+	UserID   string        `json:"user_id"`
+	Result   MemberRemoval `json:"result"`
+}
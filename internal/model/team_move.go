@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// MoveReviewPolicy tells what happens to open reviews of a user who changes teams
+type MoveReviewPolicy string
+
+const (
+	// KeepReviews leaves the user on every review they have
+	KeepReviews MoveReviewPolicy = "keep"
+	// ReassignReviews hands unfinished reviews of PRs from outside the new team back to
+	// the teams of their authors
+	ReassignReviews MoveReviewPolicy = "reassign"
+)
+
+func (p MoveReviewPolicy) IsValid() bool {
+	return p == KeepReviews || p == ReassignReviews
+}
+
+// MoveSource is the operation that changed the team of a user. MoveTeamAdd is only found
+// in older records, /team/add no longer moves users
+type MoveSource string
+
+const (
+	MoveExplicit   MoveSource = "move"
+	MoveTeamAdd    MoveSource = "team_add"
+	MoveTeamDelete MoveSource = "team_delete"
+	MoveRoster     MoveSource = "roster"
+)
+
+// TeamMove is an audit record of a user changing teams. Reassignment counts are only
+// filled by explicit moves
+type TeamMove struct {
+	MoveID             int64            `json:"move_id"`
+	UserID             string           `json:"user_id"`
+	FromTeam           string           `json:"from_team"`
+	ToTeam             string           `json:"to_team"`
+	Source             MoveSource       `json:"source"`
+	ReviewPolicy       MoveReviewPolicy `json:"review_policy"`
+	ReevaluateAuthored bool             `json:"reevaluate_authored"`
+	// reviews of the user handed to someone else
+	ReassignedReviews int `json:"reassigned_reviews"`
+	// pending reviewers replaced on the user's own open PRs
+	ReassignedAuthored int       `json:"reassigned_authored"`
+	MovedAt            time.Time `json:"moved_at"`
+}
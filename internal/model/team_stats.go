@@ -0,0 +1,18 @@
+package model
+
+// TeamStats aggregates PRs by the team of the PR. A PR is under-reviewed when it has
+// fewer reviewers than its team currently requires. Members are primary and secondary ones.
+// Rolled up stats of a team also count PRs and members of all its sub-teams, RequiredReviewers
+// stays the team's own
+type TeamStats struct {
+	TeamName           string  `json:"team_name"`
+	ParentTeam         string  `json:"parent_team,omitempty"`
+	OpenPRs            int     `json:"open_prs"`
+	MergedPRs          int     `json:"merged_prs"`
+	AvgReviewers       float64 `json:"avg_reviewers"`
+	ActiveMembers      int     `json:"active_members"`
+	InactiveMembers    int     `json:"inactive_members"`
+	RequiredReviewers  int     `json:"required_reviewers"`
+	UnderReviewedPRs   int     `json:"under_reviewed_prs"`
+	UnderReviewedShare float64 `json:"under_reviewed_share"`
+}
@@ -1,8 +1,16 @@
 package model
 
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	TeamName string   `json:"team_name"`
+	IsActive bool     `json:"is_active"`
+	Role     UserRole `json:"role"`
+}
+
+// UserTeams lists every team of the user, the primary team first
+type UserTeams struct {
+	UserID      string   `json:"user_id"`
+	PrimaryTeam string   `json:"primary_team"`
+	Teams       []string `json:"teams"`
 }
@@ -0,0 +1,6 @@
+package model
+
+type UserDeclinesCount struct {
+	User          User `json:"user"`
+	DeclinesCount int  `json:"declines_count"`
+}
@@ -0,0 +1,25 @@
+package model
+
+type UserRole string
+
+const (
+	MEMBER UserRole = "member"
+	SENIOR UserRole = "senior"
+	LEAD   UserRole = "lead"
+)
+
+var roleRanks = map[UserRole]int{
+	MEMBER: 0,
+	SENIOR: 1,
+	LEAD:   2,
+}
+
+func (r UserRole) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// lead is also counted as senior, senior as member
+func (r UserRole) AtLeast(role UserRole) bool {
+	return roleRanks[r] >= roleRanks[role]
+}
@@ -0,0 +1,124 @@
+package prctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pr-assignment/internal/model"
+	"strings"
+	"time"
+)
+
+// request is one call of the HTTP API
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// raw is sent as is instead of json body
+	raw         []byte
+	contentType string
+}
+
+// APIError is a non 2xx response of the service
+type APIError struct {
+	Status  int
+	Code    model.ErrCode
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("http %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("http %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// Client calls the pr-assignment HTTP API. With dryRun it prints requests instead of sending them
+type Client struct {
+	baseURL string
+	http    *http.Client
+	dryRun  bool
+	out     io.Writer
+}
+
+func NewClient(baseURL string, timeout time.Duration, dryRun bool, out io.Writer) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), http: &http.Client{Timeout: timeout}, dryRun: dryRun,
+		out: out}
+}
+
+// Do sends the request and returns the response body, nil in dry-run mode
+func (c *Client) Do(ctx context.Context, req request) ([]byte, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	body, contentType := req.raw, req.contentType
+	if req.body != nil {
+		var err error
+		body, err = json.MarshalIndent(req.body, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		contentType = "application/json"
+	}
+
+	if c.dryRun {
+		_, err := fmt.Fprintf(c.out, "%s %s\n", req.method, target)
+		if err == nil && body != nil {
+			_, err = fmt.Fprintf(c.out, "%s\n", body)
+		}
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, parseAPIError(resp.StatusCode, respBody)
+	}
+
+	return respBody, nil
+}
+
+// parseAPIError understands model.ErrorResponse and the plain {"error": "..."} of bind errors
+func parseAPIError(status int, body []byte) error {
+	var errResp model.ErrorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error.Code != "" {
+		return &APIError{Status: status, Code: errResp.Error.Code, Message: errResp.Error.Message}
+	}
+
+	var plain struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &plain) == nil && plain.Error != "" {
+		return &APIError{Status: status, Message: plain.Error}
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &APIError{Status: status, Message: message}
+}
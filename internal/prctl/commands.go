@@ -0,0 +1,739 @@
+package prctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UsageError is returned for unknown commands and bad arguments
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+type command struct {
+	usage string
+	run   func(a *App, ctx context.Context, args []string) error
+}
+
+var commands = map[string]map[string]command{
+	"team": {
+		"add":    {"team add NAME --member USER_ID:USERNAME... | --file TEAM_JSON", (*App).teamAdd},
+		"get":    {"team get NAME [--subtree]", (*App).teamGet},
+		"kill":   {"team kill NAME", (*App).teamKill},
+		"import": {"team import FILE.yaml|FILE.csv [--reconcile] [--preview]", (*App).teamImport},
+		"sync":   {"team sync [--preview]", (*App).teamSync},
+		"list":   {"team list [--search TEXT] [--limit N] [--offset N]", (*App).teamList},
+		"rename": {"team rename NAME NEW_NAME", (*App).teamRename},
+		"delete": {"team delete NAME [--move-to TEAM] [--open-prs block|keep|reassign]", (*App).teamDelete},
+		"add-member": {"team add-member NAME USER_ID:USERNAME [--role member|senior|lead] [--inactive]",
+			(*App).teamAddMember},
+		"remove-member": {"team remove-member NAME USER_ID", (*App).teamRemoveMember},
+		"set-parent":    {"team set-parent NAME [--parent TEAM]", (*App).teamSetParent},
+	},
+	"user": {
+		"activate":   {"user activate USER_ID", (*App).userActivate},
+		"deactivate": {"user deactivate USER_ID", (*App).userDeactivate},
+		"move": {"user move USER_ID TEAM [--reviews keep|reassign] [--reevaluate-authored]",
+			(*App).userMove},
+		"moves": {"user moves [--user USER_ID] [--team NAME] [--limit N]", (*App).userMoves},
+		"teams": {"user teams USER_ID", (*App).userTeams},
+	},
+	"pr": {
+		"create":   {"pr create PR_ID --name NAME --author USER_ID [--team NAME]", (*App).prCreate},
+		"merge":    {"pr merge PR_ID", (*App).prMerge},
+		"reassign": {"pr reassign PR_ID --old REVIEWER_ID", (*App).prReassign},
+	},
+	"snapshot": {
+		"export": {"snapshot export [--file FILE.json]", (*App).snapshotExport},
+		"import": {"snapshot import FILE.json --mode replace|merge [--preview]", (*App).snapshotImport},
+	},
+	"stats": {
+		"prs":      {"stats prs [--team NAME]", statCommand("prs")},
+		"reviews":  {"stats reviews [--team NAME] [--from DATE --to DATE --bucket day|week|month]", statCommand("reviews")},
+		"declines": {"stats declines", statCommand("declines")},
+		"pairings": {"stats pairings", statCommand("pairings")},
+		"flow":     {"stats flow [--team NAME] [--from DATE --to DATE]", statCommand("flow")},
+		"teams":    {"stats teams [--team NAME] [--rollup]", statCommand("teams")},
+		"sla":      {"stats sla", statCommand("sla")},
+	},
+}
+
+// Usage lists every command
+func Usage() string {
+	lines := make([]string, 0)
+	for _, group := range commands {
+		for _, cmd := range group {
+			lines = append(lines, "  prctl [flags] "+cmd.usage)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// App runs commands against the API and prints the results
+type App struct {
+	client *Client
+	output outputFormat
+	out    io.Writer
+}
+
+func NewApp(client *Client, output string, out io.Writer) (*App, error) {
+	switch outputFormat(output) {
+	case tableOutput, jsonOutput:
+	default:
+		return nil, usageErrorf("output must be table or json, got %s", output)
+	}
+	return &App{client: client, output: outputFormat(output), out: out}, nil
+}
+
+// Run executes "GROUP COMMAND ARGS..."
+func (a *App) Run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return usageErrorf("expected a command, see prctl -h")
+	}
+
+	group, ok := commands[args[0]]
+	if !ok {
+		return usageErrorf("unknown command %s, see prctl -h", args[0])
+	}
+	cmd, ok := group[args[1]]
+	if !ok {
+		return usageErrorf("unknown command %s %s, see prctl -h", args[0], args[1])
+	}
+
+	err := cmd.run(a, ctx, args[2:])
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		return usageErrorf("%s\nusage: prctl %s", usageErr.Message, cmd.usage)
+	}
+	return err
+}
+
+// parseArgs allows flags before and after positional arguments and checks their count
+func parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	values := make([]string, 0, positional)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageErrorf("%s", err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		values = append(values, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(values) != positional {
+		return nil, usageErrorf("expected %d argument(s), got %d", positional, len(values))
+	}
+	return values, nil
+}
+
+// call sends the request and prints the response, table is skipped for json output and dry runs
+func (a *App) call(ctx context.Context, req request, table func(body []byte) error) error {
+	body, err := a.client.Do(ctx, req)
+	if err != nil || body == nil {
+		return err
+	}
+
+	if a.output == jsonOutput {
+		return writeJSON(a.out, body)
+	}
+	return table(body)
+}
+
+func decode[T any](body []byte) (T, error) {
+	var value T
+	if err := json.Unmarshal(body, &value); err != nil {
+		return value, fmt.Errorf("unable to read response: %w", err)
+	}
+	return value, nil
+}
+
+func (a *App) teamTable(body []byte) error {
+	team, err := decode[model.Team](body)
+	if err != nil {
+		return err
+	}
+	return a.writeTeam(team)
+}
+
+// writeTeam prints the team and then its sub-teams, each under its own header
+func (a *App) writeTeam(team model.Team) error {
+	header := "team: " + team.TeamName
+	if team.ParentTeam != "" {
+		header += ", parent: " + team.ParentTeam
+	}
+	if _, err := fmt.Fprintln(a.out, header); err != nil {
+		return err
+	}
+	if err := writeTable(a.out, memberHeader, memberRows(team.Members)); err != nil {
+		return err
+	}
+
+	if len(team.SecondaryMembers) > 0 {
+		if _, err := fmt.Fprintf(a.out, "\nsecondary members:\n"); err != nil {
+			return err
+		}
+		if err := writeTable(a.out, memberHeader, memberRows(team.SecondaryMembers)); err != nil {
+			return err
+		}
+	}
+
+	for _, subTeam := range team.SubTeams {
+		if _, err := fmt.Fprintln(a.out); err != nil {
+			return err
+		}
+		if err := a.writeTeam(subTeam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memberFlag collects repeated --member USER_ID:USERNAME values
+type memberFlag []model.TeamMember
+
+func (m *memberFlag) String() string {
+	return fmt.Sprint(*m)
+}
+
+func (m *memberFlag) Set(value string) error {
+	userID, username, ok := strings.Cut(value, ":")
+	if !ok || userID == "" || username == "" {
+		return fmt.Errorf("member must be USER_ID:USERNAME, got %s", value)
+	}
+	*m = append(*m, model.TeamMember{UserID: userID, Username: username, IsActive: true})
+	return nil
+}
+
+func (a *App) teamAdd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team add", flag.ContinueOnError)
+	var members memberFlag
+	fs.Var(&members, "member", "active member as USER_ID:USERNAME, repeatable")
+	file := fs.String("file", "", "team json as accepted by /team/add, NAME overrides its team_name")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	team := model.Team{}
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, &team); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", *file, err)
+		}
+	}
+	team.TeamName = values[0]
+	team.Members = append(team.Members, members...)
+	if len(team.Members) == 0 {
+		return usageErrorf("team needs at least one --member or a --file with members")
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/add", body: team}, a.teamTable)
+}
+
+func (a *App) teamGet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team get", flag.ContinueOnError)
+	subtree := fs.Bool("subtree", false, "include sub-teams")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"team_name": {values[0]}}
+	if *subtree {
+		query.Set("subtree", "true")
+	}
+	return a.call(ctx, request{method: http.MethodGet, path: "/team/get", query: query}, a.teamTable)
+}
+
+func (a *App) teamKill(ctx context.Context, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("team kill", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/kill",
+		body: dto.TeamName{TeamName: values[0]}}, a.teamTable)
+}
+
+func (a *App) teamList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team list", flag.ContinueOnError)
+	search := fs.String("search", "", "part of the team name")
+	limit := fs.Int("limit", 0, "page size, the service default when 0")
+	offset := fs.Int("offset", 0, "teams to skip")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *search != "" {
+		query.Set("search", *search)
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+	if *offset > 0 {
+		query.Set("offset", strconv.Itoa(*offset))
+	}
+
+	return a.call(ctx, request{method: http.MethodGet, path: "/teams", query: query}, func(body []byte) error {
+		list, err := decode[model.TeamList](body)
+		if err != nil {
+			return err
+		}
+		return writeTeamList(a.out, list)
+	})
+}
+
+func (a *App) teamSetParent(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team set-parent", flag.ContinueOnError)
+	parent := fs.String("parent", "", "parent team, a top level team when empty")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/setParent",
+		body: dto.TeamParentQuery{TeamName: values[0], ParentTeam: *parent}}, a.teamTable)
+}
+
+func (a *App) teamRename(ctx context.Context, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("team rename", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/rename",
+		body: dto.TeamRenameQuery{TeamName: values[0], NewTeamName: values[1]}}, a.teamTable)
+}
+
+func (a *App) teamDelete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team delete", flag.ContinueOnError)
+	moveTo := fs.String("move-to", "", "team that gets the members")
+	openPRs := fs.String("open-prs", "", "block, keep or reassign, the service default is block")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/delete",
+		body: dto.TeamDeleteQuery{TeamName: values[0], MoveMembersTo: *moveTo,
+			OpenPRs: model.OpenPRPolicy(*openPRs)}}, func(body []byte) error {
+		result, err := decode[model.TeamDeletion](body)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.out, "deleted %s: moved %d members to %s, open PRs %d (%s), reviewers reassigned %d\n",
+			result.TeamName, len(result.MovedMembers), orDash(result.MovedTo), len(result.OpenPRs), result.Policy,
+			result.Reassigned)
+		return err
+	})
+}
+
+func (a *App) teamAddMember(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team add-member", flag.ContinueOnError)
+	role := fs.String("role", "", "member, senior or lead, member by default")
+	inactive := fs.Bool("inactive", false, "add the user deactivated")
+	values, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	userID, username, ok := strings.Cut(values[1], ":")
+	if !ok || userID == "" || username == "" {
+		return usageErrorf("member must be USER_ID:USERNAME, got %s", values[1])
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/addMember",
+		body: dto.TeamMemberQuery{TeamName: values[0], TeamMember: model.TeamMember{UserID: userID,
+			Username: username, IsActive: !*inactive, Role: model.UserRole(*role)}}}, a.teamTable)
+}
+
+func (a *App) teamRemoveMember(ctx context.Context, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("team remove-member", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/removeMember",
+		body: dto.TeamMemberRemoveQuery{TeamName: values[0], UserID: values[1]}}, func(body []byte) error {
+		result, err := decode[model.MemberRemovalResult](body)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.out, "%s %s from %s\n", result.UserID, result.Result, result.TeamName)
+		return err
+	})
+}
+
+func (a *App) teamImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team import", flag.ContinueOnError)
+	reconcile := fs.Bool("reconcile", false, "deactivate users of the roster teams missing from the roster")
+	preview := fs.Bool("preview", false, "let the service report the diff without writing")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(values[0])
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/yaml"
+	if strings.EqualFold(filepath.Ext(values[0]), ".csv") {
+		contentType = "text/csv"
+	}
+
+	query := url.Values{}
+	if *reconcile {
+		query.Set("reconcile", "true")
+	}
+	if *preview {
+		query.Set("dry_run", "true")
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/import", query: query, raw: data,
+		contentType: contentType}, func(body []byte) error {
+		result, err := decode[model.RosterImportResult](body)
+		if err != nil {
+			return err
+		}
+		return writeRosterResult(a.out, result)
+	})
+}
+
+func (a *App) teamSync(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team sync", flag.ContinueOnError)
+	preview := fs.Bool("preview", false, "let the service report the diff without writing")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *preview {
+		query.Set("dry_run", "true")
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/sync", query: query},
+		func(body []byte) error {
+			result, err := decode[model.RosterImportResult](body)
+			if err != nil {
+				return err
+			}
+			return writeRosterResult(a.out, result)
+		})
+}
+
+// snapshotExport saves the archive as the service returned it, to stdout when no file is given
+func (a *App) snapshotExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("snapshot export", flag.ContinueOnError)
+	file := fs.String("file", "", "write the archive to the file instead of stdout")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	body, err := a.client.Do(ctx, request{method: http.MethodGet, path: "/snapshot/export"})
+	if err != nil || body == nil {
+		return err
+	}
+
+	if *file == "" {
+		_, err = a.out.Write(body)
+		return err
+	}
+	if err = os.WriteFile(*file, body, 0o600); err != nil {
+		return err
+	}
+
+	snapshot, err := decode[model.Snapshot](body)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(a.out, "wrote %s: %d teams, %d users, %d pull requests\n", *file, len(snapshot.Teams),
+		len(snapshot.Users), len(snapshot.PullRequests))
+	return err
+}
+
+func (a *App) snapshotImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("snapshot import", flag.ContinueOnError)
+	mode := fs.String("mode", "", "replace wipes all data first, merge adds and updates rows")
+	preview := fs.Bool("preview", false, "let the service count the rows without writing")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if !model.SnapshotMode(*mode).IsValid() {
+		return usageErrorf("--mode must be replace or merge")
+	}
+
+	data, err := os.ReadFile(values[0])
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"mode": {*mode}}
+	if *preview {
+		query.Set("dry_run", "true")
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/snapshot/import", query: query, raw: data,
+		contentType: "application/json"}, func(body []byte) error {
+		result, err := decode[model.SnapshotImportResult](body)
+		if err != nil {
+			return err
+		}
+		return writeSnapshotResult(a.out, result)
+	})
+}
+
+func (a *App) userActivate(ctx context.Context, args []string) error {
+	return a.setUserActive(ctx, "user activate", args, true)
+}
+
+func (a *App) userDeactivate(ctx context.Context, args []string) error {
+	return a.setUserActive(ctx, "user deactivate", args, false)
+}
+
+func (a *App) setUserActive(ctx context.Context, name string, args []string, isActive bool) error {
+	values, err := parseArgs(flag.NewFlagSet(name, flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/users/setIsActive",
+		body: dto.StatusQuery{UserID: values[0], IsActive: isActive}}, func(body []byte) error {
+		resp, err := decode[dto.UserResponse](body)
+		if err != nil {
+			return err
+		}
+		return writeTable(a.out, userHeader, [][]string{userRow(resp.User)})
+	})
+}
+
+func (a *App) userMove(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user move", flag.ContinueOnError)
+	reviews := fs.String("reviews", "", "keep or reassign unfinished reviews, keep by default")
+	reevaluate := fs.Bool("reevaluate-authored", false, "pick pending reviewers of the user's open PRs again")
+	values, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/team/moveMember",
+		body: dto.TeamMoveQuery{UserID: values[0], TeamName: values[1], Reviews: model.MoveReviewPolicy(*reviews),
+			ReevaluateAuthored: *reevaluate}}, func(body []byte) error {
+		move, err := decode[model.TeamMove](body)
+		if err != nil {
+			return err
+		}
+		return writeTeamMoves(a.out, []model.TeamMove{move})
+	})
+}
+
+func (a *App) userMoves(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user moves", flag.ContinueOnError)
+	userID := fs.String("user", "", "only moves of the user")
+	team := fs.String("team", "", "only moves from or to the team")
+	limit := fs.Int("limit", 0, "number of moves, the service default when 0")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *userID != "" {
+		query.Set("user_id", *userID)
+	}
+	if *team != "" {
+		query.Set("team_name", *team)
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	return a.call(ctx, request{method: http.MethodGet, path: "/team/moves", query: query}, func(body []byte) error {
+		moves, err := decode[[]model.TeamMove](body)
+		if err != nil {
+			return err
+		}
+		return writeTeamMoves(a.out, moves)
+	})
+}
+
+func (a *App) userTeams(ctx context.Context, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("user teams", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"user_id": {values[0]}}
+	return a.call(ctx, request{method: http.MethodGet, path: "/users/getTeams", query: query}, func(body []byte) error {
+		teams, err := decode[model.UserTeams](body)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.out, "%s: primary %s, teams %s\n", teams.UserID, teams.PrimaryTeam,
+			strings.Join(teams.Teams, ","))
+		return err
+	})
+}
+
+func (a *App) prCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pr create", flag.ContinueOnError)
+	name := fs.String("name", "", "pull request name")
+	author := fs.String("author", "", "author user id")
+	team := fs.String("team", "", "team that reviews the PR, the author's primary team by default")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *name == "" || *author == "" {
+		return usageErrorf("--name and --author are required")
+	}
+
+	query := dto.PullRequestQuery{PullRequestID: values[0], PullRequestName: *name, AuthorID: *author,
+		TeamName: *team}
+	return a.call(ctx, request{method: http.MethodPost, path: "/pullRequest/create", body: query},
+		func(body []byte) error {
+			pr, err := decode[dto.PrResponse](body)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(a.out, "team: %s\n", pr.TeamName); err != nil {
+				return err
+			}
+			return writeTable(a.out, prHeader, [][]string{prRow(pr.PullRequestShort, pr.AssignedReviewers)})
+		})
+}
+
+func (a *App) prMerge(ctx context.Context, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("pr merge", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.call(ctx, request{method: http.MethodPost, path: "/pullRequest/merge",
+		body: dto.PullRequestIDQuery{PrID: values[0]}}, func(body []byte) error {
+		resp, err := decode[dto.PrMergedResponse](body)
+		if err != nil {
+			return err
+		}
+		pr := resp.PrMerged
+		return writeTable(a.out, append(prHeader, "merged_at"),
+			[][]string{append(prRow(pr.PullRequestShort, pr.AssignedReviewers), formatTime(pr.MergedAt))})
+	})
+}
+
+func (a *App) prReassign(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pr reassign", flag.ContinueOnError)
+	old := fs.String("old", "", "reviewer to replace")
+	values, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *old == "" {
+		return usageErrorf("--old is required")
+	}
+
+	query := dto.PrReassignQuery{PullRequestID: values[0], OldReviewerID: *old}
+	return a.call(ctx, request{method: http.MethodPost, path: "/pullRequest/reassign", body: query},
+		func(body []byte) error {
+			resp, err := decode[dto.PrReassignResponse](body)
+			if err != nil {
+				return err
+			}
+			return writeTable(a.out, append(prHeader, "replaced_by"),
+				[][]string{append(prRow(resp.PullRequestShort, resp.AssignedReviewers), resp.ReplacedBy)})
+		})
+}
+
+// stat describes a /stat endpoint and the filters it accepts
+type stat struct {
+	path    string
+	team    bool
+	period  bool
+	buckets bool
+	rollup  bool
+}
+
+var stats = map[string]stat{
+	"prs":      {path: "/stat/pull_request/reviewers", team: true},
+	"reviews":  {path: "/stat/users/reviews", team: true, period: true, buckets: true},
+	"declines": {path: "/stat/users/declines"},
+	"pairings": {path: "/stat/pairings"},
+	"flow":     {path: "/stat/flow", team: true, period: true},
+	"teams":    {path: "/stat/teams", team: true, rollup: true},
+	"sla":      {path: "/stat/sla"},
+}
+
+// statCommand asks the service for csv in table mode, so the columns match the csv export
+func statCommand(name string) func(a *App, ctx context.Context, args []string) error {
+	return func(a *App, ctx context.Context, args []string) error {
+		s := stats[name]
+
+		fs := flag.NewFlagSet("stats "+name, flag.ContinueOnError)
+		var team, from, to, bucket *string
+		if s.team {
+			team = fs.String("team", "", "team name")
+		}
+		if s.period {
+			from = fs.String("from", "", "from date, 2006-01-02")
+			to = fs.String("to", "", "to date exclusive, 2006-01-02")
+		}
+		if s.buckets {
+			bucket = fs.String("bucket", "", "day, week or month")
+		}
+		var rollup *bool
+		if s.rollup {
+			rollup = fs.Bool("rollup", false, "include sub-teams")
+		}
+		if _, err := parseArgs(fs, args, 0); err != nil {
+			return err
+		}
+
+		query := url.Values{"format": {string(a.output)}}
+		if a.output == tableOutput {
+			query.Set("format", "csv")
+		}
+		for key, value := range map[string]*string{"team_name": team, "from": from, "to": to, "bucket": bucket} {
+			if value != nil && *value != "" {
+				query.Set(key, *value)
+			}
+		}
+		if rollup != nil && *rollup {
+			query.Set("rollup", "true")
+		}
+
+		return a.call(ctx, request{method: http.MethodGet, path: s.path, query: query}, func(body []byte) error {
+			return writeCSVTable(a.out, body)
+		})
+	}
+}
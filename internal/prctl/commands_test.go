@@ -0,0 +1,158 @@
+package prctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"pr-assignment/internal/adapter/in/http/dto"
+	"pr-assignment/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dryRun runs the command with --dry-run and returns the printed request line and body
+func dryRun(t *testing.T, args ...string) (string, []byte) {
+	t.Helper()
+
+	var out bytes.Buffer
+	app, err := NewApp(NewClient("http://prs.local/", time.Second, true, &out), "table", &out)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	if err = app.Run(context.Background(), args); err != nil {
+		t.Fatalf("%s: %v", strings.Join(args, " "), err)
+	}
+
+	line, body, _ := strings.Cut(out.String(), "\n")
+	return line, []byte(strings.TrimSuffix(body, "\n"))
+}
+
+func TestTeamAddDryRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "team.json")
+	data := `{"team_name": "ignored", "members": [{"user_id": "u3", "username": "Carol", "role": "lead"}],
+		"fallback_teams": ["platform"]}`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	line, body := dryRun(t, "team", "add", "backend", "--member", "u1:Alice", "--file", file, "--member", "u2:Bob")
+	if line != "POST http://prs.local/team/add" {
+		t.Errorf("request = %q", line)
+	}
+
+	var team model.Team
+	if err := json.Unmarshal(body, &team); err != nil {
+		t.Fatalf("body %s: %v", body, err)
+	}
+	want := model.Team{
+		TeamName: "backend",
+		Members: []model.TeamMember{
+			{UserID: "u3", Username: "Carol", Role: model.LEAD},
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+		FallbackTeams: []string{"platform"},
+	}
+	if !reflect.DeepEqual(team, want) {
+		t.Errorf("body = %+v, want %+v", team, want)
+	}
+}
+
+func TestPRCreateDryRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want dto.PullRequestQuery
+	}{
+		{
+			name: "primary team",
+			args: []string{"pr", "create", "pr-1", "--name", "fix login", "--author", "u1"},
+			want: dto.PullRequestQuery{PullRequestID: "pr-1", PullRequestName: "fix login", AuthorID: "u1"},
+		},
+		{
+			name: "chosen team",
+			args: []string{"pr", "create", "--team", "platform", "pr-2", "--name", "x", "--author", "u1"},
+			want: dto.PullRequestQuery{PullRequestID: "pr-2", PullRequestName: "x", AuthorID: "u1",
+				TeamName: "platform"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, body := dryRun(t, tt.args...)
+			if line != "POST http://prs.local/pullRequest/create" {
+				t.Errorf("request = %q", line)
+			}
+
+			var query dto.PullRequestQuery
+			if err := json.Unmarshal(body, &query); err != nil {
+				t.Fatalf("body %s: %v", body, err)
+			}
+			if query != tt.want {
+				t.Errorf("body = %+v, want %+v", query, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotImportDryRun(t *testing.T) {
+	archive := `{"version": 1, "teams": []}`
+	file := filepath.Join(t.TempDir(), "prod.json")
+	if err := os.WriteFile(file, []byte(archive), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "replace", args: []string{"snapshot", "import", file, "--mode", "replace"},
+			want: "POST http://prs.local/snapshot/import?mode=replace"},
+		{name: "merge preview", args: []string{"snapshot", "import", "--preview", file, "--mode", "merge"},
+			want: "POST http://prs.local/snapshot/import?dry_run=true&mode=merge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, body := dryRun(t, tt.args...)
+			if line != tt.want {
+				t.Errorf("request = %q, want %q", line, tt.want)
+			}
+			// the archive is sent as it is in the file
+			if string(body) != archive {
+				t.Errorf("body = %s, want %s", body, archive)
+			}
+		})
+	}
+}
+
+func TestDryRunUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"team", "add", "backend"},
+		{"pr", "create", "pr-1", "--name", "x"},
+		{"snapshot", "import", "prod.json", "--mode", "overwrite"},
+	}
+
+	for _, args := range tests {
+		var out bytes.Buffer
+		app, err := NewApp(NewClient("http://prs.local", time.Second, true, &out), "table", &out)
+		if err != nil {
+			t.Fatalf("NewApp: %v", err)
+		}
+
+		err = app.Run(context.Background(), args)
+		var usageErr *UsageError
+		if !errors.As(err, &usageErr) {
+			t.Errorf("%s: err = %v, want a usage error", strings.Join(args, " "), err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%s: printed %q, want nothing", strings.Join(args, " "), out.String())
+		}
+	}
+}
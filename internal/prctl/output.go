@@ -0,0 +1,185 @@
+package prctl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"pr-assignment/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type outputFormat string
+
+const (
+	tableOutput outputFormat = "table"
+	jsonOutput  outputFormat = "json"
+)
+
+// writeJSON prints the response as the service returned it, indented
+func writeJSON(w io.Writer, body []byte) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(w)
+	return err
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t"))); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// writeCSVTable aligns a csv export of the service, the first record is the header
+func writeCSVTable(w io.Writer, body []byte) error {
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("unable to read csv response: %w", err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	for _, record := range records[1:] {
+		for i := range record {
+			if record[i] == "" {
+				record[i] = "-"
+			}
+		}
+	}
+	return writeTable(w, records[0], records[1:])
+}
+
+var memberHeader = []string{"user_id", "username", "is_active", "role"}
+
+func memberRows(members []model.TeamMember) [][]string {
+	rows := make([][]string, 0, len(members))
+	for _, member := range members {
+		rows = append(rows, []string{member.UserID, member.Username, strconv.FormatBool(member.IsActive),
+			orDash(string(member.Role))})
+	}
+	return rows
+}
+
+var userHeader = []string{"user_id", "username", "team_name", "is_active", "role"}
+
+func userRow(user model.User) []string {
+	return []string{user.UserID, user.Username, user.TeamName, strconv.FormatBool(user.IsActive),
+		orDash(string(user.Role))}
+}
+
+var prHeader = []string{"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers"}
+
+func prRow(pr model.PullRequestShort, reviewers []string) []string {
+	return []string{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, string(pr.Status),
+		orDash(strings.Join(reviewers, ","))}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+var rosterHeader = []string{"line", "team_name", "user_id", "username", "change", "fields"}
+
+func writeRosterResult(w io.Writer, result model.RosterImportResult) error {
+	rows := make([][]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		line := "-"
+		if row.Line > 0 {
+			line = strconv.Itoa(row.Line)
+		}
+		rows = append(rows, []string{line, row.TeamName, row.UserID, row.Username, string(row.Change),
+			orDash(strings.Join(row.Fields, ","))})
+	}
+	if err := writeTable(w, rosterHeader, rows); err != nil {
+		return err
+	}
+
+	mode := "applied"
+	if result.DryRun {
+		mode = "preview, nothing written"
+	}
+	_, err := fmt.Fprintf(w, "\n%s: teams created %d, users created %d, updated %d, unchanged %d, deactivated %d\n",
+		mode, len(result.CreatedTeams), result.Summary[model.RosterCreated], result.Summary[model.RosterUpdated],
+		result.Summary[model.RosterUnchanged], result.Summary[model.RosterDeactivated])
+	return err
+}
+
+func writeSnapshotResult(w io.Writer, result model.SnapshotImportResult) error {
+	sections := make([]string, 0, len(result.Written))
+	for section := range result.Written {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	rows := make([][]string, 0, len(sections))
+	for _, section := range sections {
+		rows = append(rows, []string{section, strconv.Itoa(result.Written[section])})
+	}
+	if err := writeTable(w, []string{"section", "written"}, rows); err != nil {
+		return err
+	}
+
+	mode := "applied"
+	if result.DryRun {
+		mode = "preview, nothing written"
+	}
+	_, err := fmt.Fprintf(w, "\n%s: mode %s, teams matched by name: %s\n", mode, result.Mode,
+		orDash(strings.Join(result.MatchedTeams, ",")))
+	return err
+}
+
+var teamListHeader = []string{"team_name", "parent_team", "members", "active_members"}
+
+func writeTeamList(w io.Writer, list model.TeamList) error {
+	rows := make([][]string, 0, len(list.Teams))
+	for _, team := range list.Teams {
+		rows = append(rows, []string{team.TeamName, orDash(team.ParentTeam), strconv.Itoa(team.Members),
+			strconv.Itoa(team.ActiveMembers)})
+	}
+	if err := writeTable(w, teamListHeader, rows); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d-%d of %d\n", min(list.Offset+1, list.Total), list.Offset+len(list.Teams),
+		list.Total)
+	return err
+}
+
+var teamMoveHeader = []string{"moved_at", "user_id", "from_team", "to_team", "source", "reviews",
+	"reassigned_reviews", "reassigned_authored"}
+
+func writeTeamMoves(w io.Writer, moves []model.TeamMove) error {
+	rows := make([][]string, 0, len(moves))
+	for _, move := range moves {
+		rows = append(rows, []string{formatTime(move.MovedAt), move.UserID, move.FromTeam, move.ToTeam,
+			string(move.Source), string(move.ReviewPolicy), strconv.Itoa(move.ReassignedReviews),
+			strconv.Itoa(move.ReassignedAuthored)})
+	}
+	return writeTable(w, teamMoveHeader, rows)
+}
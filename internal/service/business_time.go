@@ -0,0 +1,19 @@
+package service
+
+import "time"
+
+// businessDuration is the time between from and to without Saturdays and Sundays
+func businessDuration(from time.Time, to time.Time) time.Duration {
+	var total time.Duration
+	for from.Before(to) {
+		dayEnd := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, from.Location())
+		if dayEnd.After(to) {
+			dayEnd = to
+		}
+		if from.Weekday() != time.Saturday && from.Weekday() != time.Sunday {
+			total += dayEnd.Sub(from)
+		}
+		from = dayEnd
+	}
+	return total
+}
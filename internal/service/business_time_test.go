@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessDuration(t *testing.T) {
+	// 2025-03-07 is a Friday
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want time.Duration
+	}{
+		{name: "within a day", from: at(5, 9), to: at(5, 17), want: 8 * time.Hour},
+		{name: "sub-day across midnight", from: at(5, 22), to: at(6, 3), want: 5 * time.Hour},
+		{name: "friday evening to monday morning", from: at(7, 18), to: at(10, 9), want: 15 * time.Hour},
+		{name: "starts on saturday", from: at(8, 10), to: at(10, 12), want: 12 * time.Hour},
+		{name: "starts on sunday", from: at(9, 23), to: at(10, 1), want: time.Hour},
+		{name: "whole weekend", from: at(8, 0), to: at(10, 0), want: 0},
+		{name: "full week", from: at(3, 0), to: at(10, 0), want: 5 * 24 * time.Hour},
+		{name: "empty range", from: at(5, 9), to: at(5, 9), want: 0},
+		{name: "reversed range", from: at(6, 9), to: at(5, 9), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := businessDuration(tt.from, tt.to); got != tt.want {
+				t.Errorf("businessDuration = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
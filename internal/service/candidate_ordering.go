@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"pr-assignment/internal/model"
+	"sort"
+	"time"
+)
+
+// candidateOrder returns how the strategy orders a pool for the author. The history it needs is
+// loaded once, the pool may grow tier by tier after that
+func (s *PullRequestService) candidateOrder(ctx context.Context,
+	authorID string) (func([]reviewCandidate) []reviewCandidate, error) {
+	switch s.strategy {
+	case model.PairingDiversityStrategy:
+		pairings, err := s.historyRepository.GetReviewersOfAuthor(ctx, authorID, time.Now().Add(-s.pairingWindow))
+		if err != nil {
+			return nil, err
+		}
+		return func(pool []reviewCandidate) []reviewCandidate {
+			return orderByPairings(pool, pairings)
+		}, nil
+	default:
+		return func(pool []reviewCandidate) []reviewCandidate {
+			return pool
+		}, nil
+	}
+}
+
+// orderByPairings moves candidates who reviewed the author within the window to the end of their
+// tier, the most recent reviewer goes last. It sorts a copy of the pool by tier, then by the last
+// pairing with the author and the number of pairings, candidates without pairings keep the pool order
+func orderByPairings(pool []reviewCandidate, pairings map[string]model.ReviewerPairing) []reviewCandidate {
+	ordered := append([]reviewCandidate{}, pool...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].tier != ordered[j].tier {
+			return ordered[i].tier < ordered[j].tier
+		}
+
+		left := pairings[ordered[i].member.UserID]
+		right := pairings[ordered[j].member.UserID]
+		if !left.LastAssignedAt.Equal(right.LastAssignedAt) {
+			return left.LastAssignedAt.Before(right.LastAssignedAt)
+		}
+		return left.Count < right.Count
+	})
+
+	return ordered
+}
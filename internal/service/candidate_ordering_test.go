@@ -0,0 +1,55 @@
+package service
+
+import (
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderByPairings(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	pairing := func(count int, ago time.Duration) model.ReviewerPairing {
+		return model.ReviewerPairing{Count: count, LastAssignedAt: now.Add(-ago)}
+	}
+
+	pool := []reviewCandidate{
+		candidate("t1", model.MEMBER, 0),
+		candidate("t2", model.MEMBER, 0),
+		candidate("t3", model.MEMBER, 0),
+		candidate("f1", model.MEMBER, 1),
+		candidate("f2", model.MEMBER, 1),
+		candidate("h1", model.MEMBER, 2),
+	}
+
+	tests := []struct {
+		name     string
+		pairings map[string]model.ReviewerPairing
+		want     []string
+	}{
+		{name: "no history keeps the pool", want: []string{"t1", "t2", "t3", "f1", "f2", "h1"}},
+		{name: "recent reviewers go last, the latest at the end", pairings: map[string]model.ReviewerPairing{
+			"t1": pairing(1, time.Hour), "t2": pairing(5, 48*time.Hour)},
+			want: []string{"t3", "t2", "t1", "f1", "f2", "h1"}},
+		{name: "same last pairing, fewer pairings first", pairings: map[string]model.ReviewerPairing{
+			"t1": pairing(3, time.Hour), "t2": pairing(1, time.Hour)},
+			want: []string{"t3", "t2", "t1", "f1", "f2", "h1"}},
+		{name: "tiers are never mixed", pairings: map[string]model.ReviewerPairing{
+			"t1": pairing(1, time.Hour), "t2": pairing(1, time.Hour), "t3": pairing(1, time.Hour),
+			"f1": pairing(1, time.Hour)},
+			want: []string{"t1", "t2", "t3", "f2", "f1", "h1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered := orderByPairings(pool, tt.pairings)
+			if got := candidateIDs(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := candidateIDs(pool); !reflect.DeepEqual(got, []string{"t1", "t2", "t3", "f1", "f2", "h1"}) {
+		t.Errorf("pool was reordered in place: %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/mail"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+	"time"
+)
+
+// Notifier delivers review digests to users
+type Notifier interface {
+	SendDigest(ctx context.Context, digest model.ReviewDigest) error
+}
+
+type DigestService struct {
+	userRepository        *repository.UserRepository
+	prReviewersRepository *repository.PrReviewersRepository
+	notifier              Notifier
+	logger                *slog.Logger
+}
+
+func NewDigestService(userRepo *repository.UserRepository, prReviewsRepo *repository.PrReviewersRepository,
+	notifier Notifier, logger *slog.Logger) *DigestService {
+	return &DigestService{userRepository: userRepo, prReviewersRepository: prReviewsRepo, notifier: notifier,
+		logger: logger}
+}
+
+func (s *DigestService) SetDigestSettings(ctx context.Context, settings model.DigestSettings) (*model.DigestSettings, error) {
+	if !settings.Frequency.IsValid() {
+		return nil, model.NewError(model.BadRequest, "unknown digest frequency %s", settings.Frequency)
+	}
+
+	email, err := digestEmail(settings)
+	if err != nil {
+		return nil, err
+	}
+	settings.Email = email
+
+	return s.userRepository.UpdateDigestSettings(ctx, settings)
+}
+
+// digestEmail checks the digest address and returns it without a display name,
+// so only the bare address reaches the SMTP envelope
+func digestEmail(settings model.DigestSettings) (string, error) {
+	if settings.Email == "" {
+		if settings.Frequency != model.NONE {
+			return "", model.NewError(model.BadRequest, "email is required for %s digest", settings.Frequency)
+		}
+		return "", nil
+	}
+
+	addr, err := mail.ParseAddress(settings.Email)
+	if err != nil {
+		return "", model.NewError(model.BadRequest, "invalid email %s", settings.Email)
+	}
+	return addr.Address, nil
+}
+
+// SendDigests notifies every user whose digest is due. Users with nothing to review
+// get no message but are marked as done for the period
+func (s *DigestService) SendDigests(ctx context.Context) error {
+	digests, err := s.userRepository.GetUsersDueForDigest(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, digest := range digests {
+		err = s.sendDigest(ctx, digest)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "unable to send digest", "user_id", digest.UserID, "error", err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (s *DigestService) sendDigest(ctx context.Context, digest model.ReviewDigest) error {
+	now := time.Now()
+
+	items, err := s.getPendingReviews(ctx, digest.UserID, now)
+	if err != nil {
+		return err
+	}
+
+	if len(items) > 0 {
+		digest.PullRequests = items
+		err = s.notifier.SendDigest(ctx, digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.userRepository.MarkDigestSent(ctx, digest.UserID, now)
+}
+
+// getPendingReviews lists open PRs the user still has to review, already reviewed ones are left out
+func (s *DigestService) getPendingReviews(ctx context.Context, userID string, now time.Time) ([]model.DigestItem, error) {
+	items, err := s.prReviewersRepository.GetOpenReviewsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].AgeHours = int(now.Sub(items[i].CreatedAt).Hours())
+	}
+	return items, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"pr-assignment/internal/model"
+	"testing"
+)
+
+func TestDigestEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings model.DigestSettings
+		want     string
+		wantErr  bool
+	}{
+		{name: "plain address", settings: model.DigestSettings{Email: "alice@example.com", Frequency: model.DAILY},
+			want: "alice@example.com"},
+		{name: "display name is dropped",
+			settings: model.DigestSettings{Email: "Alice <alice@example.com>", Frequency: model.WEEKLY},
+			want:     "alice@example.com"},
+		{name: "no email without digest", settings: model.DigestSettings{Frequency: model.NONE}},
+		{name: "no email for daily digest", settings: model.DigestSettings{Frequency: model.DAILY}, wantErr: true},
+		{name: "not an address", settings: model.DigestSettings{Email: "alice", Frequency: model.DAILY},
+			wantErr: true},
+		{name: "header injection", settings: model.DigestSettings{Email: "alice@example.com\r\nBcc: eve@example.com",
+			Frequency: model.DAILY}, wantErr: true},
+		{name: "invalid even without digest", settings: model.DigestSettings{Email: "@", Frequency: model.NONE},
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := digestEmail(tt.settings)
+			if tt.wantErr {
+				var customErr *model.CustomError
+				if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+					t.Fatalf("err = %v, want a bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("email = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/model"
+	"sort"
+)
+
+// DirectorySource lists groups with their members from the source of truth for teams
+type DirectorySource interface {
+	Name() string
+	Groups(ctx context.Context) ([]model.DirectoryGroup, error)
+}
+
+// DirectorySyncService keeps teams, membership and is_active in line with the directory.
+// Groups become teams through the roster import with reconcile, so users of those teams missing
+// from the directory are deactivated and their reviews reassigned. Teams the directory does not
+// manage are left alone
+type DirectorySyncService struct {
+	source        DirectorySource
+	rosterService *RosterService
+	// group name to team name, empty maps every group to the team of the same name
+	teamMap map[string]string
+	logger  *slog.Logger
+}
+
+func NewDirectorySyncService(source DirectorySource, rosterService *RosterService, teamMap map[string]string,
+	logger *slog.Logger) *DirectorySyncService {
+	return &DirectorySyncService{source: source, rosterService: rosterService, teamMap: teamMap, logger: logger}
+}
+
+// Sync is the scheduler job
+func (s *DirectorySyncService) Sync(ctx context.Context) error {
+	_, err := s.Run(ctx, false)
+	return err
+}
+
+// Run reads the directory and imports it as a roster, dryRun only reports the diff
+func (s *DirectorySyncService) Run(ctx context.Context, dryRun bool) (*model.RosterImportResult, error) {
+	ctx, span := startSpan(ctx, "DirectorySyncService.Run", directorySourceKey.String(s.source.Name()),
+		dryRunKey.Bool(dryRun))
+	defer span.End()
+
+	groups, err := s.source.Groups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := s.rosterEntries(ctx, groups)
+	if len(entries) == 0 {
+		// an empty answer is a broken query or mapping far more often than an empty company
+		return nil, model.NewError(model.BadRequest, "directory %s returned no members of mapped groups",
+			s.source.Name())
+	}
+
+	result, err := s.rosterService.Import(ctx, entries, true, s.managedTeams(groups), dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "directory synced", "source", s.source.Name(), "dry_run", dryRun,
+		"created", result.Summary[model.RosterCreated], "updated", result.Summary[model.RosterUpdated],
+		"deactivated", result.Summary[model.RosterDeactivated])
+	return result, nil
+}
+
+// managedTeams are the teams the directory is the source of truth for: every team of the team map,
+// so emptying a mapped group deactivates its team, or every group the directory returned
+func (s *DirectorySyncService) managedTeams(groups []model.DirectoryGroup) []string {
+	seen := make(map[string]bool)
+	teams := make([]string, 0)
+	add := func(teamName string) {
+		if !seen[teamName] {
+			seen[teamName] = true
+			teams = append(teams, teamName)
+		}
+	}
+
+	if len(s.teamMap) > 0 {
+		for _, teamName := range s.teamMap {
+			add(teamName)
+		}
+	} else {
+		for _, group := range groups {
+			add(group.Name)
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// rosterEntries maps groups to teams. A user in several mapped groups stays in the first
+// group by name, the others are logged
+func (s *DirectorySyncService) rosterEntries(ctx context.Context, groups []model.DirectoryGroup) []model.RosterEntry {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	entries := make([]model.RosterEntry, 0)
+	teamOf := make(map[string]string)
+	for _, group := range groups {
+		teamName := group.Name
+		if len(s.teamMap) > 0 {
+			mapped, ok := s.teamMap[group.Name]
+			if !ok {
+				continue
+			}
+			teamName = mapped
+		}
+
+		for _, member := range group.Members {
+			if first, ok := teamOf[member.UserID]; ok {
+				if first != teamName {
+					s.logger.WarnContext(ctx, "user is in several directory groups, keeping the first",
+						"user_id", member.UserID, "team_name", first, "skipped_team_name", teamName)
+				}
+				continue
+			}
+			teamOf[member.UserID] = teamName
+
+			username := member.Username
+			if username == "" {
+				username = member.UserID
+			}
+			entries = append(entries, model.RosterEntry{TeamName: teamName, UserID: member.UserID,
+				Username: username, IsActive: member.IsActive})
+		}
+	}
+
+	return entries
+}
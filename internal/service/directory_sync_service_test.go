@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+)
+
+func testGroups() []model.DirectoryGroup {
+	return []model.DirectoryGroup{
+		{Name: "frontend-devs", Members: []model.DirectoryUser{
+			{UserID: "u3", Username: "Carol", IsActive: true},
+			{UserID: "u1", Username: "Alice", IsActive: true},
+		}},
+		{Name: "backend-devs", Members: []model.DirectoryUser{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", IsActive: false},
+		}},
+		{Name: "all-staff", Members: []model.DirectoryUser{
+			{UserID: "u4", Username: "Dave", IsActive: true},
+		}},
+	}
+}
+
+func TestDirectoryRosterEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		teamMap     map[string]string
+		want        []model.RosterEntry
+		wantManaged []string
+	}{
+		{
+			name: "every group is a team, a user stays in the first group by name",
+			want: []model.RosterEntry{
+				{TeamName: "all-staff", UserID: "u4", Username: "Dave", IsActive: true},
+				{TeamName: "backend-devs", UserID: "u1", Username: "Alice", IsActive: true},
+				// username falls back to the id
+				{TeamName: "backend-devs", UserID: "u2", Username: "u2", IsActive: false},
+				{TeamName: "frontend-devs", UserID: "u3", Username: "Carol", IsActive: true},
+			},
+			wantManaged: []string{"all-staff", "backend-devs", "frontend-devs"},
+		},
+		{
+			name:    "unmapped groups are ignored",
+			teamMap: map[string]string{"frontend-devs": "frontend", "backend-devs": "backend", "qa": "qa"},
+			want: []model.RosterEntry{
+				{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true},
+				{TeamName: "backend", UserID: "u2", Username: "u2", IsActive: false},
+				{TeamName: "frontend", UserID: "u3", Username: "Carol", IsActive: true},
+			},
+			// a mapped group missing from the directory still manages its team
+			wantManaged: []string{"backend", "frontend", "qa"},
+		},
+		{
+			name:    "two groups mapped to one team",
+			teamMap: map[string]string{"frontend-devs": "web", "backend-devs": "web"},
+			want: []model.RosterEntry{
+				{TeamName: "web", UserID: "u1", Username: "Alice", IsActive: true},
+				{TeamName: "web", UserID: "u2", Username: "u2", IsActive: false},
+				{TeamName: "web", UserID: "u3", Username: "Carol", IsActive: true},
+			},
+			wantManaged: []string{"web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDirectorySyncService(nil, nil, tt.teamMap, slog.New(slog.DiscardHandler))
+			groups := testGroups()
+
+			entries := s.rosterEntries(context.Background(), groups)
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", entries, tt.want)
+			}
+			if managed := s.managedTeams(groups); !reflect.DeepEqual(managed, tt.wantManaged) {
+				t.Errorf("managed teams = %v, want %v", managed, tt.wantManaged)
+			}
+		})
+	}
+}
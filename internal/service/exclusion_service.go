@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+)
+
+type ExclusionService struct {
+	exclusionRepository *repository.ExclusionRepository
+	userRepository      *repository.UserRepository
+}
+
+func NewExclusionService(exclusionRepo *repository.ExclusionRepository, userRepo *repository.UserRepository) *ExclusionService {
+	return &ExclusionService{exclusionRepository: exclusionRepo, userRepository: userRepo}
+}
+
+func (s *ExclusionService) AddExclusion(ctx context.Context, exclusion model.ReviewerExclusion) (*model.ReviewerExclusion, error) {
+	if exclusion.AuthorID == exclusion.ReviewerID {
+		return nil, model.NewError(model.BadRequest, "user %s can not be excluded from own reviews", exclusion.AuthorID)
+	}
+
+	_, err := s.userRepository.GetUserByID(ctx, exclusion.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.userRepository.GetUserByID(ctx, exclusion.ReviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.exclusionRepository.AddExclusion(ctx, exclusion)
+}
+
+func (s *ExclusionService) RemoveExclusion(ctx context.Context, authorID string, reviewerID string) error {
+	return s.exclusionRepository.RemoveExclusion(ctx, authorID, reviewerID)
+}
+
+func (s *ExclusionService) GetExclusions(ctx context.Context, userID string) ([]model.ReviewerExclusion, error) {
+	_, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.exclusionRepository.GetExclusionsByUser(ctx, userID)
+}
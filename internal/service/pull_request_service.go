@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
 	"pr-assignment/internal/adapter/in/http/dto"
 	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/metrics"
 	"pr-assignment/internal/model"
 	"time"
 )
@@ -14,18 +16,41 @@ type PullRequestService struct {
 	prReviewersRepository *repository.PrReviewersRepository
 	teamRepository        *repository.TeamRepository
 	userRepository        *repository.UserRepository
+	historyRepository     *repository.AssignmentHistoryRepository
+	exclusionRepository   *repository.ExclusionRepository
+	declineRepository     *repository.ReviewDeclineRepository
+	decisionRepository    *repository.ReviewDecisionRepository
 	userService           *UserService
+	metrics               *metrics.Metrics
+	logger                *slog.Logger
+	strategy              model.AssignmentStrategy
+	pairingWindow         time.Duration
+	// reviewers per PR when team role rules do not ask for more
+	requiredReviewers int
 }
 
 func NewPullRequestService(prRepo *repository.PullRequestRepository, prReviewsRepo *repository.PrReviewersRepository,
-	teamRepo *repository.TeamRepository, userRepo *repository.UserRepository, userService *UserService) *PullRequestService {
+	teamRepo *repository.TeamRepository, userRepo *repository.UserRepository,
+	historyRepo *repository.AssignmentHistoryRepository, exclusionRepo *repository.ExclusionRepository,
+	declineRepo *repository.ReviewDeclineRepository, decisionRepo *repository.ReviewDecisionRepository,
+	userService *UserService, metrics *metrics.Metrics, logger *slog.Logger,
+	strategy model.AssignmentStrategy, pairingWindow time.Duration, requiredReviewers int) *PullRequestService {
 
 	return &PullRequestService{prRepo, prReviewsRepo,
-		teamRepo, userRepo, userService}
+		teamRepo, userRepo, historyRepo, exclusionRepo, declineRepo, decisionRepo, userService, metrics, logger,
+		strategy, pairingWindow, requiredReviewers}
 }
 
 func (s *PullRequestService) CreatePR(ctx context.Context, prBody dto.PullRequestQuery) (*model.PullRequest, error) {
-	_, err := s.userRepository.GetUserByID(ctx, prBody.AuthorID)
+	ctx, span := startSpan(ctx, "PullRequestService.CreatePR", prIDKey.String(prBody.PullRequestID), prAuthorIDKey.String(prBody.AuthorID))
+	defer span.End()
+
+	author, err := s.userRepository.GetUserByID(ctx, prBody.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID, err := prTeamID(ctx, author, prBody.TeamName, s.teamRepository.GetTeamID, s.userRepository.IsMember)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +63,7 @@ func (s *PullRequestService) CreatePR(ctx context.Context, prBody dto.PullReques
 	}
 	pr := model.PullRequest{
 		PullRequestShort:  pullRequest,
+		TeamID:            teamID,
 		AssignedReviewers: make([]string, 0),
 		CreatedAt:         time.Now(),
 		MergedAt:          time.Time{},
@@ -52,11 +78,41 @@ func (s *PullRequestService) CreatePR(ctx context.Context, prBody dto.PullReques
 	if err != nil {
 		return nil, err
 	}
+	s.metrics.PRCreated()
 
 	return createdPR, nil
 }
 
-func (s *PullRequestService) ChangeReviewer(ctx context.Context, prID string, oldReviewerID string) (*model.ReassignmentResult, error) {
+// prTeamID picks the team that reviews a new PR: the primary team of the author, or teamName
+// when it is given and the author is a member of it
+func prTeamID(ctx context.Context, author *model.User, teamName string,
+	getTeamID func(ctx context.Context, teamName string) (string, error),
+	isMember func(ctx context.Context, userID string, teamID string) (bool, error)) (string, error) {
+	// users.team_name holds the id of the primary team
+	if teamName == "" {
+		return author.TeamName, nil
+	}
+
+	teamID, err := getTeamID(ctx, teamName)
+	if err != nil {
+		return "", err
+	}
+	member, err := isMember(ctx, author.UserID, teamID)
+	if err != nil {
+		return "", err
+	}
+	if !member {
+		return "", model.NewError(model.BadRequest, "author %s is not a member of %s", author.UserID, teamName)
+	}
+	return teamID, nil
+}
+
+func (s *PullRequestService) ChangeReviewer(ctx context.Context, prID string, oldReviewerID string,
+	reason model.ReassignReason) (*model.ReassignmentResult, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.ChangeReviewer", prIDKey.String(prID), reviewerIDKey.String(oldReviewerID),
+		reassignReasonKey.String(string(reason)))
+	defer span.End()
+
 	pullRequest, err := s.prRepository.GetPR(ctx, prID)
 	if err != nil {
 		return nil, err
@@ -65,43 +121,67 @@ func (s *PullRequestService) ChangeReviewer(ctx context.Context, prID string, ol
 	if pullRequest.Status == model.MERGED {
 		return nil, model.NewError(model.PrMerged, "PR already merged")
 	}
-	teamName, err := s.userRepository.GetTeamNameByUserID(ctx, pullRequest.AuthorID)
 
+	reviewers, err := s.prReviewersRepository.GetReviewers(ctx, prID)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
-	teammates, err := s.userRepository.GetActiveUsersByTeam(ctx, teamName)
+	if !s.inReviewers(reviewers, oldReviewerID) {
+		return nil, model.NewError(model.NotAssigned, "Old reviewer was not assigned to PR")
+	}
+
+	teamID := pullRequest.TeamID
+	span.SetAttributes(teamIDKey.String(teamID))
+
+	remaining := make([]string, 0, len(reviewers))
+	for _, reviewerID := range reviewers {
+		if reviewerID != oldReviewerID {
+			remaining = append(remaining, reviewerID)
+		}
+	}
+
+	remainingMembers, err := s.getReviewerMembers(ctx, remaining)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
-	reviewers, err := s.prReviewersRepository.GetReviewers(ctx, prID)
+	// those who declined the PR are not asked again
+	skipped, err := s.declineRepository.GetDecliners(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
+	skipped = append(skipped, oldReviewerID)
 
-	if !s.inReviewers(reviewers, oldReviewerID) {
-		return nil, model.NewError(model.NotAssigned, "Old reviewer was not assigned to PR")
+	candidates, excludedCandidates, err := s.selectReviewers(ctx, teamID, pullRequest.AuthorID, remainingMembers,
+		skipped, 1)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "unable to select new reviewer", "pr_id", prID, "error", err)
+		return nil, err
 	}
 
-	var newReviewerID string
-	newReviewerID = oldReviewerID
-	for _, userID := range teammates {
-		res, _ := s.checkAllowedToReview(reviewers, pullRequest.AuthorID, userID)
+	newReviewerID := oldReviewerID
+	fromFallback := false
+	if len(candidates) > 0 {
+		newReviewerID = candidates[0].member.UserID
+		fromFallback = candidates[0].fromFallback
+	}
 
-		if res {
-			newReviewerID = userID
-			break
+	if newReviewerID != oldReviewerID {
+		err = s.prReviewersRepository.ChangeReviewer(ctx, prID, oldReviewerID, newReviewerID, fromFallback)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "unable to change reviewer", "pr_id", prID,
+				"old_reviewer_id", oldReviewerID, "new_reviewer_id", newReviewerID, "error", err)
+			return nil, err
 		}
-	}
 
-	err = s.prReviewersRepository.ChangeReviewer(ctx, prID, oldReviewerID, newReviewerID)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
+		err = s.historyRepository.AddAssignment(ctx, prID, pullRequest.AuthorID, newReviewerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		s.metrics.Reassigned(reason)
+	} else {
+		s.metrics.NoCandidate(string(reason))
 	}
 
 	pr, err := s.prRepository.GetPR(ctx, prID)
@@ -116,6 +196,12 @@ func (s *PullRequestService) ChangeReviewer(ctx context.Context, prID string, ol
 
 	pr.AssignedReviewers = reviewers
 
+	pr.FallbackReviewers, err = s.prReviewersRepository.GetFallbackReviewers(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	pr.ExcludedCandidates = excludedCandidates
+
 	response := model.ReassignmentResult{
 		PullRequest:   *pr,
 		NewReviewerID: newReviewerID,
@@ -124,21 +210,41 @@ func (s *PullRequestService) ChangeReviewer(ctx context.Context, prID string, ol
 }
 
 func (s *PullRequestService) MergePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.MergePR", prIDKey.String(pullRequestID))
+	defer span.End()
+
+	pullRequest, err := s.prRepository.GetPR(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
 	createdPR, err := s.prRepository.MergePR(ctx, pullRequestID, model.MERGED, time.Now())
 
 	if err != nil {
-		fmt.Println(err)
+		s.logger.ErrorContext(ctx, "unable to merge PR", "pr_id", pullRequestID, "error", err)
 		return nil, err
 	}
+	// merge is idempotent, repeated calls are not counted
+	if pullRequest.Status != model.MERGED {
+		s.metrics.PRMerged()
+	}
 	reviewers, err := s.prReviewersRepository.GetReviewers(ctx, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
 	createdPR.AssignedReviewers = reviewers
+
+	createdPR.FallbackReviewers, err = s.prReviewersRepository.GetFallbackReviewers(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
 	return createdPR, nil
 }
 
 func (s *PullRequestService) GetPRsByUser(ctx context.Context, userID string) ([]*model.PullRequest, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.GetPRsByUser", userIDKey.String(userID))
+	defer span.End()
+
 	pullRequestsIDs, err := s.prReviewersRepository.GetPRsByUser(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -155,17 +261,149 @@ func (s *PullRequestService) GetPRsByUser(ctx context.Context, userID string) ([
 	return prs, nil
 }
 
+// StreamPRs passes matching PRs to fn one by one. Validation errors are returned
+// before fn is called for the first time
+func (s *PullRequestService) StreamPRs(ctx context.Context, query dto.PullRequestListQuery,
+	fn func(*model.PullRequest) error) error {
+	ctx, span := startSpan(ctx, "PullRequestService.StreamPRs", teamNameKey.String(query.TeamName),
+		prAuthorIDKey.String(query.AuthorID))
+	defer span.End()
+
+	if query.Status != "" && query.Status != model.CREATED && query.Status != model.MERGED {
+		return model.NewError(model.BadRequest, "status must be %s or %s", model.CREATED, model.MERGED)
+	}
+
+	if query.TeamName != "" {
+		if _, err := s.teamRepository.GetTeamID(ctx, query.TeamName); err != nil {
+			return err
+		}
+	}
+
+	return s.prRepository.StreamPRs(ctx, query.TeamName, query.AuthorID, query.Status, fn)
+}
+
 func (s *PullRequestService) ReassignReviewsAfterDeath(ctx context.Context, deadReviewerID string) error {
+	ctx, span := startSpan(ctx, "PullRequestService.ReassignReviewsAfterDeath", userIDKey.String(deadReviewerID))
+	defer span.End()
+
 	pullRequestsIDs, err := s.prReviewersRepository.GetPRsByUser(ctx, deadReviewerID)
+	var customErr *model.CustomError
+	if errors.As(err, &customErr) && customErr.Code == model.NotFound {
+		// the user reviews nothing
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	for _, prID := range pullRequestsIDs {
-		_, err := s.ChangeReviewer(ctx, prID, deadReviewerID)
+		_, err := s.ChangeReviewer(ctx, prID, deadReviewerID, model.DeactivationReassign)
+		var customErr *model.CustomError
+		if errors.As(err, &customErr) && customErr.Code == model.PrMerged {
+			// reviews of merged PRs stay as they were
+			continue
+		}
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// ReassignPendingReviews picks new reviewers of an open PR for everyone who has not accepted or
+// reviewed it yet and is not an active member of the PR's team, for example after the author
+// changed teams. Fallback reviewers stay. Returns the number of replaced reviewers
+func (s *PullRequestService) ReassignPendingReviews(ctx context.Context, prID string,
+	reason model.ReassignReason) (int, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.ReassignPendingReviews", prIDKey.String(prID),
+		reassignReasonKey.String(string(reason)))
+	defer span.End()
+
+	pullRequest, err := s.prRepository.GetPR(ctx, prID)
+	if err != nil {
+		return 0, err
+	}
+
+	span.SetAttributes(teamIDKey.String(pullRequest.TeamID))
+
+	teammates, err := s.userRepository.GetActiveMembersByTeam(ctx, pullRequest.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	teammateIDs := make([]string, 0, len(teammates))
+	for _, member := range teammates {
+		teammateIDs = append(teammateIDs, member.UserID)
+	}
+
+	pending, err := s.prReviewersRepository.GetPendingReviewers(ctx, prID)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	for _, reviewerID := range pending {
+		if s.inReviewers(teammateIDs, reviewerID) {
+			continue
+		}
+		result, err := s.ChangeReviewer(ctx, prID, reviewerID, reason)
+		if err != nil {
+			return replaced, err
+		}
+		if result.NewReviewerID != reviewerID {
+			replaced++
+		}
+	}
+	return replaced, nil
+}
+
+// ReassignAuthoredReviews runs ReassignPendingReviews on every open PR of the author
+func (s *PullRequestService) ReassignAuthoredReviews(ctx context.Context, authorID string,
+	reason model.ReassignReason) (int, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.ReassignAuthoredReviews", prAuthorIDKey.String(authorID),
+		reassignReasonKey.String(string(reason)))
+	defer span.End()
+
+	prIDs, err := s.prRepository.GetOpenPRIDsByAuthor(ctx, authorID)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	for _, prID := range prIDs {
+		count, err := s.ReassignPendingReviews(ctx, prID, reason)
+		replaced += count
+		if err != nil {
+			return replaced, err
+		}
+	}
+	return replaced, nil
+}
+
+// ReassignReviewsAfterMove hands unfinished reviews of a user who changed teams back to the teams
+// of the PRs, reviews of PRs of the user's teams and fallback reviews stay. Returns the number of replaced reviews
+func (s *PullRequestService) ReassignReviewsAfterMove(ctx context.Context, reviewerID string) (int, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.ReassignReviewsAfterMove", reviewerIDKey.String(reviewerID))
+	defer span.End()
+
+	prIDs, err := s.prReviewersRepository.GetUnfinishedReviewsOutsideTeams(ctx, reviewerID)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	for _, prID := range prIDs {
+		result, err := s.ChangeReviewer(ctx, prID, reviewerID, model.TeamChangeReassign)
+		var customErr *model.CustomError
+		if errors.As(err, &customErr) && customErr.Code == model.PrMerged {
+			// merged in the meantime
+			continue
+		}
+		if err != nil {
+			return replaced, err
+		}
+		if result.NewReviewerID != reviewerID {
+			replaced++
+		}
+	}
+	return replaced, nil
+}
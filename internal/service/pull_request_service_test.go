@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pr-assignment/internal/model"
+	"testing"
+)
+
+func TestPRTeamID(t *testing.T) {
+	author := &model.User{UserID: "u1", TeamName: "id-backend"}
+	teamIDs := map[string]string{"backend": "id-backend", "platform": "id-platform", "frontend": "id-frontend"}
+	memberships := map[string]bool{"id-backend": true, "id-platform": true}
+
+	getTeamID := func(_ context.Context, teamName string) (string, error) {
+		teamID, ok := teamIDs[teamName]
+		if !ok {
+			return "", model.NewError(model.NotFound, "team %s not found", teamName)
+		}
+		return teamID, nil
+	}
+	isMember := func(_ context.Context, userID string, teamID string) (bool, error) {
+		if userID != author.UserID {
+			t.Fatalf("membership of %s checked, want %s", userID, author.UserID)
+		}
+		return memberships[teamID], nil
+	}
+
+	tests := []struct {
+		name     string
+		teamName string
+		want     string
+		wantCode model.ErrCode
+	}{
+		{name: "primary team by default", want: "id-backend"},
+		{name: "primary team by name", teamName: "backend", want: "id-backend"},
+		{name: "secondary team", teamName: "platform", want: "id-platform"},
+		{name: "team of someone else", teamName: "frontend", wantCode: model.BadRequest},
+		{name: "unknown team", teamName: "mobile", wantCode: model.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prTeamID(context.Background(), author, tt.teamName, getTeamID, isMember)
+			if tt.wantCode != "" {
+				var customErr *model.CustomError
+				if !errors.As(err, &customErr) || customErr.Code != tt.wantCode {
+					t.Fatalf("err = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("team = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,22 @@ package service
 
 import (
 	"context"
+	"pr-assignment/internal/metrics"
 	"pr-assignment/internal/model"
+	"sort"
+	"time"
 )
 
-func (s *PullRequestService) checkAllowedToReview(reviewers []string, authorID string, newReviewerID string) (bool, error) {
+type reviewCandidate struct {
+	member       model.TeamMember
+	fromFallback bool
+	// 0 for the home team, 1 for fallback teams, the hierarchy walk counts up from 2 with
+	// every team, candidates of a lower tier always go first
+	tier int
+}
+
+func (s *PullRequestService) checkAllowedToReview(reviewers []string, exclusions []string, authorID string,
+	newReviewerID string) (bool, error) {
 
 	if authorID == newReviewerID {
 		return false, nil
@@ -17,26 +29,291 @@ func (s *PullRequestService) checkAllowedToReview(reviewers []string, authorID s
 		}
 	}
 
+	for id := range exclusions {
+		if exclusions[id] == newReviewerID {
+			return false, model.NewError(model.Excluded, "%s is excluded from reviews of %s", newReviewerID, authorID)
+		}
+	}
+
 	return true, nil
 }
 
-func (s *PullRequestService) AssignReviewers(ctx context.Context, pr *model.PullRequest) error {
-	teammates, err := s.userService.GetActiveTeammatesByUser(ctx, pr.AuthorID)
+// selectReviewers picks up to count new reviewers for the author. Role rules of the team
+// are satisfied first, the rest of the slots go to anyone allowed. Teammates always go before
+// members of fallback teams and fallback teams before the rest of the hierarchy, a tier is only
+// loaded when the ones before it can not fill every slot and rule. Skipped users
+// are never picked. Second result lists candidates
+// that were passed over only because of the author's exclusion list
+func (s *PullRequestService) selectReviewers(ctx context.Context, teamID string, authorID string,
+	reviewers []model.TeamMember, skipped []string, count int) ([]reviewCandidate, []string, error) {
+	rules, err := s.teamRepository.GetRoleRules(ctx, teamID)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	exclusions, err := s.exclusionRepository.GetExcludedReviewers(ctx, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, err := s.candidateOrder(ctx, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := make([]reviewCandidate, 0)
+	added := make(map[string]bool)
+	var selected []reviewCandidate
+	var excludedCandidates []string
+
+	// picks again over the whole pool with the tier added. Once the picks are complete a later
+	// tier could only be picked after them, so loading it would not change the result
+	addTier := func(teamIDs []string, tier int) (bool, error) {
+		pool, err = s.addTeamCandidates(ctx, pool, newTeams(added, teamIDs), tier)
+		if err != nil {
+			return false, err
+		}
+		selected, excludedCandidates = s.pickReviewers(order(pool), rules, exclusions, authorID, reviewers,
+			skipped, count)
+		return s.selectionComplete(selected, rules, reviewers, count), nil
+	}
+
+	done, err := addTier([]string{teamID}, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if done {
+		return selected, excludedCandidates, nil
+	}
+
+	fallbackTeams, err := s.teamRepository.GetFallbackTeamIDs(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	done, err = addTier(fallbackTeams, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if done {
+		return selected, excludedCandidates, nil
+	}
+
+	hierarchy, err := s.teamRepository.GetHierarchyTeams(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, hierarchyID := range hierarchyWalk(teamID, hierarchy) {
+		done, err = addTier([]string{hierarchyID}, 2+i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if done {
+			break
+		}
+	}
+
+	return selected, excludedCandidates, nil
+}
+
+// selectionComplete tells if the picks fill every slot and meet every role rule
+func (s *PullRequestService) selectionComplete(selected []reviewCandidate, rules []model.RoleRule,
+	reviewers []model.TeamMember, count int) bool {
+	if len(selected) < count {
+		return false
+	}
+	for _, rule := range rules {
+		if s.countWithRole(reviewers, selected, rule.Role) < rule.MinCount {
+			return false
+		}
+	}
+	return true
+}
+
+// pickReviewers walks the ordered pool once per role rule and once more for the free slots
+func (s *PullRequestService) pickReviewers(pool []reviewCandidate, rules []model.RoleRule, exclusions []string,
+	authorID string, reviewers []model.TeamMember, skipped []string, count int) ([]reviewCandidate, []string) {
+	selected := make([]reviewCandidate, 0, count)
+	excludedCandidates := make([]string, 0)
+	taken := make([]string, 0, len(reviewers)+len(skipped)+count)
+	taken = append(taken, skipped...)
+	for _, reviewer := range reviewers {
+		taken = append(taken, reviewer.UserID)
 	}
 
-	for _, userID := range teammates {
-		if userID != pr.AuthorID {
-			pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
-			err = s.prReviewersRepository.AddReviewer(ctx, pr.PullRequestID, userID)
-			if err != nil {
-				return err
+	pick := func(role model.UserRole, limit int) {
+		for _, candidate := range pool {
+			if len(selected) == count || limit == 0 {
+				return
+			}
+			if !candidate.member.Role.AtLeast(role) {
+				continue
 			}
-			if len(pr.AssignedReviewers) == 2 {
-				break
+			res, err := s.checkAllowedToReview(taken, exclusions, authorID, candidate.member.UserID)
+			if err != nil && !s.inReviewers(excludedCandidates, candidate.member.UserID) {
+				excludedCandidates = append(excludedCandidates, candidate.member.UserID)
+			}
+			if res {
+				selected = append(selected, candidate)
+				taken = append(taken, candidate.member.UserID)
+				limit--
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		missing := rule.MinCount - s.countWithRole(reviewers, selected, rule.Role)
+		if missing > 0 {
+			pick(rule.Role, missing)
+		}
+	}
+	pick(model.MEMBER, count-len(selected))
+
+	return selected, excludedCandidates
+}
+
+// addTeamCandidates appends active members of the teams to the pool, anyone outside the home
+// team comes from a fallback
+func (s *PullRequestService) addTeamCandidates(ctx context.Context, pool []reviewCandidate, teamIDs []string,
+	tier int) ([]reviewCandidate, error) {
+	for _, teamID := range teamIDs {
+		members, err := s.userRepository.GetActiveMembersByTeam(ctx, teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			pool = append(pool, reviewCandidate{member: member, fromFallback: tier > 0, tier: tier})
+		}
+	}
+	return pool, nil
+}
+
+// newTeams returns the teams that are not in the pool yet and marks them as added
+func newTeams(added map[string]bool, teamIDs []string) []string {
+	teams := make([]string, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		if added[teamID] {
+			continue
+		}
+		added[teamID] = true
+		teams = append(teams, teamID)
+	}
+	return teams
+}
+
+// hierarchyWalk orders the teams the fallback walk reaches from the team: at every level up the
+// siblings ordered by name go first, then the parent, so the nearest teams come first
+func hierarchyWalk(teamID string, nodes []model.TeamNode) []string {
+	byID := make(map[string]model.TeamNode, len(nodes))
+	children := make(map[string][]model.TeamNode)
+	for _, node := range nodes {
+		byID[node.TeamID] = node
+		if node.ParentID != "" {
+			children[node.ParentID] = append(children[node.ParentID], node)
+		}
+	}
+
+	walk := make([]string, 0, len(nodes))
+	current, ok := byID[teamID]
+	for ok && current.ParentID != "" {
+		siblings := children[current.ParentID]
+		sort.Slice(siblings, func(i, j int) bool {
+			return siblings[i].TeamName < siblings[j].TeamName
+		})
+		for _, sibling := range siblings {
+			if sibling.TeamID != current.TeamID {
+				walk = append(walk, sibling.TeamID)
 			}
 		}
+		walk = append(walk, current.ParentID)
+		current, ok = byID[current.ParentID]
+	}
+	return walk
+}
+
+func (s *PullRequestService) countWithRole(reviewers []model.TeamMember, selected []reviewCandidate, role model.UserRole) int {
+	count := 0
+	for _, reviewer := range reviewers {
+		if reviewer.Role.AtLeast(role) {
+			count++
+		}
+	}
+	for _, candidate := range selected {
+		if candidate.member.Role.AtLeast(role) {
+			count++
+		}
+	}
+	return count
+}
+
+// number of reviewers to assign so that every role rule of the team can be met
+func (s *PullRequestService) getReviewersCount(ctx context.Context, teamID string) (int, error) {
+	rules, err := s.teamRepository.GetRoleRules(ctx, teamID)
+	if err != nil {
+		return 0, err
+	}
+
+	count := s.requiredReviewers
+	for _, rule := range rules {
+		count = max(count, rule.MinCount)
+	}
+	return count, nil
+}
+
+func (s *PullRequestService) getReviewerMembers(ctx context.Context, reviewerIDs []string) ([]model.TeamMember, error) {
+	members := make([]model.TeamMember, 0, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		user, err := s.userRepository.GetUserByID(ctx, reviewerID)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, model.TeamMember{UserID: user.UserID, Username: user.Username,
+			IsActive: user.IsActive, Role: user.Role})
+	}
+	return members, nil
+}
+
+func (s *PullRequestService) AssignReviewers(ctx context.Context, pr *model.PullRequest) error {
+	ctx, span := startSpan(ctx, "PullRequestService.AssignReviewers", prIDKey.String(pr.PullRequestID),
+		prAuthorIDKey.String(pr.AuthorID))
+	defer span.End()
+
+	teamID := pr.TeamID
+	span.SetAttributes(teamIDKey.String(teamID))
+
+	reviewersCount, err := s.getReviewersCount(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	reviewers, err := s.getReviewerMembers(ctx, pr.AssignedReviewers)
+	if err != nil {
+		return err
+	}
+
+	candidates, excludedCandidates, err := s.selectReviewers(ctx, teamID, pr.AuthorID, reviewers, nil,
+		reviewersCount-len(reviewers))
+	if err != nil {
+		return err
+	}
+	pr.ExcludedCandidates = excludedCandidates
+
+	for _, candidate := range candidates {
+		err = s.prReviewersRepository.AddReviewer(ctx, pr.PullRequestID, candidate.member.UserID, candidate.fromFallback)
+		if err != nil {
+			return err
+		}
+		err = s.historyRepository.AddAssignment(ctx, pr.PullRequestID, pr.AuthorID, candidate.member.UserID, time.Now())
+		if err != nil {
+			return err
+		}
+		pr.AssignedReviewers = append(pr.AssignedReviewers, candidate.member.UserID)
+		if candidate.fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, candidate.member.UserID)
+		}
+	}
+
+	if len(pr.AssignedReviewers) < reviewersCount {
+		s.metrics.NoCandidate(metrics.AssignOperation)
 	}
 	return nil
 }
@@ -51,3 +328,56 @@ func (s *PullRequestService) inReviewers(reviewers []string, oldReviewerID strin
 	}
 	return flag
 }
+
+// AddLeadReviewer puts an active lead of the PR's team on the PR as an extra reviewer, secondary
+// members of the team included.
+// Returns empty id when there is no lead to add
+func (s *PullRequestService) AddLeadReviewer(ctx context.Context, prID string) (string, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.AddLeadReviewer", prIDKey.String(prID))
+	defer span.End()
+
+	pullRequest, err := s.prRepository.GetPR(ctx, prID)
+	if err != nil {
+		return "", err
+	}
+
+	teamID := pullRequest.TeamID
+	span.SetAttributes(teamIDKey.String(teamID))
+
+	reviewers, err := s.prReviewersRepository.GetReviewers(ctx, prID)
+	if err != nil {
+		return "", err
+	}
+
+	exclusions, err := s.exclusionRepository.GetExcludedReviewers(ctx, pullRequest.AuthorID)
+	if err != nil {
+		return "", err
+	}
+
+	teammates, err := s.userRepository.GetActiveMembersByTeam(ctx, teamID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, member := range teammates {
+		if member.Role != model.LEAD {
+			continue
+		}
+		res, _ := s.checkAllowedToReview(reviewers, exclusions, pullRequest.AuthorID, member.UserID)
+		if !res {
+			continue
+		}
+
+		err = s.prReviewersRepository.AddReviewer(ctx, prID, member.UserID, false)
+		if err != nil {
+			return "", err
+		}
+		err = s.historyRepository.AddAssignment(ctx, prID, pullRequest.AuthorID, member.UserID, time.Now())
+		if err != nil {
+			return "", err
+		}
+		return member.UserID, nil
+	}
+
+	return "", nil
+}
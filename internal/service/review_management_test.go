@@ -0,0 +1,241 @@
+package service
+
+import (
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+)
+
+func candidate(userID string, role model.UserRole, tier int) reviewCandidate {
+	return reviewCandidate{member: model.TeamMember{UserID: userID, IsActive: true, Role: role},
+		fromFallback: tier > 0, tier: tier}
+}
+
+func candidateIDs(candidates []reviewCandidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.member.UserID)
+	}
+	return ids
+}
+
+func TestPickReviewersFallback(t *testing.T) {
+	pool := []reviewCandidate{
+		candidate("author", model.MEMBER, 0),
+		candidate("t1", model.MEMBER, 0),
+		candidate("t2", model.MEMBER, 0),
+		candidate("f1", model.MEMBER, 1),
+		candidate("f2", model.MEMBER, 1),
+	}
+
+	tests := []struct {
+		name         string
+		reviewers    []model.TeamMember
+		skipped      []string
+		exclusions   []string
+		count        int
+		want         []string
+		wantExcluded []string
+	}{
+		{name: "teammates first", count: 2, want: []string{"t1", "t2"}},
+		{name: "fallback fills the rest", count: 3, want: []string{"t1", "t2", "f1"}},
+		{name: "current reviewers are not picked again", reviewers: []model.TeamMember{{UserID: "t1"}},
+			count: 2, want: []string{"t2", "f1"}},
+		{name: "skipped users are never picked", skipped: []string{"t2", "f1"}, count: 2,
+			want: []string{"t1", "f2"}},
+		{name: "exclusions are reported", exclusions: []string{"t1"}, count: 2, want: []string{"t2", "f1"},
+			wantExcluded: []string{"t1"}},
+		{name: "pool runs out", count: 5, want: []string{"t1", "t2", "f1", "f2"}},
+	}
+
+	s := &PullRequestService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, excluded := s.pickReviewers(pool, nil, tt.exclusions, "author", tt.reviewers, tt.skipped,
+				tt.count)
+			if got := candidateIDs(selected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected = %v, want %v", got, tt.want)
+			}
+			if tt.wantExcluded == nil {
+				tt.wantExcluded = []string{}
+			}
+			if !reflect.DeepEqual(excluded, tt.wantExcluded) {
+				t.Errorf("excluded = %v, want %v", excluded, tt.wantExcluded)
+			}
+		})
+	}
+}
+
+func TestPickReviewersRoleRules(t *testing.T) {
+	pool := []reviewCandidate{
+		candidate("m1", model.MEMBER, 0),
+		candidate("m2", model.MEMBER, 0),
+		candidate("s1", model.SENIOR, 0),
+		candidate("l1", model.LEAD, 0),
+		candidate("fs", model.SENIOR, 1),
+	}
+
+	tests := []struct {
+		name      string
+		rules     []model.RoleRule
+		reviewers []model.TeamMember
+		count     int
+		want      []string
+	}{
+		{name: "no rules keep the pool order", count: 2, want: []string{"m1", "m2"}},
+		{name: "senior slot first, a lead counts as senior", rules: []model.RoleRule{{Role: model.SENIOR, MinCount: 2}},
+			count: 3, want: []string{"s1", "l1", "m1"}},
+		{name: "rules in their order", rules: []model.RoleRule{
+			{Role: model.LEAD, MinCount: 1}, {Role: model.SENIOR, MinCount: 2}},
+			count: 2, want: []string{"l1", "s1"}},
+		{name: "current reviewers count towards rules", rules: []model.RoleRule{{Role: model.SENIOR, MinCount: 1}},
+			reviewers: []model.TeamMember{{UserID: "x", Role: model.LEAD}}, count: 1, want: []string{"m1"}},
+		{name: "fallback seniors fill a rule the team can not", rules: []model.RoleRule{
+			{Role: model.SENIOR, MinCount: 3}}, count: 3, want: []string{"s1", "l1", "fs"}},
+		{name: "a rule never takes more than the free slots", rules: []model.RoleRule{
+			{Role: model.SENIOR, MinCount: 3}}, count: 1, want: []string{"s1"}},
+	}
+
+	s := &PullRequestService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, _ := s.pickReviewers(pool, tt.rules, nil, "author", tt.reviewers, nil, tt.count)
+			if got := candidateIDs(selected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHierarchyWalk(t *testing.T) {
+	// root
+	// ├── a
+	// ├── b
+	// │   ├── b1
+	// │   ├── b2
+	// │   └── b3
+	// └── c
+	nodes := []model.TeamNode{
+		{TeamID: "id-c", TeamName: "c", ParentID: "id-root"},
+		{TeamID: "id-b3", TeamName: "b3", ParentID: "id-b"},
+		{TeamID: "id-root", TeamName: "root"},
+		{TeamID: "id-b2", TeamName: "b2", ParentID: "id-b"},
+		{TeamID: "id-a", TeamName: "a", ParentID: "id-root"},
+		{TeamID: "id-b1", TeamName: "b1", ParentID: "id-b"},
+		{TeamID: "id-b", TeamName: "b", ParentID: "id-root"},
+	}
+
+	tests := []struct {
+		name   string
+		teamID string
+		want   []string
+	}{
+		{name: "siblings by name, then the parent, level by level", teamID: "id-b2",
+			want: []string{"id-b1", "id-b3", "id-b", "id-a", "id-c", "id-root"}},
+		{name: "first level team", teamID: "id-c", want: []string{"id-a", "id-b", "id-root"}},
+		{name: "top level team has nothing above", teamID: "id-root", want: []string{}},
+		{name: "unknown team", teamID: "id-x", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hierarchyWalk(tt.teamID, nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walk = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTeams(t *testing.T) {
+	added := make(map[string]bool)
+
+	steps := []struct {
+		teamIDs []string
+		want    []string
+	}{
+		{teamIDs: []string{"home"}, want: []string{"home"}},
+		{teamIDs: []string{"f1", "home", "f2", "f1"}, want: []string{"f1", "f2"}},
+		{teamIDs: []string{"f2"}, want: []string{}},
+		{teamIDs: []string{"parent"}, want: []string{"parent"}},
+	}
+
+	for i, step := range steps {
+		if got := newTeams(added, step.teamIDs); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: new teams = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestSelectionComplete(t *testing.T) {
+	member := candidate("m1", model.MEMBER, 0)
+	senior := candidate("s1", model.SENIOR, 0)
+	seniorRule := []model.RoleRule{{Role: model.SENIOR, MinCount: 1}}
+
+	tests := []struct {
+		name      string
+		selected  []reviewCandidate
+		rules     []model.RoleRule
+		reviewers []model.TeamMember
+		count     int
+		want      bool
+	}{
+		{name: "all slots filled", selected: []reviewCandidate{member, senior}, count: 2, want: true},
+		{name: "free slot left", selected: []reviewCandidate{member}, count: 2},
+		{name: "slots filled, rule not met", selected: []reviewCandidate{member}, rules: seniorRule, count: 1},
+		{name: "rule met by the pick", selected: []reviewCandidate{senior}, rules: seniorRule, count: 1, want: true},
+		{name: "rule met by a current reviewer", selected: []reviewCandidate{member}, rules: seniorRule,
+			reviewers: []model.TeamMember{{UserID: "x", Role: model.LEAD}}, count: 1, want: true},
+		{name: "nothing to pick", count: 0, want: true},
+	}
+
+	s := &PullRequestService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.selectionComplete(tt.selected, tt.rules, tt.reviewers, tt.count); got != tt.want {
+				t.Errorf("complete = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// selectReviewers stops loading tiers once the picks are complete, which is only sound when
+// picking over the loaded tiers gives the same result as over the whole pool
+func TestPickReviewersCompleteOverLoadedTiers(t *testing.T) {
+	pool := []reviewCandidate{
+		candidate("m1", model.MEMBER, 0),
+		candidate("s1", model.SENIOR, 0),
+		candidate("f1", model.MEMBER, 1),
+		candidate("fl", model.LEAD, 1),
+		candidate("h1", model.SENIOR, 2),
+		candidate("hl", model.LEAD, 3),
+	}
+
+	rules := [][]model.RoleRule{
+		nil,
+		{{Role: model.SENIOR, MinCount: 2}},
+		{{Role: model.LEAD, MinCount: 1}},
+		{{Role: model.LEAD, MinCount: 2}, {Role: model.SENIOR, MinCount: 3}},
+	}
+
+	s := &PullRequestService{}
+	for _, rule := range rules {
+		for count := 0; count <= 4; count++ {
+			want, wantExcluded := s.pickReviewers(pool, rule, []string{"f1"}, "author", nil, nil, count)
+			for loaded := 1; loaded <= len(pool); loaded++ {
+				if loaded < len(pool) && pool[loaded].tier == pool[loaded-1].tier {
+					continue
+				}
+				got, gotExcluded := s.pickReviewers(pool[:loaded], rule, []string{"f1"}, "author", nil, nil, count)
+				if !s.selectionComplete(got, rule, nil, count) {
+					continue
+				}
+				if !reflect.DeepEqual(candidateIDs(got), candidateIDs(want)) ||
+					!reflect.DeepEqual(gotExcluded, wantExcluded) {
+					t.Errorf("rules %v, count %d, %d loaded: picked %v %v, whole pool %v %v", rule, count, loaded,
+						candidateIDs(got), gotExcluded, candidateIDs(want), wantExcluded)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"pr-assignment/internal/model"
+	"time"
+)
+
+// AcceptReview is only possible for a fresh assignment, an accepted or reviewed one is a conflict
+func (s *PullRequestService) AcceptReview(ctx context.Context, prID string, reviewerID string) (*model.ReviewAssignment, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.AcceptReview", prIDKey.String(prID), reviewerIDKey.String(reviewerID))
+	defer span.End()
+
+	pullRequest, err := s.prRepository.GetPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pullRequest.Status == model.MERGED {
+		return nil, model.NewError(model.PrMerged, "PR already merged")
+	}
+
+	return s.prReviewersRepository.SetReviewState(ctx, prID, reviewerID, model.ACCEPTED,
+		[]model.ReviewState{model.ASSIGNED}, time.Now())
+}
+
+// DeclineReview replaces the reviewer with someone else. Only an assigned or accepted review can be
+// declined. The decline is recorded either way, without a substitute the reviewer stays on the PR
+// and a repeated decline is not counted again
+func (s *PullRequestService) DeclineReview(ctx context.Context, prID string, reviewerID string,
+	reason string) (*model.ReassignmentResult, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.DeclineReview", prIDKey.String(prID), reviewerIDKey.String(reviewerID))
+	defer span.End()
+
+	err := s.prReviewersRepository.CheckReviewState(ctx, prID, reviewerID,
+		[]model.ReviewState{model.ASSIGNED, model.ACCEPTED})
+	if err != nil {
+		return nil, err
+	}
+
+	decliners, err := s.declineRepository.GetDecliners(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.ChangeReviewer(ctx, prID, reviewerID, model.DeclineReassign)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := result.NewReviewerID != reviewerID
+	if replaced || !s.inReviewers(decliners, reviewerID) {
+		err = s.declineRepository.AddDecline(ctx, prID, reviewerID, reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !replaced {
+		return nil, model.NewError(model.NoCandidate, "decline of %s is recorded, but no one can replace them on %s",
+			reviewerID, prID)
+	}
+	return result, nil
+}
+
+// SubmitReview records the reviewer's decision, the first one also counts as reaction for SLA
+func (s *PullRequestService) SubmitReview(ctx context.Context, prID string, reviewerID string,
+	decision model.Decision) (*model.ReviewDecision, error) {
+	ctx, span := startSpan(ctx, "PullRequestService.SubmitReview", prIDKey.String(prID), reviewerIDKey.String(reviewerID),
+		decisionKey.String(string(decision)))
+	defer span.End()
+
+	if !decision.IsValid() {
+		return nil, model.NewError(model.BadRequest, "unknown decision %s", decision)
+	}
+
+	pullRequest, err := s.prRepository.GetPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pullRequest.Status == model.MERGED {
+		return nil, model.NewError(model.PrMerged, "PR already merged")
+	}
+
+	now := time.Now()
+	// a reviewer may change the decision, every one is kept in the history
+	_, err = s.prReviewersRepository.SetReviewState(ctx, prID, reviewerID, model.REVIEWED,
+		[]model.ReviewState{model.ASSIGNED, model.ACCEPTED, model.REVIEWED}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decisionRepository.AddDecision(ctx, model.ReviewDecision{PullRequestID: prID, ReviewerID: reviewerID,
+		Decision: decision, DecidedAt: now})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"pr-assignment/internal/model"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// maxRosterErrors caps the message of an invalid roster, the first ones are enough to fix the file
+const maxRosterErrors = 20
+
+type rosterFile struct {
+	Teams []rosterTeam `yaml:"teams"`
+}
+
+type rosterTeam struct {
+	TeamName string         `yaml:"team_name"`
+	Members  []rosterMember `yaml:"members"`
+}
+
+type rosterMember struct {
+	line     int
+	UserID   string         `yaml:"user_id"`
+	Username string         `yaml:"username"`
+	IsActive *bool          `yaml:"is_active"`
+	Role     model.UserRole `yaml:"role"`
+}
+
+// UnmarshalYAML keeps the line of the member for error messages and the diff
+func (m *rosterMember) UnmarshalYAML(node *yaml.Node) error {
+	type plain rosterMember
+	if err := node.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	m.line = node.Line
+	return nil
+}
+
+// ParseRosterYAML reads
+//
+//	teams:
+//	  - team_name: backend
+//	    members:
+//	      - {user_id: u1, username: Alice, is_active: true, role: senior}
+//
+// is_active defaults to true, an empty role keeps the current one
+func ParseRosterYAML(r io.Reader) ([]model.RosterEntry, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file rosterFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, model.NewError(model.BadRequest, "invalid roster: %s", err)
+	}
+
+	entries := make([]model.RosterEntry, 0)
+	for _, team := range file.Teams {
+		for _, member := range team.Members {
+			isActive := true
+			if member.IsActive != nil {
+				isActive = *member.IsActive
+			}
+			entries = append(entries, model.RosterEntry{Line: member.line, TeamName: team.TeamName,
+				UserID: member.UserID, Username: member.Username, IsActive: isActive, Role: member.Role})
+		}
+	}
+	return entries, nil
+}
+
+// ParseRosterCSV reads a header line with team_name, user_id and username and optional
+// is_active and role columns, one member per line
+func ParseRosterCSV(r io.Reader) ([]model.RosterEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []model.RosterEntry{}, nil
+	}
+	if err != nil {
+		return nil, model.NewError(model.BadRequest, "invalid roster: %s", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"team_name", "user_id", "username"} {
+		if _, ok := columns[required]; !ok {
+			return nil, model.NewError(model.BadRequest, "invalid roster: column %s is missing", required)
+		}
+	}
+
+	column := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	entries := make([]model.RosterEntry, 0)
+	var errs rosterErrors
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, model.NewError(model.BadRequest, "invalid roster: %s", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		isActive := true
+		if value := column(record, "is_active"); value != "" {
+			isActive, err = strconv.ParseBool(value)
+			if err != nil {
+				errs.add(line, "is_active must be true or false, got %s", value)
+			}
+		}
+
+		entries = append(entries, model.RosterEntry{Line: line, TeamName: column(record, "team_name"),
+			UserID: column(record, "user_id"), Username: column(record, "username"), IsActive: isActive,
+			Role: model.UserRole(column(record, "role"))})
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// rosterErrors collects problems of all lines so the whole file can be fixed at once
+type rosterErrors []string
+
+func (e *rosterErrors) add(line int, format string, args ...any) {
+	*e = append(*e, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+func (e rosterErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	messages := e
+	more := ""
+	if len(messages) > maxRosterErrors {
+		more = fmt.Sprintf("; and %d more", len(messages)-maxRosterErrors)
+		messages = messages[:maxRosterErrors]
+	}
+	return model.NewError(model.BadRequest, "invalid roster: %s%s", strings.Join(messages, "; "), more)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"pr-assignment/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRosterYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		roster  string
+		want    []model.RosterEntry
+		wantErr string
+	}{
+		{
+			name: "lines and defaults",
+			roster: `teams:
+  - team_name: backend
+    members:
+      - {user_id: u1, username: Alice, role: senior}
+      - user_id: u2
+        username: Bob
+        is_active: false
+  - team_name: frontend
+    members:
+      - {user_id: u3, username: Carol}
+`,
+			want: []model.RosterEntry{
+				{Line: 4, TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true, Role: model.SENIOR},
+				{Line: 5, TeamName: "backend", UserID: "u2", Username: "Bob", IsActive: false},
+				{Line: 10, TeamName: "frontend", UserID: "u3", Username: "Carol", IsActive: true},
+			},
+		},
+		{
+			name:   "empty file",
+			roster: "",
+			want:   []model.RosterEntry{},
+		},
+		{
+			name:    "unknown field",
+			roster:  "teams:\n  - team_name: backend\n    owner: u1\n",
+			wantErr: "invalid roster",
+		},
+		{
+			name:    "bad is_active",
+			roster:  "teams:\n  - team_name: backend\n    members:\n      - {user_id: u1, username: A, is_active: maybe}\n",
+			wantErr: "invalid roster",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRosterYAML(strings.NewReader(tt.roster))
+			checkRosterResult(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestParseRosterCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		roster  string
+		want    []model.RosterEntry
+		wantErr string
+	}{
+		{
+			name:   "required columns only",
+			roster: "team_name,user_id,username\nbackend,u1,Alice\nbackend,u2,Bob\n",
+			want: []model.RosterEntry{
+				{Line: 2, TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true},
+				{Line: 3, TeamName: "backend", UserID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			name:   "optional columns in any order",
+			roster: "Role, username, is_active, user_id, team_name\nlead, Alice, false, u1, backend\n,Bob,,u2,backend\n",
+			want: []model.RosterEntry{
+				{Line: 2, TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: false, Role: model.LEAD},
+				{Line: 3, TeamName: "backend", UserID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			name:   "header only",
+			roster: "team_name,user_id,username\n",
+			want:   []model.RosterEntry{},
+		},
+		{
+			name:    "missing column",
+			roster:  "team_name,user_id\nbackend,u1\n",
+			wantErr: "column username is missing",
+		},
+		{
+			name:    "bad is_active on every line",
+			roster:  "team_name,user_id,username,is_active\nbackend,u1,Alice,yes please\nbackend,u2,Bob,true\nbackend,u3,Carol,nope\n",
+			wantErr: "line 2: is_active must be true or false, got yes please; line 4: is_active must be true or false, got nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRosterCSV(strings.NewReader(tt.roster))
+			checkRosterResult(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestRosterErrorsAreCapped(t *testing.T) {
+	var errs rosterErrors
+	for line := 1; line <= maxRosterErrors+3; line++ {
+		errs.add(line, "broken")
+	}
+
+	err := errs.err()
+	if err == nil || !strings.HasSuffix(err.Error(), "; and 3 more") {
+		t.Fatalf("err = %v, want the last 3 errors folded", err)
+	}
+	if strings.Contains(err.Error(), "line 21:") {
+		t.Errorf("err = %v, lines past the cap are listed", err)
+	}
+}
+
+func checkRosterResult(t *testing.T, got []model.RosterEntry, err error, want []model.RosterEntry, wantErr string) {
+	t.Helper()
+
+	if wantErr != "" {
+		var customErr *model.CustomError
+		if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+			t.Fatalf("err = %v, want a bad request", err)
+		}
+		if !strings.Contains(customErr.Message, wantErr) {
+			t.Fatalf("err = %q, want it to contain %q", customErr.Message, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+)
+
+type RosterService struct {
+	rosterRepo *repository.RosterRepository
+	prService  *PullRequestService
+	logger     *slog.Logger
+}
+
+func NewRosterService(rosterRepo *repository.RosterRepository, prService *PullRequestService,
+	logger *slog.Logger) *RosterService {
+	return &RosterService{rosterRepo: rosterRepo, prService: prService, logger: logger}
+}
+
+// Import validates the whole roster, then creates missing teams and creates or updates users in
+// one transaction. With reconcile active users missing from the roster are deactivated, but only
+// those whose primary team is in scope, empty scope means the teams of the roster. With dryRun
+// only the diff is returned. Reviews of users that became inactive are reassigned like on /users/setIsActive
+func (s *RosterService) Import(ctx context.Context, entries []model.RosterEntry, reconcile bool, scope []string,
+	dryRun bool) (*model.RosterImportResult, error) {
+	ctx, span := startSpan(ctx, "RosterService.Import", rosterEntriesKey.Int(len(entries)),
+		reconcileKey.Bool(reconcile), dryRunKey.Bool(dryRun))
+	defer span.End()
+
+	if err := s.validate(entries); err != nil {
+		return nil, err
+	}
+
+	users, err := s.rosterRepo.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teamNames, err := s.rosterRepo.GetTeamNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]model.User, len(users))
+	for _, user := range users {
+		current[user.UserID] = user
+	}
+	existingTeams := make(map[string]bool, len(teamNames))
+	for _, name := range teamNames {
+		existingTeams[name] = true
+	}
+
+	result := &model.RosterImportResult{DryRun: dryRun, Reconcile: reconcile, CreatedTeams: make([]string, 0),
+		Rows: make([]model.RosterRow, 0, len(entries)), Summary: make(map[model.RosterChange]int)}
+	upserts := make([]model.User, 0)
+	deactivated := make([]string, 0)
+
+	for _, entry := range entries {
+		if !existingTeams[entry.TeamName] {
+			existingTeams[entry.TeamName] = true
+			result.CreatedTeams = append(result.CreatedTeams, entry.TeamName)
+		}
+
+		row := model.RosterRow{Line: entry.Line, TeamName: entry.TeamName, UserID: entry.UserID,
+			Username: entry.Username}
+		old, exists := current[entry.UserID]
+		user := rosterUser(entry, old, exists)
+		if !exists {
+			row.Change = model.RosterCreated
+		} else {
+			row.Fields = changedFields(old, user)
+			row.Change = model.RosterUnchanged
+			if len(row.Fields) > 0 {
+				row.Change = model.RosterUpdated
+			}
+			if old.IsActive && !user.IsActive {
+				deactivated = append(deactivated, user.UserID)
+			}
+		}
+
+		if row.Change != model.RosterUnchanged {
+			upserts = append(upserts, user)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	deactivateIDs := make([]string, 0)
+	if reconcile {
+		if len(scope) == 0 {
+			scope = rosterTeams(entries)
+		}
+		result.ReconciledTeams = scope
+
+		for _, user := range reconciledUsers(users, entries, scope) {
+			deactivateIDs = append(deactivateIDs, user.UserID)
+			result.Rows = append(result.Rows, model.RosterRow{TeamName: user.TeamName, UserID: user.UserID,
+				Username: user.Username, Change: model.RosterDeactivated, Fields: []string{"is_active"}})
+		}
+	}
+
+	for _, row := range result.Rows {
+		result.Summary[row.Change]++
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	err = s.rosterRepo.Apply(ctx, result.CreatedTeams, upserts, deactivateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "roster imported", "created_teams", len(result.CreatedTeams),
+		"created", result.Summary[model.RosterCreated], "updated", result.Summary[model.RosterUpdated],
+		"deactivated", result.Summary[model.RosterDeactivated])
+
+	for _, userID := range append(deactivated, deactivateIDs...) {
+		err = s.prService.ReassignReviewsAfterDeath(ctx, userID)
+		if err != nil {
+			// users are deactivated anyway, the failure only goes to the log
+			s.logger.WarnContext(ctx, "unable to reassign reviews of deactivated user", "user_id", userID,
+				"error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// rosterUser is the user an entry describes, an empty role keeps the current one and new users
+// are members
+func rosterUser(entry model.RosterEntry, old model.User, exists bool) model.User {
+	user := model.User{UserID: entry.UserID, Username: entry.Username, TeamName: entry.TeamName,
+		IsActive: entry.IsActive, Role: entry.Role}
+	if user.Role == "" {
+		user.Role = model.MEMBER
+		if exists {
+			user.Role = old.Role
+		}
+	}
+	return user
+}
+
+// reconciledUsers picks active users of the scope teams that the roster does not list
+func reconciledUsers(users []model.User, entries []model.RosterEntry, scope []string) []model.User {
+	inRoster := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		inRoster[entry.UserID] = true
+	}
+	inScope := make(map[string]bool, len(scope))
+	for _, teamName := range scope {
+		inScope[teamName] = true
+	}
+
+	missing := make([]model.User, 0)
+	for _, user := range users {
+		if user.IsActive && !inRoster[user.UserID] && inScope[user.TeamName] {
+			missing = append(missing, user)
+		}
+	}
+	return missing
+}
+
+// rosterTeams lists teams of the roster in the order they first appear
+func rosterTeams(entries []model.RosterEntry) []string {
+	seen := make(map[string]bool)
+	teams := make([]string, 0)
+	for _, entry := range entries {
+		if !seen[entry.TeamName] {
+			seen[entry.TeamName] = true
+			teams = append(teams, entry.TeamName)
+		}
+	}
+	return teams
+}
+
+func (s *RosterService) validate(entries []model.RosterEntry) error {
+	if len(entries) == 0 {
+		return model.NewError(model.BadRequest, "roster is empty")
+	}
+
+	var errs rosterErrors
+	firstLine := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		if entry.TeamName == "" {
+			errs.add(entry.Line, "team_name is required")
+		}
+		if entry.UserID == "" {
+			errs.add(entry.Line, "user_id is required")
+		}
+		if entry.Username == "" {
+			errs.add(entry.Line, "username is required")
+		}
+		if entry.Role != "" && !entry.Role.IsValid() {
+			errs.add(entry.Line, "unknown role %s", entry.Role)
+		}
+		if entry.UserID == "" {
+			continue
+		}
+		if line, ok := firstLine[entry.UserID]; ok {
+			errs.add(entry.Line, "user %s is already listed on line %d", entry.UserID, line)
+			continue
+		}
+		firstLine[entry.UserID] = entry.Line
+	}
+
+	return errs.err()
+}
+
+func changedFields(old model.User, user model.User) []string {
+	fields := make([]string, 0)
+	if old.Username != user.Username {
+		fields = append(fields, "username")
+	}
+	if old.TeamName != user.TeamName {
+		fields = append(fields, "team_name")
+	}
+	if old.IsActive != user.IsActive {
+		fields = append(fields, "is_active")
+	}
+	if old.Role != user.Role {
+		fields = append(fields, "role")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
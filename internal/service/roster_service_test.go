@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestRosterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []model.RosterEntry
+		wantErr string
+	}{
+		{
+			name: "valid",
+			entries: []model.RosterEntry{
+				{Line: 2, TeamName: "backend", UserID: "u1", Username: "Alice", Role: model.LEAD},
+				{Line: 3, TeamName: "frontend", UserID: "u2", Username: "Bob"},
+			},
+		},
+		{
+			name:    "empty",
+			entries: []model.RosterEntry{},
+			wantErr: "roster is empty",
+		},
+		{
+			name: "duplicate user points at the first line",
+			entries: []model.RosterEntry{
+				{Line: 2, TeamName: "backend", UserID: "u1", Username: "Alice"},
+				{Line: 3, TeamName: "frontend", UserID: "u2", Username: "Bob"},
+				{Line: 7, TeamName: "frontend", UserID: "u1", Username: "Alice"},
+			},
+			wantErr: "invalid roster: line 7: user u1 is already listed on line 2",
+		},
+		{
+			name: "all problems at once",
+			entries: []model.RosterEntry{
+				{Line: 2, UserID: "u1", Username: "Alice"},
+				{Line: 3, TeamName: "backend", Username: "Bob"},
+				{Line: 4, TeamName: "backend", UserID: "u3", Role: "boss"},
+			},
+			wantErr: "invalid roster: line 2: team_name is required; line 3: user_id is required; " +
+				"line 4: username is required; line 4: unknown role boss",
+		},
+	}
+
+	s := &RosterService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.validate(tt.entries)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			if customErr.Message != tt.wantErr {
+				t.Errorf("err = %q, want %q", customErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRosterUserRoleDefaults(t *testing.T) {
+	old := model.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true, Role: model.SENIOR}
+
+	tests := []struct {
+		name     string
+		role     model.UserRole
+		exists   bool
+		wantRole model.UserRole
+	}{
+		{name: "new user without role is a member", exists: false, wantRole: model.MEMBER},
+		{name: "new user keeps the given role", role: model.LEAD, exists: false, wantRole: model.LEAD},
+		{name: "existing user without role keeps the current one", exists: true, wantRole: model.SENIOR},
+		{name: "existing user gets the given role", role: model.MEMBER, exists: true, wantRole: model.MEMBER},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := model.RosterEntry{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true,
+				Role: tt.role}
+			user := rosterUser(entry, old, tt.exists)
+			if user.Role != tt.wantRole {
+				t.Errorf("role = %s, want %s", user.Role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	old := model.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true, Role: model.MEMBER}
+
+	tests := []struct {
+		name   string
+		change func(user *model.User)
+		want   []string
+	}{
+		{name: "unchanged", change: func(user *model.User) {}, want: nil},
+		{name: "moved and deactivated", change: func(user *model.User) {
+			user.TeamName = "frontend"
+			user.IsActive = false
+		}, want: []string{"team_name", "is_active"}},
+		{name: "everything", change: func(user *model.User) {
+			*user = model.User{UserID: "u1", Username: "Alice B", TeamName: "frontend", Role: model.LEAD}
+		}, want: []string{"username", "team_name", "is_active", "role"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := old
+			tt.change(&user)
+			if got := changedFields(old, user); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciledUsersStayInScope(t *testing.T) {
+	users := []model.User{
+		{UserID: "u1", TeamName: "backend", IsActive: true},
+		{UserID: "u2", TeamName: "backend", IsActive: true},
+		{UserID: "u3", TeamName: "backend", IsActive: false},
+		{UserID: "u4", TeamName: "frontend", IsActive: true},
+		{UserID: "u5", TeamName: "platform", IsActive: true},
+	}
+	entries := []model.RosterEntry{
+		{TeamName: "backend", UserID: "u1"},
+		// moved out of platform by the roster
+		{TeamName: "backend", UserID: "u5"},
+	}
+
+	tests := []struct {
+		name  string
+		scope []string
+		want  []string
+	}{
+		{name: "roster teams", scope: rosterTeams(entries), want: []string{"u2"}},
+		{name: "explicit scope", scope: []string{"backend", "frontend"}, want: []string{"u2", "u4"}},
+		{name: "nothing in scope", scope: []string{"design"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, user := range reconciledUsers(users, entries, tt.scope) {
+				got = append(got, user.UserID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deactivated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
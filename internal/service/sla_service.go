@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+	"time"
+)
+
+type SlaService struct {
+	slaRepository  *repository.SlaRepository
+	teamRepository *repository.TeamRepository
+	prService      *PullRequestService
+	logger         *slog.Logger
+}
+
+func NewSlaService(slaRepo *repository.SlaRepository, teamRepo *repository.TeamRepository,
+	prService *PullRequestService, logger *slog.Logger) *SlaService {
+	return &SlaService{slaRepository: slaRepo, teamRepository: teamRepo, prService: prService, logger: logger}
+}
+
+func (s *SlaService) SetTeamSla(ctx context.Context, teamName string, sla model.TeamSla) (*model.TeamSla, error) {
+	if sla.FirstReviewHours <= 0 {
+		return nil, model.NewError(model.BadRequest, "first review hours must be positive")
+	}
+
+	if !sla.Policy.IsValid() {
+		return nil, model.NewError(model.BadRequest, "unknown SLA policy %s", sla.Policy)
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.slaRepository.SetTeamSla(ctx, teamID, sla)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sla, nil
+}
+
+// CheckSla escalates every assignment that breached the SLA of its team. One failed escalation
+// does not stop the others
+func (s *SlaService) CheckSla(ctx context.Context) error {
+	pendingReviews, err := s.slaRepository.GetPendingReviews(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var lastErr error
+	for _, review := range pendingReviews {
+		limit := time.Duration(review.Sla.FirstReviewHours) * time.Hour
+		if businessDuration(review.AssignedAt, now) <= limit {
+			continue
+		}
+
+		err = s.escalate(ctx, review)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "unable to escalate SLA breach", "pr_id", review.PullRequestID,
+				"reviewer_id", review.ReviewerID, "error", err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (s *SlaService) escalate(ctx context.Context, review model.PendingReview) error {
+	escalation := model.SlaEscalation{
+		PullRequestID: review.PullRequestID,
+		ReviewerID:    review.ReviewerID,
+		TeamID:        review.TeamID,
+		Action:        review.Sla.Policy,
+		EscalatedAt:   time.Now(),
+	}
+
+	switch review.Sla.Policy {
+	case model.AddLead:
+		leadID, err := s.prService.AddLeadReviewer(ctx, review.PullRequestID)
+		if err != nil {
+			return err
+		}
+		escalation.NewReviewerID = leadID
+	case model.Reassign:
+		result, err := s.prService.ChangeReviewer(ctx, review.PullRequestID, review.ReviewerID,
+			model.SlaReassign)
+		if err != nil {
+			return err
+		}
+		if result.NewReviewerID != review.ReviewerID {
+			escalation.NewReviewerID = result.NewReviewerID
+		}
+	}
+
+	return s.slaRepository.AddEscalation(ctx, escalation)
+}
+
+func (s *SlaService) GetSlaReport(ctx context.Context) (*model.SlaReport, error) {
+	teams, err := s.slaRepository.GetBreachesByTeam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers, err := s.slaRepository.GetBreachesByReviewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SlaReport{Teams: teams, Reviewers: reviewers}, nil
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxSnapshotErrors caps the message of an invalid snapshot
+const maxSnapshotErrors = 20
+
+type SnapshotService struct {
+	snapshotRepo *repository.SnapshotRepository
+	logger       *slog.Logger
+}
+
+func NewSnapshotService(snapshotRepo *repository.SnapshotRepository, logger *slog.Logger) *SnapshotService {
+	return &SnapshotService{snapshotRepo: snapshotRepo, logger: logger}
+}
+
+// Export returns a consistent copy of the whole dataset
+func (s *SnapshotService) Export(ctx context.Context) (*model.Snapshot, error) {
+	ctx, span := startSpan(ctx, "SnapshotService.Export")
+	defer span.End()
+
+	snapshot, err := s.snapshotRepo.Export(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Version = model.SnapshotVersion
+	snapshot.CreatedAt = time.Now().UTC()
+
+	s.logger.InfoContext(ctx, "snapshot exported", "teams", len(snapshot.Teams), "users", len(snapshot.Users),
+		"pull_requests", len(snapshot.PullRequests))
+	return snapshot, nil
+}
+
+// Import validates the snapshot and writes it in one transaction. Replace wipes the current data
+// first. Merge keeps rows missing from the snapshot, and teams of the snapshot that have the
+// name of an existing team are merged into it. Both modes are idempotent
+func (s *SnapshotService) Import(ctx context.Context, snapshot *model.Snapshot, mode model.SnapshotMode,
+	dryRun bool) (*model.SnapshotImportResult, error) {
+	ctx, span := startSpan(ctx, "SnapshotService.Import", snapshotModeKey.String(string(mode)),
+		snapshotDryRunKey.Bool(dryRun))
+	defer span.End()
+
+	if !mode.IsValid() {
+		return nil, model.NewError(model.BadRequest, "mode must be replace or merge")
+	}
+	if snapshot.Version != model.SnapshotVersion {
+		return nil, model.NewError(model.BadRequest, "unsupported snapshot version %d, expected %d",
+			snapshot.Version, model.SnapshotVersion)
+	}
+	fillPRTeams(snapshot)
+	if err := validateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	result := &model.SnapshotImportResult{Mode: mode, DryRun: dryRun, MatchedTeams: make([]string, 0)}
+	if mode == model.SnapshotMerge {
+		existing, err := s.snapshotRepo.GetTeamIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.MatchedTeams = remapTeams(snapshot, existing)
+	}
+
+	written, err := s.snapshotRepo.Import(ctx, snapshot, mode == model.SnapshotReplace, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	result.Written = written
+
+	s.logger.InfoContext(ctx, "snapshot imported", "mode", mode, "dry_run", dryRun, "written", written)
+	return result, nil
+}
+
+// remapTeams points snapshot teams at existing teams with the same name, team names are unique
+// in the service while ids differ between environments. Returns the names of matched teams
+func remapTeams(snapshot *model.Snapshot, existing map[string]string) []string {
+	ids := make(map[string]string)
+	matched := make([]string, 0)
+	for i, team := range snapshot.Teams {
+		id, ok := existing[team.TeamName]
+		if !ok || id == team.TeamID {
+			continue
+		}
+		ids[team.TeamID] = id
+		snapshot.Teams[i].TeamID = id
+		matched = append(matched, team.TeamName)
+	}
+	if len(ids) == 0 {
+		return matched
+	}
+
+	remap := func(id string) string {
+		if mapped, ok := ids[id]; ok {
+			return mapped
+		}
+		return id
+	}
+	for i := range snapshot.Teams {
+		snapshot.Teams[i].ParentTeamID = remap(snapshot.Teams[i].ParentTeamID)
+	}
+	for i := range snapshot.TeamFallbacks {
+		snapshot.TeamFallbacks[i].TeamID = remap(snapshot.TeamFallbacks[i].TeamID)
+		snapshot.TeamFallbacks[i].FallbackTeamID = remap(snapshot.TeamFallbacks[i].FallbackTeamID)
+	}
+	for i := range snapshot.TeamRoleRules {
+		snapshot.TeamRoleRules[i].TeamID = remap(snapshot.TeamRoleRules[i].TeamID)
+	}
+	for i := range snapshot.TeamSla {
+		snapshot.TeamSla[i].TeamID = remap(snapshot.TeamSla[i].TeamID)
+	}
+	for i := range snapshot.Users {
+		snapshot.Users[i].TeamID = remap(snapshot.Users[i].TeamID)
+	}
+	for i := range snapshot.TeamMemberships {
+		snapshot.TeamMemberships[i].TeamID = remap(snapshot.TeamMemberships[i].TeamID)
+	}
+	for i := range snapshot.PullRequests {
+		snapshot.PullRequests[i].TeamID = remap(snapshot.PullRequests[i].TeamID)
+	}
+	for i := range snapshot.Escalations {
+		snapshot.Escalations[i].TeamID = remap(snapshot.Escalations[i].TeamID)
+	}
+	return matched
+}
+
+// fillPRTeams gives PRs of archives made before PRs had a team the primary team of their author
+func fillPRTeams(snapshot *model.Snapshot) {
+	teams := make(map[string]string, len(snapshot.Users))
+	for _, user := range snapshot.Users {
+		teams[user.UserID] = user.TeamID
+	}
+	for i, pr := range snapshot.PullRequests {
+		if pr.TeamID == "" {
+			snapshot.PullRequests[i].TeamID = teams[pr.AuthorID]
+		}
+	}
+}
+
+// validateSnapshot checks values and that every reference points inside the snapshot,
+// an export is always self-contained
+func validateSnapshot(snapshot *model.Snapshot) error {
+	var errs snapshotErrors
+
+	teams := make(map[string]bool, len(snapshot.Teams))
+	teamNames := make(map[string]bool, len(snapshot.Teams))
+	for i, team := range snapshot.Teams {
+		if _, err := uuid.Parse(team.TeamID); err != nil {
+			errs.add("teams", i, "team_id %q is not a uuid", team.TeamID)
+		}
+		if team.TeamName == "" {
+			errs.add("teams", i, "team_name is required")
+		}
+		if teams[team.TeamID] {
+			errs.add("teams", i, "duplicate team_id %s", team.TeamID)
+		}
+		if teamNames[team.TeamName] {
+			errs.add("teams", i, "duplicate team_name %s", team.TeamName)
+		}
+		teams[team.TeamID] = true
+		teamNames[team.TeamName] = true
+	}
+
+	parents := make(map[string]string, len(snapshot.Teams))
+	for i, team := range snapshot.Teams {
+		if team.ParentTeamID == "" {
+			continue
+		}
+		if !teams[team.ParentTeamID] || team.ParentTeamID == team.TeamID {
+			errs.add("teams", i, "parent_team_id %s is not another team of the snapshot", team.ParentTeamID)
+			continue
+		}
+		parents[team.TeamID] = team.ParentTeamID
+	}
+	for i, team := range snapshot.Teams {
+		if hasParentCycle(parents, team.TeamID) {
+			errs.add("teams", i, "team %s is its own ancestor", team.TeamName)
+		}
+	}
+
+	for i, fallback := range snapshot.TeamFallbacks {
+		if !teams[fallback.TeamID] || !teams[fallback.FallbackTeamID] {
+			errs.add("team_fallbacks", i, "unknown team %s or %s", fallback.TeamID, fallback.FallbackTeamID)
+		}
+	}
+	for i, rule := range snapshot.TeamRoleRules {
+		if !teams[rule.TeamID] {
+			errs.add("team_role_rules", i, "unknown team %s", rule.TeamID)
+		}
+		if !rule.Role.IsValid() || rule.MinCount <= 0 {
+			errs.add("team_role_rules", i, "invalid rule %s x%d", rule.Role, rule.MinCount)
+		}
+	}
+	for i, sla := range snapshot.TeamSla {
+		if !teams[sla.TeamID] {
+			errs.add("team_sla", i, "unknown team %s", sla.TeamID)
+		}
+		if !sla.Policy.IsValid() || sla.FirstReviewHours <= 0 {
+			errs.add("team_sla", i, "invalid sla %dh %s", sla.FirstReviewHours, sla.Policy)
+		}
+	}
+
+	users := make(map[string]bool, len(snapshot.Users))
+	primaryTeams := make(map[string]string, len(snapshot.Users))
+	for i, user := range snapshot.Users {
+		if user.UserID == "" || user.Username == "" {
+			errs.add("users", i, "user_id and username are required")
+		}
+		if users[user.UserID] {
+			errs.add("users", i, "duplicate user_id %s", user.UserID)
+		}
+		users[user.UserID] = true
+		primaryTeams[user.UserID] = user.TeamID
+		if !teams[user.TeamID] {
+			errs.add("users", i, "unknown team %s", user.TeamID)
+		}
+		if !user.Role.IsValid() {
+			errs.add("users", i, "unknown role %s", user.Role)
+		}
+		if !user.DigestFrequency.IsValid() {
+			errs.add("users", i, "unknown digest_frequency %s", user.DigestFrequency)
+		}
+	}
+
+	for i, membership := range snapshot.TeamMemberships {
+		if !users[membership.UserID] || !teams[membership.TeamID] {
+			errs.add("team_memberships", i, "unknown user %s or team %s", membership.UserID, membership.TeamID)
+		}
+		if primaryTeams[membership.UserID] == membership.TeamID {
+			errs.add("team_memberships", i, "team %s is the primary team of %s", membership.TeamID,
+				membership.UserID)
+		}
+	}
+
+	for i, exclusion := range snapshot.ReviewerExclusions {
+		if !users[exclusion.AuthorID] || !users[exclusion.ReviewerID] {
+			errs.add("reviewer_exclusions", i, "unknown user %s or %s", exclusion.AuthorID, exclusion.ReviewerID)
+		}
+	}
+
+	prs := make(map[string]bool, len(snapshot.PullRequests))
+	for i, pr := range snapshot.PullRequests {
+		if pr.PullRequestID == "" {
+			errs.add("pull_requests", i, "pull_request_id is required")
+		}
+		if prs[pr.PullRequestID] {
+			errs.add("pull_requests", i, "duplicate pull_request_id %s", pr.PullRequestID)
+		}
+		prs[pr.PullRequestID] = true
+		if !users[pr.AuthorID] {
+			errs.add("pull_requests", i, "unknown author %s", pr.AuthorID)
+		}
+		if !teams[pr.TeamID] {
+			errs.add("pull_requests", i, "unknown team %s", pr.TeamID)
+		}
+		if pr.Status != model.CREATED && pr.Status != model.MERGED {
+			errs.add("pull_requests", i, "unknown status %s", pr.Status)
+		}
+	}
+
+	reviewRef := func(section string, i int, prID string, reviewerID string) {
+		if !prs[prID] || !users[reviewerID] {
+			errs.add(section, i, "unknown pull request %s or reviewer %s", prID, reviewerID)
+		}
+	}
+	for i, reviewer := range snapshot.Reviewers {
+		reviewRef("reviewers", i, reviewer.PullRequestID, reviewer.ReviewerID)
+		switch reviewer.State {
+		case model.ASSIGNED, model.ACCEPTED, model.REVIEWED:
+		default:
+			errs.add("reviewers", i, "unknown state %s", reviewer.State)
+		}
+	}
+	for i, assignment := range snapshot.Assignments {
+		reviewRef("assignments", i, assignment.PullRequestID, assignment.ReviewerID)
+		if !users[assignment.AuthorID] {
+			errs.add("assignments", i, "unknown author %s", assignment.AuthorID)
+		}
+	}
+	for i, decline := range snapshot.Declines {
+		reviewRef("declines", i, decline.PullRequestID, decline.ReviewerID)
+	}
+	for i, decision := range snapshot.Decisions {
+		reviewRef("decisions", i, decision.PullRequestID, decision.ReviewerID)
+		if !decision.Decision.IsValid() {
+			errs.add("decisions", i, "unknown decision %s", decision.Decision)
+		}
+	}
+	for i, escalation := range snapshot.Escalations {
+		reviewRef("escalations", i, escalation.PullRequestID, escalation.ReviewerID)
+		if !teams[escalation.TeamID] {
+			errs.add("escalations", i, "unknown team %s", escalation.TeamID)
+		}
+		if escalation.NewReviewerID != nil && !users[*escalation.NewReviewerID] {
+			errs.add("escalations", i, "unknown new reviewer %s", *escalation.NewReviewerID)
+		}
+	}
+
+	for i, move := range snapshot.TeamMoves {
+		if !users[move.UserID] {
+			errs.add("team_moves", i, "unknown user %s", move.UserID)
+		}
+		if !move.ReviewPolicy.IsValid() {
+			errs.add("team_moves", i, "unknown review_policy %s", move.ReviewPolicy)
+		}
+	}
+
+	return errs.err()
+}
+
+// hasParentCycle tells whether walking up from the team comes back to it
+func hasParentCycle(parents map[string]string, teamID string) bool {
+	seen := make(map[string]bool)
+	for id := parents[teamID]; id != ""; id = parents[id] {
+		if id == teamID {
+			return true
+		}
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return false
+}
+
+// snapshotErrors collects all problems like rosterErrors, positions are section[index]
+type snapshotErrors []string
+
+func (e *snapshotErrors) add(section string, i int, format string, args ...any) {
+	*e = append(*e, fmt.Sprintf("%s[%d]: ", section, i)+fmt.Sprintf(format, args...))
+}
+
+func (e snapshotErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	messages := e
+	more := ""
+	if len(messages) > maxSnapshotErrors {
+		more = fmt.Sprintf("; and %d more", len(messages)-maxSnapshotErrors)
+		messages = messages[:maxSnapshotErrors]
+	}
+	return model.NewError(model.BadRequest, "invalid snapshot: %s%s", strings.Join(messages, "; "), more)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"pr-assignment/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	backendID  = "00000000-0000-0000-0000-00000000000b"
+	frontendID = "00000000-0000-0000-0000-00000000000f"
+	platformID = "00000000-0000-0000-0000-00000000000a"
+)
+
+func testSnapshot() *model.Snapshot {
+	at := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	newReviewer := "u3"
+	return &model.Snapshot{
+		Version: model.SnapshotVersion,
+		Teams: []model.SnapshotTeam{
+			{TeamID: platformID, TeamName: "platform"},
+			{TeamID: backendID, TeamName: "backend", ParentTeamID: platformID},
+			{TeamID: frontendID, TeamName: "frontend", ParentTeamID: platformID},
+		},
+		TeamFallbacks: []model.SnapshotFallback{{TeamID: backendID, FallbackTeamID: frontendID, Position: 1}},
+		TeamRoleRules: []model.SnapshotRoleRule{{TeamID: backendID, Role: model.SENIOR, MinCount: 1}},
+		TeamSla:       []model.SnapshotTeamSla{{TeamID: backendID, FirstReviewHours: 24, Policy: model.AddLead}},
+		Users: []model.SnapshotUser{
+			{UserID: "u1", Username: "Alice", TeamID: backendID, IsActive: true, Role: model.MEMBER,
+				DigestFrequency: model.NONE},
+			{UserID: "u2", Username: "Bob", TeamID: backendID, IsActive: true, Role: model.SENIOR,
+				DigestFrequency: model.DAILY},
+			{UserID: "u3", Username: "Carol", TeamID: frontendID, IsActive: true, Role: model.LEAD,
+				DigestFrequency: model.NONE},
+		},
+		TeamMemberships:    []model.SnapshotMembership{{UserID: "u3", TeamID: backendID, AddedAt: at}},
+		ReviewerExclusions: []model.SnapshotExclusion{{AuthorID: "u1", ReviewerID: "u3", CreatedAt: at}},
+		PullRequests: []model.SnapshotPullRequest{
+			{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", TeamID: backendID,
+				Status: model.CREATED, CreatedAt: at},
+		},
+		Reviewers: []model.SnapshotReviewer{
+			{PullRequestID: "pr-1", ReviewerID: "u2", State: model.ACCEPTED, AssignedAt: at},
+		},
+		Assignments: []model.SnapshotAssignment{{PullRequestID: "pr-1", AuthorID: "u1", ReviewerID: "u2",
+			AssignedAt: at}},
+		Declines:  []model.SnapshotDecline{{PullRequestID: "pr-1", ReviewerID: "u3", DeclinedAt: at}},
+		Decisions: []model.SnapshotDecision{{PullRequestID: "pr-1", ReviewerID: "u2", Decision: model.APPROVED}},
+		Escalations: []model.SnapshotEscalation{{PullRequestID: "pr-1", ReviewerID: "u2", TeamID: backendID,
+			Action: model.AddLead, NewReviewerID: &newReviewer}},
+		TeamMoves: []model.SnapshotTeamMove{{UserID: "u3", ReviewPolicy: model.KeepReviews}},
+	}
+}
+
+func TestValidateSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(snapshot *model.Snapshot)
+		wantErr []string
+	}{
+		{name: "valid", change: func(snapshot *model.Snapshot) {}},
+		{name: "team values", change: func(snapshot *model.Snapshot) {
+			snapshot.Teams = append(snapshot.Teams,
+				model.SnapshotTeam{TeamID: "backend", TeamName: ""},
+				model.SnapshotTeam{TeamID: backendID, TeamName: "frontend"})
+		}, wantErr: []string{`teams[3]: team_id "backend" is not a uuid`, "teams[3]: team_name is required",
+			"teams[4]: duplicate team_id " + backendID, "teams[4]: duplicate team_name frontend"}},
+		{name: "parent outside the snapshot", change: func(snapshot *model.Snapshot) {
+			snapshot.Teams[0].ParentTeamID = "00000000-0000-0000-0000-0000000000ff"
+		}, wantErr: []string{"teams[0]: parent_team_id 00000000-0000-0000-0000-0000000000ff is not another team"}},
+		{name: "team is its own parent", change: func(snapshot *model.Snapshot) {
+			snapshot.Teams[0].ParentTeamID = platformID
+		}, wantErr: []string{"teams[0]: parent_team_id " + platformID + " is not another team"}},
+		{name: "parent cycle", change: func(snapshot *model.Snapshot) {
+			snapshot.Teams[0].ParentTeamID = backendID
+		}, wantErr: []string{"teams[0]: team platform is its own ancestor",
+			"teams[1]: team backend is its own ancestor"}},
+		{name: "dangling team references", change: func(snapshot *model.Snapshot) {
+			snapshot.TeamFallbacks[0].FallbackTeamID = "gone"
+			snapshot.Users[0].TeamID = "gone"
+			snapshot.PullRequests[0].TeamID = "gone"
+		}, wantErr: []string{"team_fallbacks[0]: unknown team", "users[0]: unknown team gone",
+			"pull_requests[0]: unknown team gone"}},
+		{name: "secondary membership in the primary team", change: func(snapshot *model.Snapshot) {
+			snapshot.TeamMemberships[0].TeamID = frontendID
+		}, wantErr: []string{"team_memberships[0]: team " + frontendID + " is the primary team of u3"}},
+		{name: "dangling user and pr references", change: func(snapshot *model.Snapshot) {
+			snapshot.Reviewers[0].ReviewerID = "u9"
+			snapshot.Declines[0].PullRequestID = "pr-9"
+			snapshot.TeamMoves[0].UserID = "u9"
+		}, wantErr: []string{"reviewers[0]: unknown pull request pr-1 or reviewer u9",
+			"declines[0]: unknown pull request pr-9 or reviewer u3", "team_moves[0]: unknown user u9"}},
+		{name: "enum values", change: func(snapshot *model.Snapshot) {
+			snapshot.Users[1].Role = "boss"
+			snapshot.PullRequests[0].Status = "CLOSED"
+			snapshot.Reviewers[0].State = "ignored"
+			snapshot.Decisions[0].Decision = "maybe"
+		}, wantErr: []string{"users[1]: unknown role boss", "pull_requests[0]: unknown status CLOSED",
+			"reviewers[0]: unknown state ignored", "decisions[0]: unknown decision maybe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := testSnapshot()
+			tt.change(snapshot)
+
+			err := validateSnapshot(snapshot)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(customErr.Message, want) {
+					t.Errorf("err = %q, want it to contain %q", customErr.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRemapTeams(t *testing.T) {
+	const (
+		liveBackendID  = "11111111-1111-1111-1111-11111111111b"
+		livePlatformID = "11111111-1111-1111-1111-11111111111a"
+	)
+
+	snapshot := testSnapshot()
+	// frontend exists under the same id, backend and platform under other ids, design is new
+	matched := remapTeams(snapshot, map[string]string{"backend": liveBackendID, "frontend": frontendID,
+		"platform": livePlatformID, "design": "11111111-1111-1111-1111-11111111111d"})
+
+	if want := []string{"platform", "backend"}; !reflect.DeepEqual(matched, want) {
+		t.Errorf("matched = %v, want %v", matched, want)
+	}
+
+	got := []string{
+		snapshot.Teams[0].TeamID, snapshot.Teams[1].TeamID, snapshot.Teams[1].ParentTeamID,
+		snapshot.Teams[2].TeamID, snapshot.Teams[2].ParentTeamID,
+		snapshot.TeamFallbacks[0].TeamID, snapshot.TeamFallbacks[0].FallbackTeamID,
+		snapshot.TeamRoleRules[0].TeamID, snapshot.TeamSla[0].TeamID,
+		snapshot.Users[0].TeamID, snapshot.Users[2].TeamID, snapshot.TeamMemberships[0].TeamID,
+		snapshot.PullRequests[0].TeamID, snapshot.Escalations[0].TeamID,
+	}
+	want := []string{
+		livePlatformID, liveBackendID, livePlatformID,
+		frontendID, livePlatformID,
+		liveBackendID, frontendID,
+		liveBackendID, liveBackendID,
+		liveBackendID, frontendID, liveBackendID,
+		liveBackendID, liveBackendID,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("team ids = %v, want %v", got, want)
+	}
+
+	// the remapped archive still points inside itself
+	if err := validateSnapshot(snapshot); err != nil {
+		t.Errorf("remapped snapshot is invalid: %v", err)
+	}
+}
+
+func TestRemapTeamsWithoutMatches(t *testing.T) {
+	snapshot := testSnapshot()
+	matched := remapTeams(snapshot, map[string]string{"frontend": frontendID})
+
+	if len(matched) != 0 {
+		t.Errorf("matched = %v, want none", matched)
+	}
+	if !reflect.DeepEqual(snapshot, testSnapshot()) {
+		t.Errorf("snapshot changed without matches")
+	}
+}
+
+func TestHasParentCycle(t *testing.T) {
+	parents := map[string]string{
+		"squad":   "tribe",
+		"tribe":   "dept",
+		"a":       "b",
+		"b":       "c",
+		"c":       "a",
+		"tail":    "a",
+		"self":    "self",
+		"orphan":  "missing",
+		"lead-in": "self",
+	}
+
+	tests := []struct {
+		teamID string
+		want   bool
+	}{
+		{teamID: "squad", want: false},
+		{teamID: "dept", want: false},
+		{teamID: "a", want: true},
+		{teamID: "c", want: true},
+		// leads into a cycle but is not part of it
+		{teamID: "tail", want: false},
+		{teamID: "lead-in", want: false},
+		{teamID: "self", want: true},
+		{teamID: "orphan", want: false},
+		{teamID: "unknown", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := hasParentCycle(parents, tt.teamID); got != tt.want {
+			t.Errorf("hasParentCycle(%s) = %v, want %v", tt.teamID, got, tt.want)
+		}
+	}
+}
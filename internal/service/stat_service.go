@@ -4,55 +4,143 @@ import (
 	"context"
 	"pr-assignment/internal/adapter/out/repository"
 	"pr-assignment/internal/model"
+	"time"
 )
 
+// limits the size of the review load series
+const maxLoadRange = 3 * 366 * 24 * time.Hour
+
 type StatService struct {
 	prReviewsRepo *repository.PrReviewersRepository
-	userRepo      *repository.UserRepository
-	prRepo        *repository.PullRequestRepository
+	teamRepo      *repository.TeamRepository
+	historyRepo   *repository.AssignmentHistoryRepository
+	declineRepo   *repository.ReviewDeclineRepository
+	decisionRepo  *repository.ReviewDecisionRepository
+	// under reviewed threshold of team stats
+	requiredReviewers int
 }
 
-func NewStatService(prReviewsRepo *repository.PrReviewersRepository, userRepo *repository.UserRepository,
-	prRepo *repository.PullRequestRepository) *StatService {
-	return &StatService{prReviewsRepo: prReviewsRepo, userRepo: userRepo, prRepo: prRepo}
+func NewStatService(prReviewsRepo *repository.PrReviewersRepository, teamRepo *repository.TeamRepository,
+	historyRepo *repository.AssignmentHistoryRepository, declineRepo *repository.ReviewDeclineRepository,
+	decisionRepo *repository.ReviewDecisionRepository, requiredReviewers int) *StatService {
+	return &StatService{prReviewsRepo: prReviewsRepo, teamRepo: teamRepo, historyRepo: historyRepo,
+		declineRepo: declineRepo, decisionRepo: decisionRepo, requiredReviewers: requiredReviewers}
 }
 
-func (s *StatService) GetReviewsCountedByUser(ctx context.Context) ([]model.UserReviewsCount, error) {
-	userReviews := []model.UserReviewsCount{}
+// checkTeam makes a filter by unknown team fail instead of returning empty stats
+func (s *StatService) checkTeam(ctx context.Context, teamName string) error {
+	if teamName == "" {
+		return nil
+	}
 
-	userMap, err := s.prReviewsRepo.GetNumberOfReviewsByUser(ctx)
-	if err != nil {
-		return nil, err
+	_, err := s.teamRepo.GetTeamID(ctx, teamName)
+	return err
+}
+
+// StreamReviewsCountedByUser passes users with their number of reviews to fn. Like every stream
+// method here, it returns validation errors before fn is called for the first time
+func (s *StatService) StreamReviewsCountedByUser(ctx context.Context, teamName string,
+	fn func(model.UserReviewsCount) error) error {
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return err
 	}
 
-	for userID, count := range userMap {
-		user, err := s.userRepo.GetUserByID(ctx, userID)
-		if err != nil {
-			return nil, err
-		}
+	return s.prReviewsRepo.StreamNumberOfReviewsByUser(ctx, teamName, fn)
+}
 
-		userReviews = append(userReviews, model.UserReviewsCount{User: *user, ReviewsCount: count})
+// StreamReviewLoad passes assignments per user over time to fn one user at a time, reassigned
+// reviews are counted too
+func (s *StatService) StreamReviewLoad(ctx context.Context, from time.Time, to time.Time,
+	bucket model.StatBucket, teamName string, fn func(model.UserReviewLoad) error) error {
+	if !bucket.IsValid() {
+		return model.NewError(model.BadRequest, "bucket must be day, week or month")
+	}
+	if !from.Before(to) {
+		return model.NewError(model.BadRequest, "from must be before to")
 	}
+	if to.Sub(from) > maxLoadRange {
+		return model.NewError(model.BadRequest, "range must not be longer than 3 years")
+	}
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return err
+	}
+
+	return s.historyRepo.StreamReviewLoad(ctx, from, to, bucket, teamName, fn)
+}
 
-	return userReviews, nil
+func (s *StatService) StreamReviewsCountedByPR(ctx context.Context, teamName string,
+	fn func(model.PrReviewersCount) error) error {
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return err
+	}
+
+	return s.prReviewsRepo.StreamPrsWithReviewer(ctx, teamName, fn)
 }
 
-func (s *StatService) GetReviewsCountedByPR(ctx context.Context) ([]model.PrReviewersCount, error) {
-	prReviewers := []model.PrReviewersCount{}
+// GetTeamStats returns PR and member aggregates of every team or only of the given one, with
+// rollup the aggregates of a team cover its sub-teams too
+func (s *StatService) GetTeamStats(ctx context.Context, teamName string, rollup bool) ([]model.TeamStats, error) {
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	return s.teamRepo.GetTeamStats(ctx, teamName, s.requiredReviewers, rollup)
+}
 
-	prMap, err := s.prReviewsRepo.GetPrsWithReviewer(ctx)
+// GetPairingMatrix counts all assignments ever made for every author and reviewer pair
+func (s *StatService) GetPairingMatrix(ctx context.Context) (*model.PairingMatrix, error) {
+	pairings := make([]model.PairingCount, 0)
+	err := s.historyRepo.StreamPairingCounts(ctx, func(pairing model.PairingCount) error {
+		pairings = append(pairings, pairing)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for prID, count := range prMap {
-		pr, err := s.prRepo.GetPR(ctx, prID)
-		if err != nil {
-			return nil, err
+	authorIdx := make(map[string]int)
+	reviewerIdx := make(map[string]int)
+	matrix := model.PairingMatrix{Authors: []string{}, Reviewers: []string{}, Counts: [][]int{}}
+
+	for _, pairing := range pairings {
+		if _, ok := authorIdx[pairing.AuthorID]; !ok {
+			authorIdx[pairing.AuthorID] = len(matrix.Authors)
+			matrix.Authors = append(matrix.Authors, pairing.AuthorID)
 		}
+		if _, ok := reviewerIdx[pairing.ReviewerID]; !ok {
+			reviewerIdx[pairing.ReviewerID] = len(matrix.Reviewers)
+			matrix.Reviewers = append(matrix.Reviewers, pairing.ReviewerID)
+		}
+	}
+
+	for range matrix.Authors {
+		matrix.Counts = append(matrix.Counts, make([]int, len(matrix.Reviewers)))
+	}
+
+	for _, pairing := range pairings {
+		matrix.Counts[authorIdx[pairing.AuthorID]][reviewerIdx[pairing.ReviewerID]] = pairing.Count
+	}
 
-		prReviewers = append(prReviewers, model.PrReviewersCount{PullRequest: pr.PullRequestShort, Count: count})
+	return &matrix, nil
+}
+
+// StreamPairingCounts passes pairs that were assigned at least once to fn, ordered by author
+func (s *StatService) StreamPairingCounts(ctx context.Context, fn func(model.PairingCount) error) error {
+	return s.historyRepo.StreamPairingCounts(ctx, fn)
+}
+
+func (s *StatService) StreamDeclinesCountedByUser(ctx context.Context, fn func(model.UserDeclinesCount) error) error {
+	return s.declineRepo.StreamDeclinesCountByUser(ctx, fn)
+}
+
+func (s *StatService) GetFlowReport(ctx context.Context, from time.Time, to time.Time,
+	teamName string) (*model.FlowReport, error) {
+	if !from.Before(to) {
+		return nil, model.NewError(model.BadRequest, "from must be before to")
+	}
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return nil, err
 	}
 
-	return prReviewers, nil
+	return s.decisionRepo.GetFlowReport(ctx, from, to, teamName)
 }
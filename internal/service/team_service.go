@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pr-assignment/internal/adapter/out/repository"
+	"pr-assignment/internal/model"
+	"strings"
+)
+
+const (
+	defaultTeamPageSize = 50
+	maxTeamPageSize     = 500
+	defaultMovesLimit   = 100
+	maxMovesLimit       = 1000
+)
+
+// TeamService changes teams one piece at a time, /team/add and /team/import work with whole rosters
+type TeamService struct {
+	teamRepository *repository.TeamRepository
+	userRepository *repository.UserRepository
+	moveRepository *repository.TeamMoveRepository
+	userService    *UserService
+	prService      *PullRequestService
+	logger         *slog.Logger
+}
+
+func NewTeamService(teamRepo *repository.TeamRepository, userRepo *repository.UserRepository,
+	moveRepo *repository.TeamMoveRepository, userService *UserService, prService *PullRequestService,
+	logger *slog.Logger) *TeamService {
+	return &TeamService{teamRepository: teamRepo, userRepository: userRepo, moveRepository: moveRepo,
+		userService: userService, prService: prService, logger: logger}
+}
+
+// ListTeams pages through teams by name, limit 0 means the default page size
+func (s *TeamService) ListTeams(ctx context.Context, search string, limit int, offset int) (*model.TeamList, error) {
+	ctx, span := startSpan(ctx, "TeamService.ListTeams")
+	defer span.End()
+
+	if limit == 0 {
+		limit = defaultTeamPageSize
+	}
+	if limit < 0 || limit > maxTeamPageSize {
+		return nil, model.NewError(model.BadRequest, "limit must be between 1 and %d", maxTeamPageSize)
+	}
+	if offset < 0 {
+		return nil, model.NewError(model.BadRequest, "offset can not be negative")
+	}
+
+	teams, total, err := s.teamRepository.ListTeams(ctx, search, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TeamList{Teams: teams, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// SetParentTeam puts the team under the parent team, empty parent makes it a top level team
+func (s *TeamService) SetParentTeam(ctx context.Context, teamName string, parentTeam string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "TeamService.SetParentTeam", teamNameKey.String(teamName))
+	defer span.End()
+
+	if parentTeam == teamName {
+		return nil, model.NewError(model.BadRequest, "team %s can not be its own parent", teamName)
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := ""
+	if parentTeam != "" {
+		parentID, err = s.teamRepository.GetTeamID(ctx, parentTeam)
+		if err != nil {
+			return nil, err
+		}
+
+		parentPath, err := s.teamRepository.GetAncestorIDs(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if err = parentCycleError(teamName, teamID, parentTeam, parentPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// the update checks for cycles again, a concurrent change may have made one since
+	ok, err := s.teamRepository.SetParentTeam(ctx, teamID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, model.NewError(model.BadRequest, "%s is a sub-team of %s and can not be its parent",
+			parentTeam, teamName)
+	}
+
+	s.logger.InfoContext(ctx, "parent team set", "team_name", teamName, "parent_team", parentTeam)
+	return s.userService.GetTeam(ctx, teamName)
+}
+
+// parentCycleError refuses a parent when the team is on the parent's path to the top, parentPath
+// lists the parent and all teams above it
+func parentCycleError(teamName string, teamID string, parentTeam string, parentPath []string) error {
+	for _, ancestorID := range parentPath {
+		if ancestorID == teamID {
+			return model.NewError(model.BadRequest, "%s is a sub-team of %s and can not be its parent",
+				parentTeam, teamName)
+		}
+	}
+	return nil
+}
+
+func (s *TeamService) RenameTeam(ctx context.Context, teamName string, newTeamName string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "TeamService.RenameTeam", teamNameKey.String(teamName))
+	defer span.End()
+
+	if newTeamName == "" {
+		return nil, model.NewError(model.BadRequest, "new_team_name is required")
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if newTeamName == teamName {
+		return s.userService.GetTeam(ctx, teamName)
+	}
+
+	exists, err := s.teamRepository.Exists(ctx, newTeamName)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, model.NewError(model.TeamExists, "%s already exists", newTeamName)
+	}
+
+	// members, fallbacks, rules and SLA refer to the id, only the name changes
+	if err = s.teamRepository.RenameTeam(ctx, teamID, newTeamName); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "team renamed", "team_name", teamName, "new_team_name", newTeamName)
+	return s.userService.GetTeam(ctx, newTeamName)
+}
+
+// DeleteTeam removes the team. Members, active or not, move to moveMembersTo, which is required
+// unless the team is empty. Open PRs filed in the team are handled by policy
+func (s *TeamService) DeleteTeam(ctx context.Context, teamName string, moveMembersTo string,
+	policy model.OpenPRPolicy) (*model.TeamDeletion, error) {
+	ctx, span := startSpan(ctx, "TeamService.DeleteTeam", teamNameKey.String(teamName))
+	defer span.End()
+
+	if policy == "" {
+		policy = model.BlockOpenPRs
+	}
+	if !policy.IsValid() {
+		return nil, model.NewError(model.BadRequest, "open_prs must be block, keep or reassign")
+	}
+	if moveMembersTo == teamName {
+		return nil, model.NewError(model.BadRequest, "team %s can not move members to itself", teamName)
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.userRepository.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID := ""
+	if moveMembersTo != "" {
+		targetID, err = s.teamRepository.GetTeamID(ctx, moveMembersTo)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(team.Members) > 0 {
+		return nil, model.NewError(model.BadRequest, "team %s has %d members, set move_members_to", teamName,
+			len(team.Members))
+	}
+
+	openPRs, err := s.teamRepository.GetOpenPRIDs(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if len(openPRs) > 0 && policy == model.BlockOpenPRs {
+		return nil, model.NewError(model.HasOpenPRs, "team %s has %d open PRs, merge them or "+
+			"choose keep or reassign", teamName, len(openPRs))
+	}
+
+	moved, err := s.teamRepository.DeleteTeam(ctx, teamID, targetID, policy == model.ReassignOpenPRs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.TeamDeletion{TeamName: teamName, MovedTo: moveMembersTo, MovedMembers: moved,
+		OpenPRs: openPRs, Policy: policy}
+
+	if policy == model.ReassignOpenPRs {
+		for _, prID := range openPRs {
+			replaced, err := s.prService.ReassignPendingReviews(ctx, prID, model.TeamChangeReassign)
+			result.Reassigned += replaced
+			if err != nil {
+				// the team is gone already, the rest of the PRs keep their reviewers
+				s.logger.ErrorContext(ctx, "unable to reassign reviews after team delete", "team_name", teamName,
+					"pr_id", prID, "error", err)
+				break
+			}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "team deleted", "team_name", teamName, "moved_to", moveMembersTo,
+		"moved_members", len(moved), "open_prs", len(openPRs), "policy", policy, "reassigned", result.Reassigned)
+	return result, nil
+}
+
+// AddMember creates a new user in the team. A user of another team keeps the primary team
+// and gets this one as a secondary team
+func (s *TeamService) AddMember(ctx context.Context, teamName string, member model.TeamMember) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "TeamService.AddMember", teamNameKey.String(teamName),
+		userIDKey.String(member.UserID))
+	defer span.End()
+
+	if member.UserID == "" || member.Username == "" {
+		return nil, model.NewError(model.BadRequest, "user_id and username are required")
+	}
+	if member.Role == "" {
+		member.Role = model.MEMBER
+	}
+	if !member.Role.IsValid() {
+		return nil, model.NewError(model.BadRequest, "unknown role %s", member.Role)
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.userRepository.AddMember(ctx, teamID, member)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		// adding the same member again changes nothing, the primary team is skipped by the repository
+		if _, err = s.userRepository.AddMembership(ctx, member.UserID, teamID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.userService.GetTeam(ctx, teamName)
+}
+
+// RemoveMember takes a secondary team away from the user, unfinished reviews of the team's PRs
+// are reassigned. From the primary team it deletes a user nothing refers to yet, typically added
+// by mistake. Users with PRs or reviews are kept for the history, deactivated, and their open
+// reviews are reassigned. A user with secondary teams has to be moved instead
+func (s *TeamService) RemoveMember(ctx context.Context, teamName string,
+	userID string) (*model.MemberRemovalResult, error) {
+	ctx, span := startSpan(ctx, "TeamService.RemoveMember", teamNameKey.String(teamName), userIDKey.String(userID))
+	defer span.End()
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTeamID, err := s.userRepository.GetTeamNameByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if currentTeamID != teamID {
+		return s.removeMembership(ctx, teamName, teamID, userID)
+	}
+
+	teams, err := s.userRepository.GetUserTeams(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = primaryRemovalError(teamName, teams); err != nil {
+		return nil, err
+	}
+
+	result := &model.MemberRemovalResult{TeamName: teamName, UserID: userID, Result: model.MemberDeleted}
+
+	deleted, err := s.userRepository.DeleteUnusedUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deleted {
+		return result, nil
+	}
+
+	result.Result = model.MemberDeactivated
+	if _, err = s.userService.SetUserActive(ctx, userID, false); err != nil {
+		return nil, err
+	}
+	if err = s.prService.ReassignReviewsAfterDeath(ctx, userID); err != nil {
+		// the user is deactivated anyway, the failure only goes to the log
+		s.logger.ErrorContext(ctx, "unable to reassign reviews of removed member", "user_id", userID,
+			"error", err)
+	}
+	return result, nil
+}
+
+// primaryRemovalError refuses to take the primary team from a user who has secondary teams,
+// teams lists the primary team first as GetUserTeams returns it
+func primaryRemovalError(teamName string, teams *model.UserTeams) error {
+	if len(teams.Teams) <= 1 {
+		return nil
+	}
+	return model.NewError(model.BadRequest, "%s is the primary team of %s, use /team/moveMember "+
+		"to move the user to one of %s instead", teamName, teams.UserID, strings.Join(teams.Teams[1:], ", "))
+}
+
+func (s *TeamService) removeMembership(ctx context.Context, teamName string, teamID string,
+	userID string) (*model.MemberRemovalResult, error) {
+	removed, err := s.userRepository.RemoveMembership(ctx, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, model.NewError(model.NotFound, "user %s is not a member of %s", userID, teamName)
+	}
+
+	if _, err = s.prService.ReassignReviewsAfterMove(ctx, userID); err != nil {
+		// the membership is gone anyway, the failure only goes to the log
+		s.logger.ErrorContext(ctx, "unable to reassign reviews after membership removal", "user_id", userID,
+			"team_name", teamName, "error", err)
+	}
+	return &model.MemberRemovalResult{TeamName: teamName, UserID: userID, Result: model.MembershipRemoved}, nil
+}
+
+// GetUserTeams lists the primary and secondary teams of the user
+func (s *TeamService) GetUserTeams(ctx context.Context, userID string) (*model.UserTeams, error) {
+	ctx, span := startSpan(ctx, "TeamService.GetUserTeams", userIDKey.String(userID))
+	defer span.End()
+
+	return s.userRepository.GetUserTeams(ctx, userID)
+}
+
+// MoveUser changes the primary team of the user, the move is audited. The old primary team is
+// left, a secondary membership of the new team becomes the primary one. Policy decides about the user's
+// unfinished reviews: keep them, or hand reviews of PRs from outside the new team back to the
+// authors' teams. With reevaluateAuthored pending reviewers of the user's own open PRs who are
+// not in the new team are picked again from it
+func (s *TeamService) MoveUser(ctx context.Context, userID string, teamName string, policy model.MoveReviewPolicy,
+	reevaluateAuthored bool) (*model.TeamMove, error) {
+	ctx, span := startSpan(ctx, "TeamService.MoveUser", userIDKey.String(userID), teamNameKey.String(teamName))
+	defer span.End()
+
+	if policy == "" {
+		policy = model.KeepReviews
+	}
+	if !policy.IsValid() {
+		return nil, model.NewError(model.BadRequest, "reviews must be keep or reassign")
+	}
+
+	currentTeamID, err := s.userRepository.GetTeamNameByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if targetID == currentTeamID {
+		return nil, model.NewError(model.BadRequest, "user %s is already in %s", userID, teamName)
+	}
+
+	move, err := s.moveRepository.MoveUser(ctx, userID, currentTeamID, targetID, policy, reevaluateAuthored)
+	if err != nil {
+		return nil, err
+	}
+
+	// the move itself is done, failed reassignments only leave some reviewers as they were
+	if policy == model.ReassignReviews {
+		move.ReassignedReviews, err = s.prService.ReassignReviewsAfterMove(ctx, userID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "unable to reassign reviews of moved user", "user_id", userID, "error", err)
+		}
+	}
+	if reevaluateAuthored {
+		move.ReassignedAuthored, err = s.prService.ReassignAuthoredReviews(ctx, userID, model.TeamChangeReassign)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "unable to reassign reviewers of moved user's PRs", "user_id", userID,
+				"error", err)
+		}
+	}
+
+	if move.ReassignedReviews > 0 || move.ReassignedAuthored > 0 {
+		err = s.moveRepository.SetReassigned(ctx, move.MoveID, move.ReassignedReviews, move.ReassignedAuthored)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.InfoContext(ctx, "user moved", "user_id", userID, "from_team", move.FromTeam, "to_team", move.ToTeam,
+		"reviews", policy, "reassigned_reviews", move.ReassignedReviews,
+		"reassigned_authored", move.ReassignedAuthored)
+	return move, nil
+}
+
+// GetMoves returns the audit trail of team changes, latest first, limit 0 means the default
+func (s *TeamService) GetMoves(ctx context.Context, userID string, teamName string,
+	limit int) ([]model.TeamMove, error) {
+	ctx, span := startSpan(ctx, "TeamService.GetMoves", userIDKey.String(userID), teamNameKey.String(teamName))
+	defer span.End()
+
+	if limit == 0 {
+		limit = defaultMovesLimit
+	}
+	if limit < 0 || limit > maxMovesLimit {
+		return nil, model.NewError(model.BadRequest, "limit must be between 1 and %d", maxMovesLimit)
+	}
+
+	return s.moveRepository.GetMoves(ctx, userID, teamName, limit)
+}
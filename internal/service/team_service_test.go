@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pr-assignment/internal/model"
+	"testing"
+)
+
+func TestParentCycleError(t *testing.T) {
+	tests := []struct {
+		name       string
+		parentPath []string
+		wantErr    bool
+	}{
+		{name: "unrelated parent", parentPath: []string{"id-ops", "id-root"}},
+		{name: "top level parent", parentPath: []string{"id-ops"}},
+		{name: "direct sub-team", parentPath: []string{"id-sub", "id-backend", "id-root"}, wantErr: true},
+		{name: "deeper sub-team", parentPath: []string{"id-leaf", "id-sub", "id-backend"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parentCycleError("backend", "id-backend", "sub", tt.parentPath)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+		})
+	}
+}
+
+func TestSetParentTeamRefusesItself(t *testing.T) {
+	s := &TeamService{}
+	_, err := s.SetParentTeam(context.Background(), "backend", "backend")
+
+	var customErr *model.CustomError
+	if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+		t.Fatalf("err = %v, want a bad request", err)
+	}
+}
+
+func TestAddMemberValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  model.TeamMember
+		wantErr string
+	}{
+		{name: "no user_id", member: model.TeamMember{Username: "Alice"}, wantErr: "user_id and username are required"},
+		{name: "no username", member: model.TeamMember{UserID: "u1"}, wantErr: "user_id and username are required"},
+		{name: "unknown role", member: model.TeamMember{UserID: "u1", Username: "Alice", Role: "boss"},
+			wantErr: "unknown role boss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &TeamService{}
+			_, err := s.AddMember(context.Background(), "backend", tt.member)
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			if customErr.Message != tt.wantErr {
+				t.Errorf("err = %q, want %q", customErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrimaryRemovalError(t *testing.T) {
+	tests := []struct {
+		name    string
+		teams   []string
+		wantErr string
+	}{
+		{name: "only the primary team", teams: []string{"backend"}},
+		{
+			name:  "secondary teams left",
+			teams: []string{"backend", "frontend", "platform"},
+			wantErr: "backend is the primary team of u1, use /team/moveMember to move the user to one of " +
+				"frontend, platform instead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := primaryRemovalError("backend", &model.UserTeams{UserID: "u1", PrimaryTeam: "backend",
+				Teams: tt.teams})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			if customErr.Message != tt.wantErr {
+				t.Errorf("err = %q, want %q", customErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
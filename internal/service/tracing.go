@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spans go to the global provider, they are no-ops when tracing is off
+var tracer = otel.Tracer("pr-assignment/internal/service")
+
+const (
+	prIDKey            = attribute.Key("pr.id")
+	prAuthorIDKey      = attribute.Key("pr.author_id")
+	reviewerIDKey      = attribute.Key("reviewer.id")
+	reassignReasonKey  = attribute.Key("reassign.reason")
+	decisionKey        = attribute.Key("review.decision")
+	userIDKey          = attribute.Key("user.id")
+	userRoleKey        = attribute.Key("user.role")
+	userActiveKey      = attribute.Key("user.is_active")
+	teamIDKey          = attribute.Key("team.id")
+	teamNameKey        = attribute.Key("team.name")
+	teamMembersKey     = attribute.Key("team.members")
+	rosterEntriesKey   = attribute.Key("roster.entries")
+	reconcileKey       = attribute.Key("roster.reconcile")
+	dryRunKey          = attribute.Key("roster.dry_run")
+	directorySourceKey = attribute.Key("directory.source")
+	snapshotModeKey    = attribute.Key("snapshot.mode")
+	snapshotDryRunKey  = attribute.Key("snapshot.dry_run")
+)
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
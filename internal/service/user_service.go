@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"pr-assignment/internal/adapter/out/repository"
 	"pr-assignment/internal/model"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -12,13 +13,19 @@ import (
 type UserService struct {
 	userRepository *repository.UserRepository
 	teamRepository *repository.TeamRepository
+	slaRepository  *repository.SlaRepository
+	logger         *slog.Logger
 }
 
-func NewUserService(r *repository.UserRepository, t *repository.TeamRepository) *UserService {
-	return &UserService{r, t}
+func NewUserService(r *repository.UserRepository, t *repository.TeamRepository, sla *repository.SlaRepository,
+	logger *slog.Logger) *UserService {
+	return &UserService{r, t, sla, logger}
 }
 
 func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+	ctx, span := startSpan(ctx, "UserService.SetUserActive", userIDKey.String(userID), userActiveKey.Bool(isActive))
+	defer span.End()
+
 	user, err := s.userRepository.UpdateUserStatus(ctx, userID, isActive)
 	if err != nil {
 		return nil, err
@@ -26,15 +33,67 @@ func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive
 	return user, nil
 }
 
+func (s *UserService) SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	ctx, span := startSpan(ctx, "UserService.SetUserRole", userIDKey.String(userID), userRoleKey.String(string(role)))
+	defer span.End()
+
+	if !role.IsValid() {
+		return nil, model.NewError(model.BadRequest, "unknown role %s", role)
+	}
+
+	user, err := s.userRepository.UpdateUserRole(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (s *UserService) AddTeam(ctx context.Context, team model.Team) error {
-	res, _ := s.teamRepository.Exists(ctx, team.TeamName)
+	ctx, span := startSpan(ctx, "UserService.AddTeam", teamNameKey.String(team.TeamName),
+		teamMembersKey.Int(len(team.Members)))
+	defer span.End()
+
+	res, err := s.teamRepository.Exists(ctx, team.TeamName)
+	if err != nil {
+		return err
+	}
 
 	if res {
 		return model.NewError(model.TeamExists, "%s already exists", team.TeamName)
 	}
 
+	err = validateNewMembers(team.Members)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range team.RoleRules {
+		err := s.validateRoleRule(rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.checkNewMembers(ctx, team.Members)
+	if err != nil {
+		return err
+	}
+
+	fallbackIDs, err := s.resolveFallbackTeams(ctx, team.TeamName, team.FallbackTeams)
+	if err != nil {
+		return err
+	}
+
+	parentID := ""
+	if team.ParentTeam != "" {
+		parentID, err = s.teamRepository.GetTeamID(ctx, team.ParentTeam)
+		if err != nil {
+			return err
+		}
+	}
+
 	teamID := uuid.New()
-	err := s.teamRepository.AddTeam(ctx, team, teamID)
+	err = s.teamRepository.AddTeam(ctx, team, teamID)
 	if err != nil {
 		return err
 	}
@@ -44,28 +103,239 @@ func (s *UserService) AddTeam(ctx context.Context, team model.Team) error {
 		return err
 	}
 
+	if len(fallbackIDs) > 0 {
+		err = s.teamRepository.SetFallbackTeams(ctx, teamID.String(), fallbackIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	// a new team has no sub-teams, the parent can not make a cycle
+	if parentID != "" {
+		_, err = s.teamRepository.SetParentTeam(ctx, teamID.String(), parentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range team.RoleRules {
+		err = s.teamRepository.SetRoleRule(ctx, teamID.String(), rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *UserService) SetRoleRule(ctx context.Context, teamName string, rule model.RoleRule) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "UserService.SetRoleRule", teamNameKey.String(teamName))
+	defer span.End()
+
+	err := s.validateRoleRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.teamRepository.SetRoleRule(ctx, teamID, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTeam(ctx, teamName)
+}
+
+// validateNewMembers checks the payload of a new team before anything is written,
+// a user listed twice would otherwise fail halfway through the inserts
+func validateNewMembers(members []model.TeamMember) error {
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		if member.Role != "" && !member.Role.IsValid() {
+			return model.NewError(model.BadRequest, "unknown role %s of user %s", member.Role, member.UserID)
+		}
+		if seen[member.UserID] {
+			return model.NewError(model.BadRequest, "user %s is listed twice", member.UserID)
+		}
+		seen[member.UserID] = true
+	}
 	return nil
 }
 
+func (s *UserService) validateRoleRule(rule model.RoleRule) error {
+	if !rule.Role.IsValid() {
+		return model.NewError(model.BadRequest, "unknown role %s", rule.Role)
+	}
+	if rule.MinCount < 0 {
+		return model.NewError(model.BadRequest, "min count of role %s can not be negative", rule.Role)
+	}
+	return nil
+}
+
+func (s *UserService) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "UserService.SetFallbackTeams", teamNameKey.String(teamName))
+	defer span.End()
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackIDs, err := s.resolveFallbackTeams(ctx, teamName, fallbackTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.teamRepository.SetFallbackTeams(ctx, teamID, fallbackIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTeam(ctx, teamName)
+}
+
+// checkNewMembers refuses to create a team with users that already have a primary team
+func (s *UserService) checkNewMembers(ctx context.Context, members []model.TeamMember) error {
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+
+	primaryTeams, err := s.userRepository.GetPrimaryTeams(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	return existingMembersError(members, primaryTeams)
+}
+
+// existingMembersError lists members found in primaryTeams in the order of the request
+func existingMembersError(members []model.TeamMember, primaryTeams map[string]string) error {
+	taken := make([]string, 0)
+	for _, member := range members {
+		if teamName, ok := primaryTeams[member.UserID]; ok {
+			taken = append(taken, member.UserID+" is already in "+teamName)
+		}
+	}
+
+	if len(taken) == 0 {
+		return nil
+	}
+	return model.NewError(model.BadRequest, "%s: use /team/moveMember to move users between teams "+
+		"or /team/addMember to add a secondary team", strings.Join(taken, ", "))
+}
+
+func (s *UserService) resolveFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) ([]string, error) {
+	fallbackIDs := make([]string, 0, len(fallbackTeams))
+	seen := make(map[string]bool)
+	for _, fallbackName := range fallbackTeams {
+		if fallbackName == teamName {
+			return nil, model.NewError(model.BadRequest, "team %s can not be a fallback of itself", teamName)
+		}
+		if seen[fallbackName] {
+			return nil, model.NewError(model.BadRequest, "fallback team %s is listed twice", fallbackName)
+		}
+		seen[fallbackName] = true
+
+		fallbackID, err := s.teamRepository.GetTeamID(ctx, fallbackName)
+		if err != nil {
+			return nil, err
+		}
+		fallbackIDs = append(fallbackIDs, fallbackID)
+	}
+
+	return fallbackIDs, nil
+}
+
 func (s *UserService) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "UserService.GetTeam", teamNameKey.String(teamName))
+	defer span.End()
+
 	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
 
 	if err != nil {
-		fmt.Println("error getting team id teamRepo")
 		return nil, err
 	}
 
 	team, err := s.userRepository.GetTeam(ctx, teamID)
 	if err != nil {
-		fmt.Println("error getting team userRepo")
-		fmt.Println(err)
+		s.logger.WarnContext(ctx, "unable to get team members", "team_name", teamName, "error", err)
 		return nil, model.NewError(model.NotFound, "%s not found", teamName)
 	}
 	team.TeamName = teamName
+
+	team.ParentTeam, err = s.teamRepository.GetParentTeamName(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	team.SecondaryMembers, err = s.userRepository.GetSecondaryMembers(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	team.FallbackTeams, err = s.teamRepository.GetFallbackTeamNames(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	team.RoleRules, err = s.teamRepository.GetRoleRules(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	team.Sla, err = s.slaRepository.GetTeamSla(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
 	return team, nil
 }
 
+// GetTeamTree returns the team with all its sub-teams nested in sub_teams
+func (s *UserService) GetTeamTree(ctx context.Context, teamName string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "UserService.GetTeamTree", teamNameKey.String(teamName))
+	defer span.End()
+
+	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamRepository.GetTeamSubtree(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return nestTeams(teams), nil
+}
+
+// nestTeams builds the tree from a subtree listed root first with every team after its parent.
+// Sub-teams keep their order
+func nestTeams(teams []model.Team) *model.Team {
+	children := make(map[string][]model.Team)
+	for _, team := range teams[1:] {
+		children[team.ParentTeam] = append(children[team.ParentTeam], team)
+	}
+
+	var nest func(team model.Team) model.Team
+	nest = func(team model.Team) model.Team {
+		for _, child := range children[team.TeamName] {
+			team.SubTeams = append(team.SubTeams, nest(child))
+		}
+		return team
+	}
+
+	root := nest(teams[0])
+	return &root
+}
+
 func (s *UserService) GetActiveTeammatesByUser(ctx context.Context, userID string) ([]string, error) {
+	ctx, span := startSpan(ctx, "UserService.GetActiveTeammatesByUser", userIDKey.String(userID))
+	defer span.End()
+
 	teamID, err := s.userRepository.GetTeamNameByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -80,6 +350,9 @@ func (s *UserService) GetActiveTeammatesByUser(ctx context.Context, userID strin
 }
 
 func (s *UserService) KillTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	ctx, span := startSpan(ctx, "UserService.KillTeam", teamNameKey.String(teamName))
+	defer span.End()
+
 	teamID, err := s.teamRepository.GetTeamID(ctx, teamName)
 	if err != nil {
 		return nil, err
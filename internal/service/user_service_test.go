@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"pr-assignment/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestExistingMembersError(t *testing.T) {
+	members := []model.TeamMember{{UserID: "u3"}, {UserID: "u1"}, {UserID: "u2"}}
+
+	tests := []struct {
+		name         string
+		primaryTeams map[string]string
+		wantErr      string
+	}{
+		{name: "all new", primaryTeams: map[string]string{}},
+		{
+			name:         "members of other teams in request order",
+			primaryTeams: map[string]string{"u1": "backend", "u3": "frontend"},
+			wantErr: "u3 is already in frontend, u1 is already in backend: use /team/moveMember to move users " +
+				"between teams or /team/addMember to add a secondary team",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := existingMembersError(members, tt.primaryTeams)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			if customErr.Message != tt.wantErr {
+				t.Errorf("err = %q, want %q", customErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNewMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []model.TeamMember
+		wantErr string
+	}{
+		{name: "distinct members", members: []model.TeamMember{{UserID: "u1", Role: model.LEAD}, {UserID: "u2"}}},
+		{name: "empty team"},
+		{
+			name:    "user listed twice",
+			members: []model.TeamMember{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u1", Role: model.SENIOR}},
+			wantErr: "user u1 is listed twice",
+		},
+		{
+			name:    "unknown role",
+			members: []model.TeamMember{{UserID: "u1", Role: "boss"}},
+			wantErr: "unknown role boss of user u1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNewMembers(tt.members)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var customErr *model.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != model.BadRequest {
+				t.Fatalf("err = %v, want a bad request", err)
+			}
+			if customErr.Message != tt.wantErr {
+				t.Errorf("err = %q, want %q", customErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNestTeams(t *testing.T) {
+	teams := []model.Team{
+		{TeamName: "backend", ParentTeam: "eng"},
+		{TeamName: "api", ParentTeam: "backend"},
+		{TeamName: "db", ParentTeam: "backend"},
+		{TeamName: "auth", ParentTeam: "api"},
+	}
+
+	want := &model.Team{TeamName: "backend", ParentTeam: "eng", SubTeams: []model.Team{
+		{TeamName: "api", ParentTeam: "backend", SubTeams: []model.Team{{TeamName: "auth", ParentTeam: "api"}}},
+		{TeamName: "db", ParentTeam: "backend"},
+	}}
+
+	if got := nestTeams(teams); !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %+v, want %+v", got, want)
+	}
+	if got := nestTeams(teams[:1]); !reflect.DeepEqual(got, &model.Team{TeamName: "backend", ParentTeam: "eng"}) {
+		t.Errorf("leaf tree = %+v", got)
+	}
+}